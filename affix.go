@@ -0,0 +1,106 @@
+package versioner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxAffix bounds Prefix and FeatureSuffix so the core of the version keeps room under tag-length limits.
+const maxAffix = 32
+
+var (
+	prefixRE    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	suffixRE    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	affixBadRE  = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	leadDigitRE = regexp.MustCompile(`^[0-9._-]+`)
+)
+
+// checkAffixes validates Prefix and FeatureSuffix, or with Config.SanitizeAffixes rewrites them into the allowed
+// form: prefixes start with a letter and carry no dots (they would split the date), suffixes avoid '+', '!' and
+// anything registries reject, and both stay within maxAffix characters.
+func checkAffixes(cfg Config) (Config, error) {
+	prefix := strings.TrimSuffix(cfg.Prefix, "-")
+	suffix := strings.TrimPrefix(cfg.FeatureSuffix, "-")
+	if cfg.SanitizeAffixes {
+		prefix = sanitizeAffix(leadDigitRE.ReplaceAllString(strings.ReplaceAll(prefix, ".", "-"), ""))
+		suffix = sanitizeAffix(suffix)
+	}
+
+	switch {
+	case prefix == "":
+	case len(prefix) > maxAffix:
+		return cfg, &AffixError{"Prefix", cfg.Prefix, "is longer than 32 characters"}
+	case !prefixRE.MatchString(prefix):
+		return cfg, &AffixError{"Prefix", cfg.Prefix, "must start with a letter and contain only letters, digits, '_' and '-'"}
+	}
+	switch {
+	case suffix == "":
+	case len(suffix) > maxAffix:
+		return cfg, &AffixError{"FeatureSuffix", cfg.FeatureSuffix, "is longer than 32 characters"}
+	case !suffixRE.MatchString(suffix):
+		return cfg, &AffixError{"FeatureSuffix", cfg.FeatureSuffix, "may contain only letters, digits, '.', '_' and '-'"}
+	}
+	labels := make([]string, 0, len(cfg.SuffixLabels))
+	for _, l := range cfg.SuffixLabels {
+		if cfg.SanitizeAffixes {
+			l = sanitizeAffix(l)
+		}
+		if l != "" && (len(l) > maxAffix || !suffixRE.MatchString(l)) {
+			return cfg, &AffixError{"SuffixLabels", l, "may contain only letters, digits, '.', '_' and '-'"}
+		}
+		labels = append(labels, l)
+	}
+	if cfg.SanitizeAffixes {
+		cfg.Prefix, cfg.FeatureSuffix, cfg.SuffixLabels = prefix, suffix, labels
+	}
+	return cfg, nil
+}
+
+// StripLabels removes the trailing suffix labels of v that belong to known (compared case-insensitively) and
+// returns the remaining version with the labels found, in the order they appeared. Snapshot versions of one build
+// across a matrix of variants therefore map back to a common version.
+func StripLabels(v Version, known []string) (Version, []string) {
+	isKnown := map[string]bool{}
+	for _, k := range known {
+		isKnown[strings.ToLower(k)] = true
+	}
+	var found []string
+	for v.Suffix != "" {
+		i := strings.LastIndex(v.Suffix, "-")
+		last := v.Suffix[i+1:]
+		if !isKnown[strings.ToLower(last)] {
+			break
+		}
+		found = append([]string{last}, found...)
+		if i < 0 {
+			v.Suffix = ""
+		} else {
+			v.Suffix = v.Suffix[:i]
+		}
+	}
+	return v, found
+}
+
+// canonicalLabels sorts labels case-insensitively and drops duplicates and empties, so matrix jobs listing the same
+// labels in any order produce the same version.
+func canonicalLabels(labels []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, l := range labels {
+		if k := strings.ToLower(l); l != "" && !seen[k] {
+			seen[k] = true
+			out = append(out, l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i]) < strings.ToLower(out[j]) })
+	return out
+}
+
+func sanitizeAffix(s string) string {
+	s = strings.Trim(affixBadRE.ReplaceAllString(s, "-"), "-._")
+	if len(s) > maxAffix {
+		s = strings.TrimRight(s[:maxAffix], "-._")
+	}
+	return s
+}
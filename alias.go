@@ -0,0 +1,115 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Alias is a moving tag following the newest final version, for consumers that pull "whatever is current".
+// Config.Aliases names the ones kept up to date when a version is tagged.
+type Alias string
+
+const (
+	AliasLatest Alias = "latest" // the newest final version: default-branch builds and release patches
+	AliasStable Alias = "stable" // the newest release patch ('<base>.<n>'); in SemVer mode the same as latest
+)
+
+// AliasTag is the tag of alias for the version prefix in namespace: "stable", "api-stable" or "cli/api-stable".
+func AliasTag(namespace, prefix string, a Alias) string {
+	return TagName(namespace, addPrefix(string(a), prefix))
+}
+
+// UpdateAliases force-moves each of Config.Aliases to the commit of version and force-pushes it, when version is the
+// newest final version of Config.Prefix the alias follows. Re-runs of older pipelines and versions that are not
+// newer leave the aliases alone, so they never move backwards; retracted versions are not counted.
+func (c BuildContext) UpdateAliases(version string) error {
+	if len(c.Config.Aliases) == 0 {
+		return nil
+	}
+	ts, err := c.tags()
+	if err != nil {
+		return err
+	}
+	retracted := Retracted(ts)
+	prefix := strings.TrimSuffix(c.Config.Prefix, "-")
+	for _, a := range c.Config.Aliases {
+		if !c.follows(a, version, prefix) || c.superseded(a, version, prefix, ts, retracted) {
+			c.debug("alias not moved", "alias", a, "version", version)
+			continue
+		}
+		name := AliasTag(c.Config.Namespace, prefix, a)
+		err := c.effect("move tag "+name+" to "+version, func() error {
+			_, err := c.git("tag", "-f", "--end-of-options", name, "refs/tags/"+c.tagName(version)+"^{commit}")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("alias %s: %w", name, err)
+		}
+		err = c.effect("force-push tag "+name+" to origin", func() error {
+			_, err := c.git("push", "-f", "origin", "refs/tags/"+name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("alias %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func checkAlias(a Alias) error {
+	if a != AliasLatest && a != AliasStable {
+		return fmt.Errorf("alias %q is not %s or %s", a, AliasLatest, AliasStable)
+	}
+	return nil
+}
+
+// follows reports whether alias a may point at the final version tag of prefix.
+func (c BuildContext) follows(a Alias, tag, prefix string) bool {
+	if c.Config.SemVer {
+		p, _, err := ParseSemVer(tag)
+		return err == nil && p == prefix
+	}
+	v, err := Parse(tag)
+	return err == nil && IsFinal(tag) && v.Prefix == prefix && (a == AliasLatest || v.Patch > 0)
+}
+
+// superseded reports whether a tag a follows is newer than version.
+func (c BuildContext) superseded(a Alias, version, prefix string, ts []string, retracted map[string]bool) bool {
+	for _, t := range ts {
+		if retracted[t] || !c.follows(a, t, prefix) {
+			continue
+		}
+		if c.Config.SemVer {
+			_, v, _ := ParseSemVer(version)
+			if _, tv, _ := ParseSemVer(t); v.Less(tv) {
+				return true
+			}
+		} else {
+			v, _ := Parse(version)
+			if tv, _ := Parse(t); Compare(v, tv) < 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// released finishes tagging m: the ledger records it, the aliases move to it and, for a nightly, the nightly
+// pruning policy runs.
+func (c BuildContext) released(m Manifest) error {
+	if err := c.record(m); err != nil {
+		return err
+	}
+	if err := c.UpdateAliases(m.Version); err != nil {
+		return err
+	}
+	if policy := c.nightlyPolicy(); policy != nil && c.isNightly() {
+		_, err := c.PruneNightlies(policy)
+		return err
+	}
+	return nil
+}
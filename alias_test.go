@@ -0,0 +1,58 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUpdateAliases(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, "", "tag", "20250427.90")
+	mustGit(t, "", "push", "-q", "origin", "20250427.90")
+	at := func(tag string) string {
+		out := mustGit(t, "", "ls-remote", origin, "refs/tags/"+tag)
+		sha, _, _ := strings.Cut(out, "\t")
+		return sha
+	}
+	cfg := Config{DefaultBranch: "main", Aliases: []Alias{AliasLatest, AliasStable}}
+
+	c := ctx("main", cfg, nil)
+	c.LookupTags = GitTags
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "build")
+	head := mustGit(t, "", "rev-parse", "HEAD")
+	if _, err := c.TagAndPush(); err != nil {
+		t.Fatal(err)
+	}
+	if at("latest") != head || at("stable") != "" {
+		t.Fatalf("default build: latest %q stable %q", at("latest"), at("stable"))
+	}
+
+	// A release patch of an older base is stable but not the latest.
+	mustGit(t, "", "checkout", "-q", "-b", "release/v20250427.90", "20250427.90")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "fix")
+	fix := mustGit(t, "", "rev-parse", "HEAD")
+	c = ctx("release/v20250427.90", cfg, nil)
+	c.LookupTags = GitTags
+	if m, err := c.TagAndPush(); err != nil || m.Version != "20250427.90.1" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	if at("latest") != head || at("stable") != fix {
+		t.Fatalf("release patch: latest %q stable %q", at("latest"), at("stable"))
+	}
+
+	c.Config.Prefix, c.Config.Aliases = "api", []Alias{AliasStable}
+	if err := c.UpdateAliases("20250427.90.1"); err != nil || tagged("api-stable") {
+		t.Fatalf("other prefix: %v", err)
+	}
+}
+
+func TestAliasConfig(t *testing.T) {
+	err := Config{DefaultBranch: "main", Aliases: []Alias{"newest"}}.Validate()
+	if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), `alias "newest"`) {
+		t.Fatalf("got %v", err)
+	}
+	if got := AliasTag("cli", "api-", AliasStable); got != "cli/api-stable" {
+		t.Fatalf("got %s want cli/api-stable", got)
+	}
+}
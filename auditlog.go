@@ -0,0 +1,175 @@
+package versioner
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// AuditRecord is one decision of the versioner: the inputs it saw and the version (or error) it produced. Records
+// of a FileAudit are hash-chained: Hash covers the record including Prev, the Hash of the record before it, so
+// editing or dropping an entry breaks every later hash.
+type AuditRecord struct {
+	Time       time.Time `json:"time"`
+	Version    string    `json:"version,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Kind       string    `json:"kind"`
+	Forced     bool      `json:"forced,omitempty"` // Config.ForceVersion decided
+	Branch     string    `json:"branch"`
+	PipelineID string    `json:"pipeline_id,omitempty"`
+	Commit     string    `json:"commit,omitempty"`
+	MergeReqID string    `json:"merge_request_iid,omitempty"`
+	Source     string    `json:"pipeline_source,omitempty"`
+	Config     Config    `json:"config"`
+
+	Prev string `json:"prev,omitempty"`
+	Hash string `json:"hash"`
+}
+
+// AuditSink receives every version computed by a BuildContext with Audit set.
+type AuditSink interface {
+	Append(r AuditRecord) error
+}
+
+// FileAudit is an append-only, hash-chained JSON-lines audit log.
+type FileAudit struct {
+	Path string
+}
+
+func (a FileAudit) Append(r AuditRecord) error {
+	rs, err := a.Records()
+	if err != nil {
+		return err
+	}
+	if len(rs) > 0 {
+		r.Prev = rs[len(rs)-1].Hash
+	}
+	if r.Hash, err = r.digest(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Records reads the log in order; a missing file is an empty log.
+func (a FileAudit) Records() ([]AuditRecord, error) {
+	f, err := os.Open(a.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rs []AuditRecord
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		var r AuditRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", a.Path, n, err)
+		}
+		rs = append(rs, r)
+	}
+	return rs, sc.Err()
+}
+
+// Verify recomputes the hash chain and reports the first record that was altered, inserted or follows a removed one
+// as ErrAuditTampered.
+func (a FileAudit) Verify() error {
+	rs, err := a.Records()
+	if err != nil {
+		return err
+	}
+	prev := ""
+	for i, r := range rs {
+		want, err := r.digest()
+		if err != nil {
+			return err
+		}
+		if r.Prev != prev || r.Hash != want {
+			return fmt.Errorf("%w: %s:%d (%s)", ErrAuditTampered, a.Path, i+1, r.Version)
+		}
+		prev = r.Hash
+	}
+	return nil
+}
+
+// HTTPAudit posts each record to an audit service through Webhook, signed with its Secret, so the trail lives
+// outside the pipeline's reach. Hash covers the record itself; chaining is up to the receiver.
+type HTTPAudit struct {
+	Webhook Webhook
+}
+
+func (a HTTPAudit) Append(r AuditRecord) error {
+	var err error
+	if r.Hash, err = r.digest(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return a.Webhook.deliver(context.Background(), b)
+}
+
+// ErrAuditTampered is returned by FileAudit.Verify when the hash chain is broken.
+var ErrAuditTampered = errors.New("audit log tampered")
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// digest is the hex SHA-256 of the record's JSON with Hash cleared.
+func (r AuditRecord) digest() (string, error) {
+	r.Hash = ""
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// audit appends the outcome of Version to c.Audit. Failing to record fails the build: a trail with holes is no
+// trail.
+func (c BuildContext) audit(v string, verr error) error {
+	r := AuditRecord{
+		Time:       c.now().UTC(),
+		Version:    v,
+		Kind:       Classify(c.Config, c.Branch).String(),
+		Forced:     c.Config.ForceVersion != "",
+		Branch:     c.Branch,
+		PipelineID: c.PipelineID,
+		Commit:     c.CommitSHA,
+		MergeReqID: c.MergeReqID,
+		Source:     c.PipelineSource,
+		Config:     c.Config,
+	}
+	if verr != nil {
+		r.Error = verr.Error()
+	}
+	err := c.effect("append "+v+" to the audit log", func() error { return c.Audit.Append(r) })
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package versioner
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileAudit(t *testing.T) {
+	a := FileAudit{Path: filepath.Join(t.TempDir(), "audit.jsonl")}
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.Audit, c.CommitSHA = a, "0123456789abcdef"
+	if _, err := c.Version(); err != nil {
+		t.Fatal(err)
+	}
+	c.Config.ForceVersion = "20250101.1"
+	c.Version()
+	c.Config.ForceVersion = "bogus"
+	if _, err := c.Version(); err == nil {
+		t.Fatal("bogus forced version accepted")
+	}
+
+	rs, err := a.Records()
+	if err != nil || len(rs) != 3 {
+		t.Fatalf("got %d records, %v", len(rs), err)
+	}
+	if r := rs[0]; r.Version != "20250428.321" || r.Kind != "default" || r.Commit != "0123456789abcdef" || r.Prev != "" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	if !rs[1].Forced || rs[1].Prev != rs[0].Hash || rs[2].Error == "" {
+		t.Fatalf("unexpected chain %+v", rs[1:])
+	}
+	if err := a.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := os.ReadFile(a.Path)
+	os.WriteFile(a.Path, []byte(strings.Replace(string(b), "20250428.321", "20250428.999", 1)), 0o644)
+	if err := a.Verify(); !errors.Is(err, ErrAuditTampered) {
+		t.Fatalf("edited record: got %v", err)
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	os.WriteFile(a.Path, []byte(lines[0]+lines[2]), 0o644)
+	if err := a.Verify(); !errors.Is(err, ErrAuditTampered) {
+		t.Fatalf("dropped record: got %v", err)
+	}
+}
+
+func TestHTTPAudit(t *testing.T) {
+	var got AuditRecord
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	c := ctx("feat/x", Config{DefaultBranch: "main"}, nil)
+	c.Audit = HTTPAudit{Webhook{URL: srv.URL}}
+	if _, err := c.Version(); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "20250428.321" || got.Kind != "feature" || len(got.Hash) != 64 {
+		t.Fatalf("got %+v", got)
+	}
+
+	c.Audit = HTTPAudit{Webhook{URL: srv.URL + "/x", Client: &http.Client{Transport: failing{}}}}
+	if _, err := c.Version(); err == nil {
+		t.Fatal("unrecorded version must fail")
+	}
+}
+
+type failing struct{}
+
+func (failing) RoundTrip(*http.Request) (*http.Response, error) { return nil, errors.New("down") }
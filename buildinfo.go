@@ -0,0 +1,115 @@
+package versioner
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BuildInfo is every fact derived while computing a version, so callers need not re-parse the string.
+type BuildInfo struct {
+	Version    string   `json:"version"`
+	Kind       string   `json:"kind"`               // default, release, feature or nightly
+	BaseTag    string   `json:"base_tag,omitempty"` // YYYYMMDD.<build> the version descends from; empty on feature builds
+	Date       string   `json:"date"`               // YYYYMMDD
+	Build      int      `json:"build"`
+	Patch      int      `json:"patch,omitempty"`
+	Prefix     string   `json:"prefix,omitempty"`
+	Suffix     string   `json:"suffix,omitempty"`
+	Commit     string   `json:"commit,omitempty"` // full SHA from BuildContext.CommitSHA
+	Branch     string   `json:"branch"`
+	PipelineID string   `json:"pipeline_id,omitempty"`
+	Tickets    []string `json:"tickets,omitempty"` // issue IDs from the branch name; see Config.TicketPattern
+
+	SkippedTags []SkippedTag `json:"skipped_tags,omitempty"` // looked-up tag names ignored as malformed
+	Warnings    []Warning    `json:"warnings,omitempty"`     // issues that did not stop the computation; see Warnings
+}
+
+// BuildInfo computes the version like Version and returns it with its derived components.
+func (c BuildContext) BuildInfo() (BuildInfo, error) {
+	c = c.pinTime().memoizeTags()
+	v, err := c.Version()
+	if err != nil {
+		return BuildInfo{}, err
+	}
+	if c.isNightly() {
+		return c.nightlyInfo(v)
+	}
+	raw := v
+	if len(c.Config.Policies)+len(c.Policies) > 0 && c.Config.ForceVersion == "" {
+		if raw, err = c.unpoliced().Version(); err != nil {
+			return BuildInfo{}, err
+		}
+	}
+	if c.Config.SemVer && c.Config.ForceVersion == "" {
+		return c.semverInfo(v, raw)
+	}
+	pv, err := Parse(raw)
+	if err != nil {
+		return BuildInfo{}, err
+	}
+	kind := Classify(c.Config, c.Branch)
+	bi := BuildInfo{
+		Version:    v,
+		Kind:       kind.String(),
+		Date:       pv.Date,
+		Build:      pv.Build,
+		Patch:      pv.Patch,
+		Prefix:     pv.Prefix,
+		Suffix:     pv.Suffix,
+		Commit:     c.CommitSHA,
+		Branch:     c.Branch,
+		PipelineID: c.PipelineID,
+	}
+	if kind.Final() {
+		bi.BaseTag = pv.Base()
+	}
+	return c.annotate(bi)
+}
+
+// nightlyInfo is the BuildInfo of nightly version v.
+func (c BuildContext) nightlyInfo(v string) (BuildInfo, error) {
+	n, err := ParseNightly(v)
+	if err != nil {
+		return BuildInfo{}, err
+	}
+	bi := BuildInfo{Version: v, Kind: "nightly", Date: n.Date, Build: n.N, Prefix: n.Prefix, Commit: c.CommitSHA,
+		Branch: c.Branch, PipelineID: c.PipelineID}
+	return c.annotate(bi)
+}
+
+// semverInfo is the BuildInfo of SemVer-mode version v, computed as raw before policies: the patch from the core and,
+// on feature builds, the build number and suffix from the '<build>[-<suffix>]' pre-release.
+func (c BuildContext) semverInfo(v, raw string) (BuildInfo, error) {
+	prefix, s, pre, err := parseSemVerBuild(raw)
+	if err != nil {
+		return BuildInfo{}, err
+	}
+	day, err := c.day()
+	if err != nil {
+		return BuildInfo{}, err
+	}
+	bi := BuildInfo{Version: v, Kind: Classify(c.Config, c.Branch).String(), Date: day, Patch: s.Patch, Prefix: prefix,
+		Commit: c.CommitSHA, Branch: c.Branch, PipelineID: c.PipelineID}
+	build, suffix, _ := strings.Cut(pre, "-")
+	if n, err := strconv.Atoi(build); err == nil {
+		bi.Build, bi.Suffix = n, suffix
+	} else {
+		bi.Suffix = pre
+	}
+	return c.annotate(bi)
+}
+
+// annotate adds what BuildInfo reports beside the version components: tickets, skipped tags and warnings.
+func (c BuildContext) annotate(bi BuildInfo) (BuildInfo, error) {
+	var err error
+	if bi.Tickets, err = c.tickets(); err != nil {
+		return BuildInfo{}, err
+	}
+	if bi.SkippedTags, err = c.SkippedTags(); err != nil && !c.Config.BestEffortTags {
+		return BuildInfo{}, err
+	}
+	if bi.Warnings, err = c.Warnings(); err != nil {
+		return BuildInfo{}, err
+	}
+	return bi, nil
+}
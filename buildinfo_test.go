@@ -0,0 +1,25 @@
+package versioner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, []string{"20250428.100.1"})
+	c.CommitSHA = "0123456789abcdef"
+	bi, err := c.BuildInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BuildInfo{Version: "20250428.100.2", Kind: "release", BaseTag: "20250428.100", Date: "20250428",
+		Build: 100, Patch: 2, Commit: "0123456789abcdef", Branch: "release/v20250428.100", PipelineID: "321"}
+	if !reflect.DeepEqual(bi, want) {
+		t.Fatalf("got %+v want %+v", bi, want)
+	}
+
+	c = ctx("feat/x", Config{DefaultBranch: "main", FeatureSuffix: "SNAPSHOT"}, nil)
+	if bi, _ = c.BuildInfo(); bi.Kind != "feature" || bi.BaseTag != "" || bi.Suffix != "SNAPSHOT" || bi.Build != 321 {
+		t.Fatalf("unexpected feature info %+v", bi)
+	}
+}
@@ -0,0 +1,46 @@
+package versioner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// ManualKind selects what an out-of-band release from a workstation allocates.
+type ManualKind string
+
+const (
+	ManualPatch ManualKind = "patch" // the next patch on a release branch
+	ManualBuild ManualKind = "build" // a new default-branch build
+)
+
+// Manual prepares c for an out-of-band release when CI is down. A patch needs c.Branch to be a release branch and is
+// computed as a pipeline would; a build needs the default branch and, with no pipeline to number it, takes the build
+// after the highest one of the prefix's stream so it still sorts last. Version, TagAndPush … on the returned context
+// then behave as in CI.
+func (c BuildContext) Manual(kind ManualKind) (BuildContext, error) {
+	switch want := map[ManualKind]Kind{ManualPatch: KindRelease, ManualBuild: KindDefault}[kind]; {
+	case kind != ManualPatch && kind != ManualBuild:
+		return BuildContext{}, fmt.Errorf("%w: unknown bump kind %q (want patch or build)", ErrInvalidConfig, kind)
+	case Classify(c.Config, c.Branch) != want:
+		return BuildContext{}, fmt.Errorf("%w: a %s bump needs a %s branch, not %q", ErrInvalidConfig, kind, want, c.Branch)
+	}
+	if kind == ManualPatch {
+		return c, nil
+	}
+	ts, err := c.tags()
+	if err != nil {
+		return BuildContext{}, err
+	}
+	last := 0
+	for _, t := range ts {
+		v, err := Parse(t)
+		if err == nil && v.Prefix == strings.TrimSuffix(c.Config.Prefix, "-") && v.Build > last {
+			last = v.Build
+		}
+	}
+	c.PipelineID = strconv.Itoa(last + 1)
+	return c, nil
+}
@@ -0,0 +1,41 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestManual(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", Prefix: "api"}
+	tags := []string{"api-20250420.412", "api-20250425.418", "api-20250425.418.2", "web-20250427.900"}
+
+	c := ctx("main", cfg, tags)
+	c.PipelineID = ""
+	mc, err := c.Manual(ManualBuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := mc.Version(); got != "api-20250428.419" {
+		t.Fatalf("got %s want api-20250428.419", got)
+	}
+
+	mc, err = ctx("release/v20250425.418", Config{DefaultBranch: "main"}, []string{"20250425.418", "20250425.418.2"}).Manual(ManualPatch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := mc.Version(); got != "20250425.418.3" {
+		t.Fatalf("got %s want 20250425.418.3", got)
+	}
+}
+
+func TestManualErrors(t *testing.T) {
+	cfg := Config{DefaultBranch: "main"}
+	for _, tc := range []struct {
+		branch string
+		kind   ManualKind
+	}{{"main", ManualPatch}, {"release/v20250425.418", ManualBuild}, {"feat/x", ManualBuild}, {"main", "major"}} {
+		if _, err := ctx(tc.branch, cfg, nil).Manual(tc.kind); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%s on %s: got %v want ErrInvalidConfig", tc.kind, tc.branch, err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package versioner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Change is one commit of a changelog, parsed as a conventional commit where possible.
+type Change struct {
+	SHA      string `json:"sha"`
+	Type     string `json:"type,omitempty"` // feat, fix …; empty for subjects that are not conventional commits
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Breaking bool   `json:"breaking,omitempty"` // "type!:" or a "BREAKING CHANGE:" footer
+}
+
+// Changes is a changelog, newest commit first.
+type Changes []Change
+
+// Changelog lists the commits between two tagged versions: reachable from to but not from. A zero from starts at
+// the root commit.
+func Changelog(from, to Version) (Changes, error) {
+	var f string
+	if from != (Version{}) {
+		f = from.String()
+	}
+	return BuildContext{}.changesBetween(f, to.String())
+}
+
+// Markdown renders the changes under a "## title" heading, breaking changes first, then grouped by commit type.
+func (cs Changes) Markdown(title string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", title)
+	if len(cs) == 0 {
+		b.WriteString("\n_No changes._\n")
+		return b.String()
+	}
+
+	section := func(heading string, keep func(Change) bool) {
+		first := true
+		for _, ch := range cs {
+			if !keep(ch) {
+				continue
+			}
+			if first {
+				fmt.Fprintf(&b, "\n### %s\n\n", heading)
+				first = false
+			}
+			if ch.Scope != "" {
+				fmt.Fprintf(&b, "- **%s:** %s (%s)\n", ch.Scope, ch.Subject, ch.SHA)
+			} else {
+				fmt.Fprintf(&b, "- %s (%s)\n", ch.Subject, ch.SHA)
+			}
+		}
+	}
+	section("Breaking Changes", func(ch Change) bool { return ch.Breaking })
+	for _, g := range changeGroups {
+		section(g.heading, func(ch Change) bool { return !ch.Breaking && groupOf(ch.Type) == g.heading })
+	}
+	return b.String()
+}
+
+// ReleaseNotes renders a Markdown section for version listing the commits since the nearest previous tag in
+// Config.Namespace. The tag of version itself is skipped, so the notes come out the same before and after tagging.
+func (c BuildContext) ReleaseNotes(version string) (string, error) {
+	describe := []string{"describe", "--tags", "--abbrev=0", "--exclude", c.tagName(version), "--exclude", "*/*"}
+	if c.Config.Namespace != "" {
+		describe = []string{"describe", "--tags", "--abbrev=0", "--exclude", c.tagName(version),
+			"--match", c.Config.Namespace + "/*"}
+	}
+	var prev string
+	if out, err := c.git(append(describe, "HEAD")...); err == nil {
+		prev = strings.TrimSpace(out)
+	}
+	cs, err := c.changesBetween(prev, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	date, err := c.localTime()
+	if err != nil {
+		return "", err
+	}
+	return cs.Markdown(fmt.Sprintf("%s (%s)", version, date.Format("2006-01-02"))), nil
+}
+
+// UpdateChangelog prepends the release notes of m, a version TagAndPush already tagged and pushed, to
+// Config.Changelog and commits the result on top of the tagged commit on default and release builds. Running after
+// the tag keeps the tag on the commit the pipeline built, never on the bookkeeping commit. The commit is pushed
+// straight to the branch, or to changelog/<version> with a merge request opened via GitLab push options when
+// Config.ChangelogMR is set. Feature builds are left untouched.
+func (c BuildContext) UpdateChangelog(m Manifest) error {
+	c = c.pinTime()
+	if c.Config.Changelog == "" || Classify(c.Config, c.Branch) == KindFeature {
+		return nil
+	}
+	v := m.Version
+
+	notes := m.Notes
+	if notes == "" {
+		var err error
+		if notes, err = c.ReleaseNotes(v); err != nil {
+			return err
+		}
+	}
+	err := c.effect(fmt.Sprintf("prepend to %s:\n%s", c.Config.Changelog, notes), func() error {
+		return PrependChangelog(c.Config.Changelog, notes)
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("chore: update changelog for %s", v)
+	push := []string{"push", "origin", "HEAD:refs/heads/" + c.Branch}
+	if c.Config.ChangelogMR {
+		push = []string{"push",
+			"-o", "merge_request.create",
+			"-o", "merge_request.target=" + c.Branch,
+			"-o", "merge_request.remove_source_branch",
+			"origin", "HEAD:refs/heads/changelog/" + v}
+	} else {
+		msg += " [skip ci]" // the version is tagged and pushed already; don't re-run the pipeline for the bookkeeping commit
+	}
+
+	for _, args := range [][]string{{"add", "--", filepath.ToSlash(c.Config.Changelog)}, {"commit", "-m", msg}, push} {
+		err := c.effect("run git "+strings.Join(args, " "), func() error {
+			_, err := c.git(args...)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrependChangelog inserts section above the newest entry in the changelog at path, keeping any leading
+// "# Changelog" title in place. A missing file is created.
+func PrependChangelog(path, section string) error {
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, []byte(prependSection(string(old), section)), 0o644)
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var changeGroups = []struct {
+	heading string
+	types   []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+	{"Reverts", []string{"revert"}},
+	{"Other", nil}, // docs, chore, refactor … and non-conventional subjects
+}
+
+func groupOf(typ string) string {
+	for _, g := range changeGroups {
+		for _, t := range g.types {
+			if t == typ {
+				return g.heading
+			}
+		}
+	}
+	return "Other"
+}
+
+var conventionalRE = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+// changesBetween runs git log over from..to (all of to when from is empty).
+func (c BuildContext) changesBetween(from, to string) (Changes, error) {
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+	out, err := c.git("log", "--no-merges", "--format=%h%x1f%s%x1f%b%x1e", "--end-of-options", rng)
+	if err != nil {
+		return nil, err
+	}
+	return parseChanges(out), nil
+}
+
+func parseChanges(out string) Changes {
+	var cs Changes
+	for _, rec := range strings.Split(out, "\x1e") {
+		f := strings.SplitN(strings.TrimLeft(rec, "\n"), "\x1f", 3)
+		if len(f) < 2 {
+			continue
+		}
+		ch := Change{SHA: f[0], Subject: f[1]}
+		if m := conventionalRE.FindStringSubmatch(f[1]); m != nil {
+			ch.Type, ch.Scope, ch.Breaking, ch.Subject = strings.ToLower(m[1]), m[2], m[3] == "!", m[4]
+		}
+		if len(f) == 3 && strings.Contains(f[2], "BREAKING CHANGE:") {
+			ch.Breaking = true
+		}
+		cs = append(cs, ch)
+	}
+	return cs
+}
+
+func prependSection(doc, section string) string {
+	section = strings.TrimRight(section, "\n") + "\n"
+	if !strings.HasPrefix(doc, "# ") {
+		if doc == "" {
+			return "# Changelog\n\n" + section
+		}
+		return section + "\n" + doc
+	}
+	title, rest, _ := strings.Cut(doc, "\n")
+	return title + "\n\n" + section + "\n" + strings.TrimLeft(rest, "\n")
+}
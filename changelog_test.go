@@ -0,0 +1,112 @@
+package versioner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangesMarkdown(t *testing.T) {
+	log := "a1\x1ffix(tag): retry push\x1f\x1e\n" +
+		"b2\x1ffeat: add flag\x1f\x1e\n" +
+		"c3\x1frefactor!: drop v1 config\x1f\x1e\n" +
+		"d4\x1fUpdate README\x1f\x1e\n" +
+		"e5\x1ffeat(api): new endpoint\x1fBREAKING CHANGE: removes /v0\n\x1e\n"
+	got := parseChanges(log).Markdown("20250428.100.2 (2025-04-28)")
+	want := "## 20250428.100.2 (2025-04-28)\n" +
+		"\n### Breaking Changes\n\n- drop v1 config (c3)\n- **api:** new endpoint (e5)\n" +
+		"\n### Features\n\n- add flag (b2)\n" +
+		"\n### Bug Fixes\n\n- **tag:** retry push (a1)\n" +
+		"\n### Other\n\n- Update README (d4)\n"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestChangelogBetweenTags(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "20250427.300")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "fix: one")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "feat: two")
+	mustGit(t, "", "tag", "20250428.321")
+
+	from, _ := Parse("20250427.300")
+	to, _ := Parse("20250428.321")
+	cs, err := Changelog(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 || cs[0].Type != "feat" || cs[1].Subject != "one" {
+		t.Fatalf("unexpected changes %+v", cs)
+	}
+}
+
+func TestReleaseNotesInNamespace(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "cli/20250427.300")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "feat: two")
+	mustGit(t, "", "tag", "api/20250428.5")
+	for _, tc := range []struct {
+		ns   string
+		want bool
+	}{{"cli", true}, {"api", false}} {
+		c := ctx("main", Config{DefaultBranch: "main", Namespace: tc.ns}, nil)
+		notes, err := c.ReleaseNotes("20250428.321")
+		if err != nil || strings.Contains(notes, "two") != tc.want {
+			t.Fatalf("%s: got %q, %v", tc.ns, notes, err)
+		}
+	}
+}
+
+func TestPrependChangelogKeepsTitle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	if err := os.WriteFile(path, []byte("# Changelog\n\n## 20250427.1\n\n- old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := PrependChangelog(path, "## 20250428.2\n\n- new\n"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(path)
+	want := "# Changelog\n\n## 20250428.2\n\n- new\n\n## 20250427.1\n\n- old\n"
+	if string(got) != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestPrependChangelogCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	if err := PrependChangelog(path, "## 20250428.2\n\n- new\n"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(path)
+	want := "# Changelog\n\n## 20250428.2\n\n- new\n"
+	if string(got) != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestUpdateChangelogAfterTag(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, "", "tag", "20250427.300")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "feat: two")
+	built := mustGit(t, "", "rev-parse", "HEAD")
+
+	c := ctx("main", Config{DefaultBranch: "main", Changelog: "CHANGELOG.md"}, nil)
+	m, err := c.TagAndPush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UpdateChangelog(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustGit(t, "", "rev-parse", "refs/tags/"+m.Version+"^{commit}"); got != built {
+		t.Fatalf("tag on %s want the built commit %s", got, built)
+	}
+	if got := mustGit(t, "", "--git-dir", origin, "rev-parse", "main^"); got != built {
+		t.Fatalf("changelog commit's parent %s want the tagged commit %s", got, built)
+	}
+	if b, _ := os.ReadFile("CHANGELOG.md"); !strings.Contains(string(b), "two") {
+		t.Fatalf("notes miss the change since the previous tag:\n%s", b)
+	}
+}
@@ -0,0 +1,82 @@
+package versioner
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Channel is the audience of a build, rendered as a pre-release suffix on default-branch and feature builds.
+type Channel string
+
+const (
+	ChannelStable  Channel = "stable"  // no suffix
+	ChannelBeta    Channel = "beta"    // '-beta.<build>'
+	ChannelNightly Channel = "nightly" // '-nightly'; the date already identifies the build
+	ChannelDev     Channel = "dev"     // '-dev.<build>'
+)
+
+// ScheduleKey in Config.Channels selects the channel of scheduled pipelines (CI_PIPELINE_SOURCE=schedule) on any
+// branch, ahead of the branch patterns.
+const ScheduleKey = "@schedule"
+
+// ChannelOf picks the channel for branch from cfg.Channels: ScheduleKey for scheduled pipelines, then an exact branch
+// name, then the longest matching path.Match glob ("release/*", "feature/*"). Without a match it is "", meaning no
+// channel suffix.
+func ChannelOf(cfg Config, branch, source string) (Channel, error) {
+	if ch, ok := cfg.Channels[ScheduleKey]; ok && source == "schedule" {
+		return ch, nil
+	}
+	if ch, ok := cfg.Channels[branch]; ok {
+		return ch, nil
+	}
+	patterns := make([]string, 0, len(cfg.Channels))
+	for p := range cfg.Channels {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, p := range patterns {
+		if p == ScheduleKey {
+			continue
+		}
+		ok, err := path.Match(p, branch)
+		if err != nil {
+			return "", fmt.Errorf("%w: channel pattern %q: %v", ErrInvalidConfig, p, err)
+		}
+		if ok {
+			return cfg.Channels[p], nil
+		}
+	}
+	return "", nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// channelSuffix is the '-<channel>[.<build>]' part of the version, or "" for stable and unmatched builds.
+func (c BuildContext) channelSuffix(build string) (string, error) {
+	ch, err := ChannelOf(c.Config, c.Branch, c.PipelineSource)
+	if err != nil {
+		return "", err
+	}
+	name := strings.Trim(string(ch), "-")
+	if name != "" && !suffixRE.MatchString(name) {
+		return "", &AffixError{"Channels", string(ch), "may contain only letters, digits, '.', '_' and '-'"}
+	}
+	c.debug("channel selected", "branch", c.Branch, "source", c.PipelineSource, "channel", ch)
+	switch Channel(name) {
+	case "", ChannelStable:
+		return "", nil
+	case ChannelNightly:
+		return "-" + name, nil
+	default:
+		return "-" + name + "." + build, nil
+	}
+}
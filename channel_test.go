@@ -0,0 +1,42 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChannels(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", Channels: map[string]Channel{
+		"main":      ChannelStable,
+		"develop":   ChannelBeta,
+		"feature/*": ChannelDev,
+		"feature/x": ChannelBeta,
+		ScheduleKey: ChannelNightly,
+	}}
+	for _, tc := range []struct{ branch, source, want string }{
+		{"main", "push", "20250428.321"},
+		{"main", "schedule", "20250428.321-nightly"},
+		{"develop", "push", "20250428.321-beta.321"},
+		{"feature/y", "push", "20250428.321-dev.321"},
+		{"feature/x", "push", "20250428.321-beta.321"},
+		{"hotfix/z", "push", "20250428.321"},
+	} {
+		c := ctx(tc.branch, cfg, nil)
+		c.PipelineSource = tc.source
+		if got, err := c.Version(); err != nil || got != tc.want {
+			t.Fatalf("%s/%s: got %s want %s (%v)", tc.branch, tc.source, got, tc.want, err)
+		}
+	}
+}
+
+func TestChannelErrors(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", Channels: map[string]Channel{"[": ChannelBeta}}
+	if _, err := ctx("develop", cfg, nil).Version(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("bad pattern: got %v", err)
+	}
+	cfg.Channels = map[string]Channel{"develop": "be+ta"}
+	var ae *AffixError
+	if _, err := ctx("develop", cfg, nil).Version(); !errors.As(err, &ae) || ae.Field != "Channels" {
+		t.Fatalf("bad channel: got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package versioner
+
+import (
+	"fmt"
+	"os"
+)
+
+// Sources of the pipeline number for BuildContext.PipelineID. The project-scoped IID is the most readable, but
+// forked-project pipelines and multi-project triggers can reuse it; the instance-wide pipeline and job IDs cannot.
+const (
+	SourceIID      = "iid"      // CI_PIPELINE_IID (default)
+	SourcePipeline = "pipeline" // CI_PIPELINE_ID
+	SourceJob      = "job"      // CI_JOB_ID
+)
+
+// PipelineNumber reads the number for source ("" meaning SourceIID) from the GitLab CI environment. It is empty
+// outside CI, where BuildContext falls back to LookupBuild.
+func PipelineNumber(source string) (string, error) {
+	switch source {
+	case "", SourceIID:
+		return os.Getenv("CI_PIPELINE_IID"), nil
+	case SourcePipeline:
+		return os.Getenv("CI_PIPELINE_ID"), nil
+	case SourceJob:
+		return os.Getenv("CI_JOB_ID"), nil
+	default:
+		return "", fmt.Errorf("%w: unknown pipeline number source %q (want iid, pipeline or job)", ErrInvalidConfig, source)
+	}
+}
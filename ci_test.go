@@ -0,0 +1,20 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPipelineNumber(t *testing.T) {
+	t.Setenv("CI_PIPELINE_IID", "12")
+	t.Setenv("CI_PIPELINE_ID", "904512")
+	t.Setenv("CI_JOB_ID", "7781234")
+	for src, want := range map[string]string{"": "12", SourceIID: "12", SourcePipeline: "904512", SourceJob: "7781234"} {
+		if got, err := PipelineNumber(src); err != nil || got != want {
+			t.Fatalf("%q: got %s, %v want %s", src, got, err, want)
+		}
+	}
+	if _, err := PipelineNumber("run"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want ErrInvalidConfig", err)
+	}
+}
@@ -0,0 +1,38 @@
+package versioner
+
+import "time"
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Clock tells BuildContext the time. Each operation (Version, TagAndPush, Train …) reads it once and uses that
+// instant throughout, so a version's date, its manifest time and its audit record never straddle midnight.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function such as time.Now to Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
+
+// SystemClock is the real time, the default of BuildContext.Clock.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// pinTime starts an operation: a zero Time is read from Clock once, so c and its copies agree on the time.
+func (c BuildContext) pinTime() BuildContext {
+	if c.Time.IsZero() {
+		clock := c.Clock
+		if clock == nil {
+			clock = SystemClock
+		}
+		c.Time = clock.Now()
+	}
+	return c
+}
+
+// now is the pinned Time, or the clock's time outside an operation.
+func (c BuildContext) now() time.Time {
+	return c.pinTime().Time
+}
@@ -0,0 +1,35 @@
+package versioner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClockReadOncePerOperation(t *testing.T) {
+	reads := 0
+	clock := ClockFunc(func() time.Time {
+		reads++
+		return time.Date(2025, 4, 28, 23, 59, 59, 0, time.UTC).Add(time.Duration(reads-1) * time.Second)
+	})
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.Time, c.Clock = time.Time{}, clock
+
+	m, err := c.Manifest()
+	if err != nil || m.Version != "20250428.321" {
+		t.Fatalf("got %s, %v want 20250428.321", m.Version, err)
+	}
+	if reads != 1 || m.Time.Day() != 28 {
+		t.Fatalf("clock read %d times, manifest time %v", reads, m.Time)
+	}
+
+	// the next operation reads the clock again, after midnight
+	if v, _ := c.Version(); v != "20250429.321" || reads != 2 {
+		t.Fatalf("got %s after %d reads want 20250429.321", v, reads)
+	}
+
+	// a set Time pins the clock
+	c.Time = now
+	if v, _ := c.Version(); v != "20250428.321" || reads != 2 {
+		t.Fatalf("got %s after %d reads want 20250428.321", v, reads)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// completion and man read the command table, so they are registered here rather than in its initializer.
+func init() {
+	commands["completion"] = command{run: runCompletion, summary: "shell completion script for the commands and their flags",
+		subs: []string{"bash", "zsh", "fish"}}
+	commands["man"] = command{run: runMan, summary: "write man pages: versioner(1) and versioner-<command>(1)"}
+}
+
+func runCompletion(args []string) error {
+	gen := map[string]func(io.Writer){"bash": bashCompletion, "zsh": zshCompletion, "fish": fishCompletion}
+	if len(args) == 0 || gen[args[0]] == nil {
+		return usageError("versioner completion bash|zsh|fish")
+	}
+	fs := newFlagSet("completion " + args[0])
+	fs.Parse(args[1:])
+	gen[args[0]](os.Stdout)
+	return nil
+}
+
+func runMan(args []string) error {
+	fs := newFlagSet("man")
+	dir := fs.String("o", ".", "directory to write the pages to")
+	fs.Parse(args)
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	manIndex(&b)
+	if err := os.WriteFile(filepath.Join(*dir, "versioner.1"), []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	for _, name := range commandNames() {
+		b.Reset()
+		manPage(&b, name)
+		if err := os.WriteFile(filepath.Join(*dir, "versioner-"+name+".1"), []byte(b.String()), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ---------------- Command introspection --------------------------------------------------------------------------
+
+// describing makes newFlagSet build flag sets that panic on -h instead of exiting; described is the last one built.
+var (
+	describing bool
+	described  *flag.FlagSet
+)
+
+// newFlagSet is the flag set of every subcommand.
+func newFlagSet(name string) *flag.FlagSet {
+	if !describing {
+		return flag.NewFlagSet(name, flag.ExitOnError)
+	}
+	described = flag.NewFlagSet(name, flag.PanicOnError)
+	described.SetOutput(io.Discard)
+	return described
+}
+
+// describe returns the flags of a command by running it with -h (after its first subcommand, if any) and catching the
+// help panic raised by its flag set's Parse, before the command does anything.
+func describe(name string) (flags []*flag.Flag) {
+	describing, described = true, nil
+	defer func() {
+		describing = false
+		if r := recover(); r != nil && r != flag.ErrHelp {
+			panic(r)
+		}
+		if described != nil {
+			described.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+		}
+	}()
+	args := []string{"-h"}
+	if subs := commands[name].subs; len(subs) > 0 {
+		args = []string{subs[0], "-h"}
+	}
+	commands[name].run(args)
+	return nil
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// takesValue reports whether f needs an argument, i.e. is not a boolean flag.
+func takesValue(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !b.IsBoolFlag()
+}
+
+// ---------------- Completion scripts -----------------------------------------------------------------------------
+
+func bashCompletion(w io.Writer) {
+	names := commandNames()
+	fmt.Fprintf(w, "# bash completion for versioner; source it or install it as /etc/bash_completion.d/versioner\n")
+	fmt.Fprintf(w, "_versioner() {\n")
+	fmt.Fprintf(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]} words\n")
+	fmt.Fprintf(w, "\tif [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintf(w, "\t\treturn\n\tfi\n")
+	fmt.Fprintf(w, "\tcase ${COMP_WORDS[1]} in\n")
+	for _, name := range names {
+		words := append([]string(nil), commands[name].subs...)
+		for _, f := range describe(name) {
+			words = append(words, "-"+f.Name)
+		}
+		fmt.Fprintf(w, "\t%s) words=%q ;;\n", name, strings.Join(words, " "))
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "\tif [[ $cur == -* ]] || [[ $COMP_CWORD -eq 2 && -n $words && ${words%%%% *} != -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "\telse\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\tfi\n")
+	fmt.Fprintf(w, "}\ncomplete -F _versioner versioner\n")
+}
+
+func zshCompletion(w io.Writer) {
+	names := commandNames()
+	fmt.Fprintf(w, "#compdef versioner\n\n_versioner() {\n\tlocal -a commands\n\tcommands=(\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "\t\t%s\n", shQuote(name+":"+commands[name].summary))
+	}
+	fmt.Fprintf(w, "\t)\n\tif (( CURRENT == 2 )); then\n\t\t_describe command commands\n\t\treturn\n\tfi\n")
+	fmt.Fprintf(w, "\tcase $words[2] in\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%s)\n\t\t_arguments", name)
+		if subs := commands[name].subs; len(subs) > 0 {
+			fmt.Fprintf(w, " \\\n\t\t\t%s", shQuote("1:subcommand:("+strings.Join(subs, " ")+")"))
+		}
+		for _, f := range describe(name) {
+			_, usage := flag.UnquoteUsage(f)
+			spec := "-" + f.Name + "[" + zshEscape(usage) + "]"
+			if takesValue(f) {
+				spec += ":" + f.Name + ":"
+			}
+			fmt.Fprintf(w, " \\\n\t\t\t%s", shQuote(spec))
+		}
+		fmt.Fprintf(w, " \\\n\t\t\t'*:file:_files'\n\t\t;;\n")
+	}
+	fmt.Fprintf(w, "\tesac\n}\n\n_versioner \"$@\"\n")
+}
+
+func fishCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for versioner; install it as ~/.config/fish/completions/versioner.fish\n")
+	fmt.Fprintf(w, "complete -c versioner -f\n")
+	for _, name := range commandNames() {
+		fmt.Fprintf(w, "complete -c versioner -n __fish_use_subcommand -a %s -d %s\n", name, shQuote(commands[name].summary))
+	}
+	for _, name := range commandNames() {
+		cond := shQuote("__fish_seen_subcommand_from " + name)
+		if subs := commands[name].subs; len(subs) > 0 {
+			fmt.Fprintf(w, "complete -c versioner -n %s -a %s\n", cond, shQuote(strings.Join(subs, " ")))
+		}
+		for _, f := range describe(name) {
+			_, usage := flag.UnquoteUsage(f)
+			req := ""
+			if takesValue(f) {
+				req = " -r"
+			}
+			fmt.Fprintf(w, "complete -c versioner -n %s -o %s%s -d %s\n", cond, f.Name, req, shQuote(usage))
+		}
+	}
+}
+
+// shQuote single-quotes s for POSIX shells and fish; both read an embedded quote closed, escaped and reopened.
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshEscape protects the characters _arguments gives meaning to inside an option description.
+func zshEscape(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, `\`, `\\`).Replace(s)
+}
+
+// ---------------- Man pages --------------------------------------------------------------------------------------
+
+func manIndex(w io.Writer) {
+	fmt.Fprintf(w, ".TH VERSIONER 1\n.SH NAME\nversioner \\- CalVer versions for CI pipelines\n")
+	fmt.Fprintf(w, ".SH SYNOPSIS\n\\fBversioner\\fR [\\fIcommand\\fR] [\\fIflags\\fR]\n")
+	fmt.Fprintf(w, ".SH DESCRIPTION\nWithout a command, \\fBversioner\\fR runs \\fBversion\\fR.\n.SH COMMANDS\n")
+	for _, name := range commandNames() {
+		fmt.Fprintf(w, ".TP\n\\fB%s\\fR\n%s\n", roff(name), roff(commands[name].summary))
+	}
+	fmt.Fprintf(w, ".SH SEE ALSO\n")
+	for i, name := range commandNames() {
+		sep := ",\n"
+		if i == len(commands)-1 {
+			sep = "\n"
+		}
+		fmt.Fprintf(w, ".BR versioner\\-%s (1)%s", roff(name), sep)
+	}
+}
+
+func manPage(w io.Writer, name string) {
+	cmd := commands[name]
+	fmt.Fprintf(w, ".TH VERSIONER\\-%s 1\n", strings.ToUpper(roff(name)))
+	fmt.Fprintf(w, ".SH NAME\nversioner\\-%s \\- %s\n", roff(name), roff(cmd.summary))
+	sub := ""
+	if len(cmd.subs) > 0 {
+		sub = " " + strings.Join(cmd.subs, "|")
+	}
+	fmt.Fprintf(w, ".SH SYNOPSIS\n\\fBversioner %s\\fR%s [\\fIflags\\fR]\n", roff(name), roff(sub))
+	if flags := describe(name); len(flags) > 0 {
+		fmt.Fprintf(w, ".SH OPTIONS\n")
+		for _, f := range flags {
+			arg, usage := flag.UnquoteUsage(f)
+			if arg != "" {
+				arg = " \\fI" + roff(arg) + "\\fR"
+			}
+			if f.DefValue != "" && f.DefValue != "false" {
+				usage += " (default " + f.DefValue + ")"
+			}
+			fmt.Fprintf(w, ".TP\n\\fB\\-%s\\fR%s\n%s\n", roff(f.Name), arg, roff(usage))
+		}
+	}
+	fmt.Fprintf(w, ".SH SEE ALSO\n.BR versioner (1)\n")
+}
+
+// roff escapes backslashes and dashes, and leading control characters.
+func roff(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
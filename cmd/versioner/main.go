@@ -0,0 +1,1459 @@
+// Command versioner prints CalVer versions for GitLab pipelines.
+//
+//	versioner [version] [flags]   version for the current pipeline, read from the CI_* environment; non-fatal issues
+//	                              (malformed tags, a shallow clone …) are printed to stderr and listed by -json
+//	versioner components c…       versions of several monorepo components (version prefixes) over one tag lookup,
+//	                              -parallel at a time
+//	versioner tag [flags]         compute, tag HEAD and push the tag, retrying on concurrent release builds;
+//	                              -publish gitlab|github also creates the hosted release with the changelog,
+//	                              -milestone links (and -close-milestone closes) the GitLab milestone
+//	versioner dev [flags]         collision-free local version for developer builds
+//	versioner bump -kind k [-tag] next patch|build computed from a workstation when CI is down; -tag tags and
+//	                              pushes it after confirmation (-yes skips the prompts)
+//	versioner local [-prefix p]   <nearest tag>-local.<branch>.<distance>[-dirty]+<sha> from the local checkout
+//	versioner dead-letters        list (or -redeliver) webhook events that could not be delivered
+//	versioner import [flags]      backfill the ledger from GitLab Releases and tags
+//	versioner fleet -env n=url…   report environments lagging behind the latest release
+//	versioner locks list|clear    inspect or release (stale) release-branch locks
+//	versioner validate [-final] v check that v (a tag, an image label …) conforms to the scheme
+//	versioner audit [flags]       check the tag history: versions parse, patches are contiguous, dates never go back
+//	versioner check [-json]       tag hygiene: malformed names, duplicate versions, patch gaps, dates going back and
+//	                              final versions off the release lines; exit 1 on any violation
+//	versioner stale [flags]       prune candidates: final tags older than -max-age-days or superseded by -superseded
+//	                              newer patches, with the reasons; deletes nothing
+//	versioner reserve [flags]     claim the next version as a pending ref on origin before building
+//	versioner confirm <version>   tag and push a reserved version after a successful build
+//	versioner abandon <version>   release a reservation after a failed build
+//	versioner record [flags]      claim the version in the VERSIONER_STATE file instead of tagging (commit or keep it)
+//	versioner cut [flags]         create and push release/v<tag> from the latest default-branch build
+//	versioner train [-cut]        on a -schedule (cron) release train: print cut|due|reuse <branch>, -cut cuts it
+//	versioner promote snap sha    release an existing snapshot build under its final version
+//	versioner history [flags]     list released versions newest-first with their commits and dates
+//	versioner latest [-component] newest release of the component (version prefix), -json with commit and time
+//	versioner list [flags]        releases newest-first, -since YYYYMMDD, -json as one array for scripts
+//	versioner diff <from> <to>    upgrade|rollback|rebuild|same and the changed components, for deploy gates
+//	versioner sign [flags] <v>    cosign the tag (-tag-bundle file) and/or an image digest (-image repo@sha256:…),
+//	                              keyless with SIGSTORE_ID_TOKEN
+//	versioner retract -reason r v mark a bad release so it is never again treated as the latest
+//	versioner migrate [flags]     mirror legacy vX.Y.Z tags into the configured scheme (-rewrite also deletes them),
+//	                              printing the old → new mapping; -dry-run changes nothing
+//	versioner init gitlab [flags] write .gitlab/versioner.yml: a version job exporting $VERSION as a dotenv report
+//	                              and a tag job for default and release branches, to include from .gitlab-ci.yml
+//	versioner init github [flags] write .github/actions/versioner/action.yml, a composite action with a version output
+//	versioner where <version>     print the commit a version was built from
+//	versioner write [flags] file… stamp the version into VERSION, package.json, pyproject.toml, Chart.yaml or
+//	                              path:json:<key.path> / path:regex:<pattern> targets, all or nothing
+//	versioner provenance file…    SLSA v1 provenance statement for the built files, stamped with the version
+//	versioner serve [flags]       central version service: POST /v1/version backed by a shared ledger, GET /metrics
+//	versioner plan [-json]        preview the next default, release and feature versions
+//	versioner classify [branch]   print default|release|feature for the branch (exit 1 with -final on features)
+//	versioner completion <shell>  bash, zsh or fish completion script for the commands and their flags
+//	versioner man [-o dir]        man pages: versioner(1) and versioner-<command>(1)
+//
+// Environment:
+//
+//	VERSIONER_CONFIG=file      JSON config profiles (see Config.Overlay); its "default" profile applies, then the one
+//	                           named by VERSIONER_PROFILE=name. Precedence, lowest first: built-in defaults and
+//	                           CI_DEFAULT_BRANCH, the default profile, the named profile, VERSIONER_PREFIX and the
+//	                           other variables of versioner.EnvConfig (booleans as 1/0/true/false), flags
+//	VERSIONER_DEBUG=1          log classification, tag and patch decisions and span timings to stderr; spans carry
+//	                           the trace ID from TRACEPARENT
+//	VERSIONER_TAG_CACHE=file   share tag lookups between the invocations of one pipeline
+//	VERSIONER_SCOPED_TAGS=1    list only the tags the computation needs (git for-each-ref), for huge repositories
+//	VERSIONER_REMOTE=url       list tags with git ls-remote instead of a clone ("ci": this project, authenticated
+//	                           with CI_JOB_TOKEN); SSH URLs use the job's SSH setup
+//	VERSIONER_NO_FETCH_TAGS=1  don't fetch tags from origin into shallow or tagless clones before the first lookup
+//	VERSIONER_BUILD_SOURCE=s   pipeline number from iid (default), pipeline or job ID; the global IDs stay unique
+//	                           across forks and multi-project triggers
+//	VERSIONER_BUILD_NUMBER=n   build number when the pipeline number is missing (default: commit count of HEAD)
+//	VERSIONER_STATE=file|url   read versions from a JSON state file instead of git tags (see record); gs://bucket/key
+//	                           and s3://bucket/key share one ledger across repositories with conditional writes
+//	VERSIONER_AUDIT=file|url   record every computed version with its inputs: a hash-chained JSON-lines file or an
+//	                           HTTP endpoint (signed with VERSIONER_AUDIT_SECRET); `audit -log file` verifies a file
+//	VERSIONER_PUSHGATEWAY=url  push each run's metrics to this Prometheus Pushgateway
+//
+// Exit codes:
+//
+//	0  success, or no version by design: -unknown-branches skip on a branch outside the allowed ones
+//	1  any other failure, including check and audit violations
+//	2  malformed flags or arguments
+//	3  invalid configuration (flags, environment)
+//	4  invalid or unsafe branch name, an unknown branch under -unknown-branches error, or the release base build was
+//	   never tagged
+//	5  a version argument or tag does not parse
+//	6  no matching version or tag
+//	7  collision: the version was taken by a concurrent or earlier pipeline
+//	8  tag lookup or another git call failed; usually transient, worth a retry
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	versioner "github.com/drew-mcl/test"
+	"github.com/drew-mcl/test/invariants"
+)
+
+func main() {
+	args := os.Args[1:]
+	cmd := "version"
+	if len(args) > 0 && commands[args[0]].run != nil {
+		cmd, args = args[0], args[1:]
+	}
+	err := commands[cmd].run(args)
+	if gw := os.Getenv("VERSIONER_PUSHGATEWAY"); gw != "" && cmd != "serve" {
+		if err := metrics.Push(context.Background(), gw, "versioner"); err != nil {
+			fmt.Fprintln(os.Stderr, "versioner: warning:", err)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "versioner:", err)
+		if _, ok := err.(usageError); ok {
+			os.Exit(versioner.ExitUsage)
+		}
+		os.Exit(versioner.ExitCode(err))
+	}
+}
+
+// usageError is a malformed command line.
+type usageError string
+
+func (e usageError) Error() string { return "usage: " + string(e) }
+
+// metrics is shared by every BuildContext of the run: scraped in serve mode, pushed for other commands.
+var metrics = &versioner.Metrics{}
+
+// command is one subcommand. Its flags are not listed here: completion and man pages discover them from the
+// command's own flag set (see describe).
+type command struct {
+	run     func([]string) error
+	summary string
+	subs    []string // leading positional subcommands, e.g. locks list|clear
+}
+
+var commands = map[string]command{
+	"version":    {run: runVersion, summary: "version for the current pipeline, read from the CI_* environment"},
+	"components": {run: runComponents, summary: "versions of several monorepo components (version prefixes), computed in parallel"},
+	"tag":        {run: runTag, summary: "compute, tag HEAD and push the tag, retrying on concurrent release builds"},
+	"dev":        {run: runDev, summary: "collision-free local version for developer builds"},
+	"bump":       {run: runBump, summary: "next patch or build computed (and -tag pushed) from a workstation when CI is down"},
+	"local":      {run: runLocal, summary: "version from the local checkout: nearest tag, branch, distance and dirtiness"},
+
+	"dead-letters": {run: runDeadLetters, summary: "list (or -redeliver) webhook events that could not be delivered"},
+	"import":       {run: runImport, summary: "backfill the ledger from GitLab Releases and tags"},
+	"fleet":        {run: runFleet, summary: "report environments lagging behind the latest release"},
+	"locks":        {run: runLocks, summary: "inspect or release (stale) release-branch locks", subs: []string{"list", "clear"}},
+	"validate":     {run: runValidate, summary: "check that a version conforms to the scheme"},
+	"classify":     {run: runClassify, summary: "print default|release|feature for the branch"},
+	"plan":         {run: runPlan, summary: "preview the next default, release and feature versions"},
+	"cut":          {run: runCut, summary: "create and push release/v<tag> from the latest default-branch build"},
+	"record":       {run: runRecord, summary: "claim the version in the VERSIONER_STATE file instead of tagging"},
+	"reserve":      {run: runReserve, summary: "claim the next version as a pending ref on origin before building"},
+	"confirm":      {run: runConfirm, summary: "tag and push a reserved version after a successful build"},
+	"abandon":      {run: runAbandon, summary: "release a reservation after a failed build"},
+	"check":        {run: runCheck, summary: "report malformed, duplicate, missing-patch and off-branch tags"},
+	"audit":        {run: runAudit, summary: "check the tag history for unparsable versions, patch gaps and dates going back"},
+	"train":        {run: runTrain, summary: "decide (and -cut) the release branch of a scheduled release train"},
+	"promote":      {run: runPromote, summary: "release an existing snapshot build under its final version"},
+	"stale":        {run: runStale, summary: "report final tags older than a retention period or superseded by newer patches"},
+	"history":      {run: runHistory, summary: "list released versions newest-first with their commits and dates"},
+	"latest":       {run: runLatest, summary: "newest release of a component (version prefix)"},
+	"list":         {run: runList, summary: "releases newest-first, optionally since a date, as text or JSON"},
+	"where":        {run: runWhere, summary: "print the commit a version was built from"},
+	"init":         {run: runInit, summary: "write the CI configuration running versioner", subs: []string{"gitlab", "github"}},
+	"retract":      {run: runRetract, summary: "mark a bad release so it is never again treated as the latest"},
+	"migrate":      {run: runMigrate, summary: "mirror (or -rewrite) legacy vX.Y.Z tags into the configured scheme"},
+	"sign":         {run: runSign, summary: "cosign the version tag and/or an image digest"},
+	"diff":         {run: runDiff, summary: "upgrade|rollback|rebuild|same and the changed components of two versions"},
+	"serve":        {run: runServe, summary: "central version service backed by a shared ledger"},
+	"write":        {run: runWrite, summary: "stamp the version into VERSION, package.json, pyproject.toml, Chart.yaml …"},
+	"provenance":   {run: runProvenance, summary: "SLSA v1 provenance statement for the built files"},
+}
+
+func runVersion(args []string) error {
+	fs := newFlagSet("version")
+	cfg := configFlags(fs)
+	asJSON := fs.Bool("json", false, "print the version with kind, base tag, date, build, patch, commit … as JSON")
+	wh := webhookFlags(fs)
+	notify := fs.Bool("notify", os.Getenv("VERSIONER_NOTIFY_COMPUTED") != "", "POST a version.computed event to -webhook")
+	ldflags := fs.Bool("ldflags", false, "print a go build -ldflags stanza (with -json: add it to the JSON)")
+	ldpkg := fs.String("ldflags-pkg", "main", "package holding the version, commit and date variables")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	c.Time = c.Clock.Now() // one instant for the version, its manifest and the event
+	bi, err := c.BuildInfo()
+	if err != nil {
+		return err
+	}
+	for _, w := range bi.Warnings {
+		fmt.Fprintln(os.Stderr, "versioner: warning:", w)
+	}
+	m := versioner.Manifest{Version: bi.Version, Commit: c.CommitSHA, Time: c.Time.UTC(), Metadata: c.Metadata}
+	if *notify {
+		c.Webhooks = webhooks(*wh)
+		if err := c.Notify(context.Background(), versioner.EventComputed, m); err != nil {
+			fmt.Fprintln(os.Stderr, "versioner: warning:", err)
+		}
+	}
+	switch {
+	case *asJSON:
+		out := struct {
+			versioner.BuildInfo
+			LDFlags string `json:"ldflags,omitempty"`
+		}{BuildInfo: bi}
+		if *ldflags {
+			out.LDFlags = versioner.LDFlags(*ldpkg, m)
+		}
+		return json.NewEncoder(os.Stdout).Encode(out)
+	case *ldflags:
+		fmt.Println(versioner.LDFlags(*ldpkg, m))
+	default:
+		fmt.Println(bi.Version)
+	}
+	return nil
+}
+
+func runComponents(args []string) error {
+	fs := newFlagSet("components")
+	cfg := configFlags(fs)
+	parallel := fs.Int("parallel", 0, "components computed at once (default GOMAXPROCS)")
+	asJSON := fs.Bool("json", false, "print a component → version JSON object")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return usageError("versioner components [flags] component…")
+	}
+	if os.Getenv("VERSIONER_SCOPED_TAGS") != "" {
+		return fmt.Errorf("%w: components needs the tags of every prefix; unset VERSIONER_SCOPED_TAGS", versioner.ErrInvalidConfig)
+	}
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	vs, err := c.ComponentVersions(fs.Args(), *parallel)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(vs)
+	}
+	for _, p := range fs.Args() {
+		fmt.Println(p, vs[p])
+	}
+	return nil
+}
+
+func runTag(args []string) error {
+	fs := newFlagSet("tag")
+	cfg := configFlags(fs)
+	fs.IntVar(&cfg.PushRetries, "push-retries", orDefault(cfg.PushRetries, 3), "extra attempts after a rejected tag push")
+	fs.DurationVar(&cfg.PushBackoff, "push-backoff", orDefault(cfg.PushBackoff, time.Second), "first retry delay, doubled per attempt")
+	wh := webhookFlags(fs)
+	lockDir := fs.String("lock-dir", os.Getenv("VERSIONER_LOCK_DIR"), "shared directory serializing release-branch tagging")
+	lockTTL := fs.Duration("lock-ttl", 15*time.Minute, "age after which another pipeline's lock counts as abandoned")
+	ledger := fs.String("ledger", os.Getenv("VERSIONER_LEDGER"), "JSON-lines ledger recording every pushed version")
+	manifest := fs.String("manifest", "", "also write the manifest JSON to this file")
+	publish := fs.String("publish", os.Getenv("VERSIONER_PUBLISH"), "create a hosted release on final builds: gitlab or github")
+	gl := gitlabFlags(fs)
+	fs.StringVar(&cfg.Milestone, "milestone", cfg.Milestone, "gitlab: link the milestone titled by this template (\"Sprint {year}-{month}\") or @date")
+	fs.BoolVar(&cfg.CloseMilestone, "close-milestone", cfg.CloseMilestone, "with -milestone: close it after publishing")
+	fs.StringVar(&cfg.Changelog, "changelog", cfg.Changelog, "CHANGELOG.md to update after tagging")
+	fs.BoolVar(&cfg.ChangelogMR, "changelog-mr", cfg.ChangelogMR, "open a merge request for the changelog commit")
+	fs.BoolVar(&cfg.TagNotes, "notes", cfg.TagNotes, "attach the changelog since the previous tag to the tag annotation")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	if *lockDir != "" {
+		c.Locker = versioner.FileLocker{Dir: *lockDir, Owner: os.Getenv("CI_JOB_URL"), TTL: *lockTTL}
+	}
+	if *ledger != "" {
+		c.Ledger = versioner.FileLedger{Path: *ledger}
+	}
+	var pub versioner.Publisher
+	switch *publish {
+	case "":
+	case "gitlab":
+		pub = *gl
+	case "github":
+		pub = versioner.GitHub{}
+	default:
+		return fmt.Errorf("-publish: unknown host %q (want gitlab or github)", *publish)
+	}
+	m, err := c.TagAndPush()
+	if err != nil {
+		return err
+	}
+	if err := c.UpdateChangelog(m); err != nil {
+		return err
+	}
+	fmt.Println(m.Version)
+
+	if pub != nil && versioner.Classify(*cfg, c.Branch).Final() {
+		if err := c.PublishRelease(context.Background(), pub, m); err != nil {
+			return err
+		}
+	}
+	if *manifest != "" {
+		if cfg.DryRun {
+			fmt.Fprintf(os.Stderr, "dry-run: would write manifest %s\n", *manifest)
+		} else if err := versioner.WriteManifest(*manifest, m); err != nil {
+			return err
+		}
+	}
+	c.Webhooks = webhooks(*wh)
+	if err := c.Notify(context.Background(), versioner.EventTagged, m); err != nil {
+		// the tag is already pushed; a missed notification is recoverable from the dead-letter log
+		fmt.Fprintln(os.Stderr, "versioner: warning:", err)
+	}
+	return nil
+}
+
+func runDeadLetters(args []string) error {
+	fs := newFlagSet("dead-letters")
+	wh := webhookFlags(fs)
+	redeliver := fs.Bool("redeliver", false, "resend dead-lettered events for -webhook")
+	asJSON := fs.Bool("json", false, "print entries as JSON lines")
+	fs.Parse(args)
+
+	if *redeliver {
+		n, err := wh.Redeliver(context.Background())
+		fmt.Printf("redelivered %d event(s)\n", n)
+		return err
+	}
+	dls, err := versioner.ReadDeadLetters(wh.DeadLetter)
+	if err != nil {
+		return err
+	}
+	for _, dl := range dls {
+		if *asJSON {
+			b, _ := json.Marshal(dl)
+			fmt.Println(string(b))
+			continue
+		}
+		fmt.Printf("%s\t%s\t%d attempt(s)\t%s\n", dl.Time.Format(time.RFC3339), dl.URL, dl.Attempts, dl.Error)
+	}
+	return nil
+}
+
+func runDev(args []string) error {
+	fs := newFlagSet("dev")
+	prefix := fs.String("prefix", os.Getenv("VERSIONER_PREFIX"), "prepended as '<prefix>-'")
+	state := fs.String("state-dir", "", "where the local build counter lives (default: user cache dir)")
+	fs.Parse(args)
+
+	v, err := versioner.DevVersion(*prefix, *state)
+	if err != nil {
+		return err
+	}
+	fmt.Println(v)
+	return nil
+}
+
+func runBump(args []string) error {
+	fs := newFlagSet("bump")
+	cfg := configFlags(fs)
+	kind := fs.String("kind", "", "patch (on a release branch) or build (on the default branch)")
+	tag := fs.Bool("tag", false, "tag the version and push the tag to origin")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	if c.Branch == "" {
+		c.Branch = currentBranch()
+	}
+	mc, err := c.Manual(versioner.ManualKind(*kind))
+	if err != nil {
+		return err
+	}
+	v, err := mc.Version()
+	if err != nil {
+		return err
+	}
+	if !*tag {
+		fmt.Println(v)
+		return nil
+	}
+	head := gitOutput("rev-parse", "HEAD")
+	if up := gitOutput("rev-parse", "--verify", "-q", "origin/"+mc.Branch); up != head && !*yes {
+		q := fmt.Sprintf("HEAD %.12s is not origin/%s (%.12s); continue?", head, mc.Branch, up)
+		if up == "" {
+			q = fmt.Sprintf("origin/%s does not exist; continue?", mc.Branch)
+		}
+		if !confirm(q) {
+			return fmt.Errorf("aborted")
+		}
+	}
+	if !*yes && !confirm(fmt.Sprintf("tag %s on %.12s (%s) and push it to origin?", v, head, mc.Branch)) {
+		return fmt.Errorf("aborted")
+	}
+	m, err := mc.TagAndPush()
+	if err != nil {
+		return err
+	}
+	fmt.Println(m.Version)
+	return nil
+}
+
+// confirm asks a yes/no question on stderr; anything but y or yes (including no terminal) is no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func runLocal(args []string) error {
+	fs := newFlagSet("local")
+	cfg := baseConfig()
+	fs.StringVar(&cfg.Prefix, "prefix", cfg.Prefix, "prepended as '<prefix>-'")
+	fs.Parse(args)
+
+	v, err := versioner.BuildContext{Clock: versioner.SystemClock, Config: *cfg}.LocalVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Println(v)
+	return nil
+}
+
+func runImport(args []string) error {
+	fs := newFlagSet("import")
+	gl := gitlabFlags(fs)
+	ledger := fs.String("ledger", envOr("VERSIONER_LEDGER", "versions.jsonl"), "JSON-lines ledger to backfill")
+	fs.Parse(args)
+
+	rep, err := versioner.ImportGitLab(context.Background(), *gl, versioner.FileLedger{Path: *ledger})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d, already known %d, foreign tags skipped %d\n",
+		len(rep.Imported), len(rep.Existing), len(rep.Foreign))
+	return nil
+}
+
+func runFleet(args []string) error {
+	fs := newFlagSet("fleet")
+	envs := map[string]string{}
+	fs.Func("env", "environment version endpoint as name=url (repeatable)", func(s string) error {
+		name, u, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("want name=url, got %q", s)
+		}
+		envs[name] = u
+		return nil
+	})
+	maxLag := fs.Int("max-lag", 2, "releases an environment may trail the latest one")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	tags, err := versioner.GitTags()
+	if err != nil {
+		return err
+	}
+	rows, err := versioner.FleetReport(context.Background(), tags, versioner.HTTPDeployed{Endpoints: envs}, *maxLag)
+	if err != nil {
+		return err
+	}
+	lagging := 0
+	for _, r := range rows {
+		if r.Lagging {
+			lagging++
+		}
+	}
+	if *asJSON {
+		json.NewEncoder(os.Stdout).Encode(rows)
+	} else {
+		for _, r := range rows {
+			mark := ""
+			if r.Lagging {
+				mark = "LAGGING"
+			}
+			fmt.Printf("%-16s %-28s %3d behind %s\n", r.Environment, r.Running, r.Behind, mark)
+		}
+	}
+	if lagging > 0 {
+		return fmt.Errorf("%d environment(s) more than %d releases behind", lagging, *maxLag)
+	}
+	return nil
+}
+
+func runLocks(args []string) error {
+	if len(args) == 0 || args[0] != "list" && args[0] != "clear" {
+		return usageError("versioner locks list|clear [flags]")
+	}
+	sub := args[0]
+	fs := newFlagSet("locks " + sub)
+	l := versioner.FileLocker{}
+	fs.StringVar(&l.Dir, "lock-dir", os.Getenv("VERSIONER_LOCK_DIR"), "shared lock directory")
+	fs.DurationVar(&l.TTL, "ttl", 15*time.Minute, "age after which a lock counts as abandoned")
+	key := fs.String("key", "", "clear: release this lock even if it is not stale")
+	fs.Parse(args[1:])
+
+	switch {
+	case sub == "list":
+		locks, err := l.List()
+		if err != nil {
+			return err
+		}
+		for _, li := range locks {
+			state := "held"
+			if li.Stale {
+				state = "stale"
+			}
+			fmt.Printf("%-32s %-6s %s %s\n", li.Key, state, li.Acquired.Format(time.RFC3339), li.Owner)
+		}
+	case *key != "":
+		return l.Clear(*key)
+	default:
+		cleared, err := l.ClearStale()
+		for _, k := range cleared {
+			fmt.Println("cleared", k)
+		}
+		return err
+	}
+	return nil
+}
+
+func runValidate(args []string) error {
+	fs := newFlagSet("validate")
+	final := fs.Bool("final", false, "require a final (default-branch or release) version")
+	snapshot := fs.Bool("snapshot", false, "require a snapshot (feature) version")
+	fs.Parse(args)
+
+	for _, v := range fs.Args() {
+		if err := versioner.Validate(v); err != nil {
+			return err
+		}
+		if *final && !versioner.IsFinal(v) {
+			return fmt.Errorf("%s is not a final version", v)
+		}
+		if *snapshot && !versioner.IsSnapshot(v) {
+			return fmt.Errorf("%s is not a snapshot version", v)
+		}
+	}
+	return nil
+}
+
+func runClassify(args []string) error {
+	fs := newFlagSet("classify")
+	cfg := configFlags(fs)
+	final := fs.Bool("final", false, "fail unless the branch produces final versions")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	branch := c.Branch
+	if fs.NArg() > 0 {
+		branch = fs.Arg(0)
+	}
+	kind := versioner.Classify(*cfg, branch)
+	fmt.Println(kind)
+	if *final && !kind.Final() {
+		return fmt.Errorf("%s is a %s branch", branch, kind)
+	}
+	return nil
+}
+
+func runPlan(args []string) error {
+	fs := newFlagSet("plan")
+	cfg := configFlags(fs)
+	asJSON := fs.Bool("json", false, "print the plan as JSON")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	if c.Branch == "" {
+		c.Branch = currentBranch()
+	}
+	p, err := c.Plan()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(p)
+	}
+	fmt.Printf("default  %s\n", p.Default)
+	if p.ReleaseBranch != "" {
+		fmt.Printf("release  %s (%s)\n", p.Release, p.ReleaseBranch)
+	}
+	fmt.Printf("feature  %s\n", p.Feature)
+	return nil
+}
+
+func runCut(args []string) error {
+	fs := newFlagSet("cut")
+	cfg := configFlags(fs)
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	br, err := c.CutRelease()
+	if err != nil {
+		return err
+	}
+	fmt.Println(br)
+	return nil
+}
+
+func runReserve(args []string) error {
+	fs := newFlagSet("reserve")
+	cfg := configFlags(fs)
+	fs.IntVar(&cfg.PushRetries, "push-retries", orDefault(cfg.PushRetries, 3), "recomputations after another pipeline reserved the version first")
+	fs.DurationVar(&cfg.PushBackoff, "push-backoff", orDefault(cfg.PushBackoff, time.Second), "first retry delay, doubled per attempt")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	v, err := c.Reserve()
+	if err != nil {
+		return err
+	}
+	fmt.Println(v)
+	return nil
+}
+
+func runConfirm(args []string) error {
+	fs := newFlagSet("confirm")
+	cfg := configFlags(fs)
+	ledger := fs.String("ledger", os.Getenv("VERSIONER_LEDGER"), "JSON-lines ledger recording the confirmed version")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return usageError("versioner confirm <version>")
+	}
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	if *ledger != "" {
+		c.Ledger = versioner.FileLedger{Path: *ledger}
+	}
+	m, err := c.Confirm(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Println(m.Version)
+	return nil
+}
+
+func runAbandon(args []string) error {
+	fs := newFlagSet("abandon")
+	cfg := configFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return usageError("versioner abandon <version>")
+	}
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	return c.Abandon(fs.Arg(0))
+}
+
+func runRecord(args []string) error {
+	fs := newFlagSet("record")
+	cfg := configFlags(fs)
+	fs.IntVar(&cfg.PushRetries, "retries", orDefault(cfg.PushRetries, 5), "recomputations after another pipeline claimed the version first")
+	fs.Parse(args)
+	path := os.Getenv("VERSIONER_STATE")
+	if path == "" {
+		return fmt.Errorf("record: VERSIONER_STATE is not set")
+	}
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	c.Ledger = stateLedger(path)
+	m, err := c.Claim()
+	if err != nil {
+		return err
+	}
+	fmt.Println(m.Version)
+	return nil
+}
+
+func runAudit(args []string) error {
+	fs := newFlagSet("audit")
+	cfg := configFlags(fs)
+	log := fs.String("log", "", "also verify the hash chain of this VERSIONER_AUDIT file")
+	fs.Parse(args)
+
+	if *log != "" {
+		if err := (versioner.FileAudit{Path: *log}).Verify(); err != nil {
+			return err
+		}
+	}
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	ts, err := c.Tags()
+	if err != nil {
+		return err
+	}
+	vs := invariants.Check(ts, invariants.Options{DailySequence: cfg.DailySequence})
+	for _, v := range vs {
+		fmt.Println(v)
+	}
+	if len(vs) > 0 {
+		return fmt.Errorf("%d invariant violation(s) in %d tags", len(vs), len(ts))
+	}
+	return nil
+}
+
+func runCheck(args []string) error {
+	fs := newFlagSet("check")
+	cfg := configFlags(fs)
+	asJSON := fs.Bool("json", false, "print the violations as a JSON array")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	skipped, err := c.SkippedTags()
+	if err != nil {
+		return err
+	}
+	ts, err := c.Tags()
+	if err != nil {
+		return err
+	}
+	var vs []invariants.Violation
+	for _, s := range skipped {
+		vs = append(vs, invariants.Violation{Rule: invariants.RuleParse, Tags: []string{s.Tag}, Detail: s.Reason})
+	}
+	vs = append(vs, invariants.Check(ts, invariants.Options{
+		DailySequence: cfg.DailySequence,
+		OnBranch:      func(v string) (bool, error) { return c.OnReleaseLine(versioner.TagName(c.Config.Namespace, v)) },
+	})...)
+	if *asJSON {
+		if vs == nil {
+			vs = []invariants.Violation{}
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(vs); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range vs {
+			fmt.Println(v)
+		}
+	}
+	if len(vs) > 0 {
+		return fmt.Errorf("%d violation(s) in %d tags", len(vs), len(ts))
+	}
+	return nil
+}
+
+func runTrain(args []string) error {
+	fs := newFlagSet("train")
+	cfg := configFlags(fs)
+	fs.StringVar(&cfg.Train, "schedule", cfg.Train, "cron schedule of release trains, e.g. '0 6 * * 1'")
+	cut := fs.Bool("cut", false, "cut and push the release branch when a train is due")
+	fs.Parse(args)
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	d, err := c.Train()
+	if err != nil {
+		return err
+	}
+	switch {
+	case d.Cut && *cut:
+		br, err := c.CutRelease()
+		if err != nil {
+			return err
+		}
+		fmt.Println("cut", br)
+	case d.Cut:
+		fmt.Println("due", d.Branch)
+	default:
+		fmt.Println("reuse", d.Branch)
+	}
+	return nil
+}
+
+func runPromote(args []string) error {
+	fs := newFlagSet("promote")
+	cfg := configFlags(fs)
+	ledger := fs.String("ledger", os.Getenv("VERSIONER_LEDGER"), "JSON-lines ledger recording the promotion")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return usageError("versioner promote <snapshot-version> <commit>")
+	}
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	if *ledger != "" {
+		c.Ledger = versioner.FileLedger{Path: *ledger}
+	}
+	m, err := c.Promote(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	fmt.Println(m.Version)
+	return nil
+}
+
+func runHistory(args []string) error {
+	fs := newFlagSet("history")
+	var opts versioner.HistoryOptions
+	fs.StringVar(&opts.Prefix, "prefix", os.Getenv("VERSIONER_PREFIX"), "only versions with this prefix")
+	fs.StringVar(&opts.Namespace, "namespace", os.Getenv("VERSIONER_NAMESPACE"), "only tags in this namespace ('<namespace>/<version>')")
+	fs.StringVar(&opts.Base, "base", "", "only this default build (YYYYMMDD.<build>) and its release patches")
+	fs.IntVar(&opts.Limit, "n", 0, "show at most n versions")
+	fs.BoolVar(&opts.IncludeRetracted, "retracted", false, "include retracted versions, marked as such")
+	asJSON := fs.Bool("json", false, "print JSON lines")
+	fs.DurationVar(&opts.GitTimeout, "git-timeout", versioner.DefaultGitTimeout, "bound on the git command")
+	fs.Parse(args)
+
+	hs, err := versioner.History(opts)
+	if err != nil {
+		return err
+	}
+	for _, h := range hs {
+		if *asJSON {
+			if err := json.NewEncoder(os.Stdout).Encode(historyJSON(h)); err != nil {
+				return err
+			}
+			continue
+		}
+		printHistory(h)
+	}
+	return nil
+}
+
+func runStale(args []string) error {
+	fs := newFlagSet("stale")
+	var opts versioner.StaleOptions
+	fs.StringVar(&opts.Prefix, "prefix", os.Getenv("VERSIONER_PREFIX"), "only versions with this prefix")
+	fs.StringVar(&opts.Namespace, "namespace", os.Getenv("VERSIONER_NAMESPACE"), "only tags in this namespace ('<namespace>/<version>')")
+	maxAge := fs.Int("max-age-days", 0, "flag tags created more than this many days ago")
+	fs.IntVar(&opts.Superseded, "superseded", 0, "flag versions with at least this many newer patches on their release line")
+	asJSON := fs.Bool("json", false, "print the report as a JSON array")
+	fs.DurationVar(&opts.GitTimeout, "git-timeout", versioner.DefaultGitTimeout, "bound on the git command")
+	fs.Parse(args)
+	if *maxAge == 0 && opts.Superseded == 0 {
+		return usageError("versioner stale -max-age-days n and/or -superseded n [-prefix p] [-json]")
+	}
+	opts.MaxAge = time.Duration(*maxAge) * 24 * time.Hour
+
+	st, err := versioner.StaleTags(opts)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if st == nil {
+			st = []versioner.StaleTag{}
+		}
+		return json.NewEncoder(os.Stdout).Encode(st)
+	}
+	for _, s := range st {
+		fmt.Printf("%-24s %s %s %s\n", s.Tag, s.Commit[:min(len(s.Commit), 12)], s.Time.Format(time.DateOnly),
+			strings.Join(s.Reasons, "; "))
+	}
+	return nil
+}
+
+func runList(args []string) error {
+	fs := newFlagSet("list")
+	var opts versioner.HistoryOptions
+	fs.StringVar(&opts.Prefix, "component", os.Getenv("VERSIONER_PREFIX"), "component (version prefix) to list")
+	fs.StringVar(&opts.Namespace, "namespace", os.Getenv("VERSIONER_NAMESPACE"), "only tags in this namespace ('<namespace>/<version>')")
+	fs.StringVar(&opts.Since, "since", "", "only versions dated on or after YYYYMMDD")
+	fs.IntVar(&opts.Limit, "n", 0, "list at most n versions")
+	asJSON := fs.Bool("json", false, "print a JSON array")
+	fs.DurationVar(&opts.GitTimeout, "git-timeout", versioner.DefaultGitTimeout, "bound on the git command")
+	fs.Parse(args)
+
+	hs, err := versioner.History(opts)
+	if err != nil {
+		return err
+	}
+	if !*asJSON {
+		for _, h := range hs {
+			printHistory(h)
+		}
+		return nil
+	}
+	out := []any{}
+	for _, h := range hs {
+		out = append(out, historyJSON(h))
+	}
+	return json.NewEncoder(os.Stdout).Encode(out)
+}
+
+func runLatest(args []string) error {
+	fs := newFlagSet("latest")
+	var opts versioner.HistoryOptions
+	fs.StringVar(&opts.Prefix, "component", os.Getenv("VERSIONER_PREFIX"), "component (version prefix) to look up")
+	fs.StringVar(&opts.Namespace, "namespace", os.Getenv("VERSIONER_NAMESPACE"), "only tags in this namespace ('<namespace>/<version>')")
+	asJSON := fs.Bool("json", false, "print version, commit and time as JSON")
+	fs.DurationVar(&opts.GitTimeout, "git-timeout", versioner.DefaultGitTimeout, "bound on the git command")
+	fs.Parse(args)
+
+	h, err := versioner.Latest(opts)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return json.NewEncoder(os.Stdout).Encode(historyJSON(h))
+	}
+	fmt.Println(h.Version)
+	return nil
+}
+
+func historyJSON(h versioner.HistoryEntry) any {
+	return struct {
+		Version   string    `json:"version"`
+		Patch     int       `json:"patch"`
+		Commit    string    `json:"commit"`
+		Time      time.Time `json:"time"`
+		Retracted bool      `json:"retracted,omitempty"`
+	}{h.Version.String(), h.Version.Patch, h.Commit, h.Time, h.Retracted}
+}
+
+func printHistory(h versioner.HistoryEntry) {
+	mark := ""
+	if h.Retracted {
+		mark = " retracted"
+	}
+	fmt.Printf("%-24s %s %s%s\n", h.Version, h.Commit[:min(len(h.Commit), 12)], h.Time.Format(time.DateOnly), mark)
+}
+
+func runSign(args []string) error {
+	fs := newFlagSet("sign")
+	cfg := configFlags(fs)
+	cs := versioner.Cosign{IDToken: os.Getenv("SIGSTORE_ID_TOKEN"), Key: os.Getenv("COSIGN_KEY")}
+	fs.StringVar(&cs.Binary, "cosign", "cosign", "cosign binary")
+	image := fs.String("image", "", "image to sign, as repository@sha256:<digest>")
+	bundle := fs.String("tag-bundle", "", "sign the annotated tag and write the sigstore bundle here")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *image == "" && *bundle == "" {
+		return usageError("versioner sign [-image repo@sha256:…] [-tag-bundle file] <version>")
+	}
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	v := fs.Arg(0)
+	if *bundle != "" {
+		if err := c.SignTag(cs, v, *bundle); err != nil {
+			return err
+		}
+	}
+	if *image != "" {
+		repo, digest, _ := strings.Cut(*image, "@")
+		return c.SignImage(cs, repo, digest, v)
+	}
+	return nil
+}
+
+func runRetract(args []string) error {
+	fs := newFlagSet("retract")
+	cfg := configFlags(fs)
+	reason := fs.String("reason", "", "why the version is withdrawn (required)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return usageError("versioner retract -reason <text> <version>")
+	}
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	return c.Retract(fs.Arg(0), *reason)
+}
+
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate")
+	cfg := configFlags(fs)
+	rewrite := fs.Bool("rewrite", false, "delete the legacy tags once their new tags are pushed")
+	asJSON := fs.Bool("json", false, "print the mapping as a JSON array")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		return usageError("versioner migrate [-rewrite] [-dry-run] [-json] [flags]")
+	}
+
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	ms, err := c.MigrateTags(*rewrite)
+	if err != nil {
+		return err
+	}
+	conflicts := 0
+	if *asJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(ms); err != nil {
+			return err
+		}
+	}
+	for _, m := range ms {
+		status := "created"
+		switch {
+		case m.Conflict != "":
+			status, conflicts = "conflict: "+m.Conflict, conflicts+1
+		case m.Migrated:
+			status = "exists"
+		case cfg.DryRun:
+			status = "planned"
+		}
+		if !*asJSON {
+			fmt.Printf("%s\t%s\t%.8s\t%s\n", m.From, m.To, m.Commit, status)
+		}
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("%d legacy tags left unmigrated", conflicts)
+	}
+	return nil
+}
+
+func runInit(args []string) error {
+	gen := map[string]func(versioner.ScaffoldOptions) ([]byte, error){
+		"gitlab": versioner.GitLabCI,
+		"github": versioner.GitHubAction,
+	}
+	if len(args) == 0 || gen[args[0]] == nil {
+		return usageError("versioner init gitlab|github [flags]")
+	}
+	host := args[0]
+	def := map[string]string{"gitlab": ".gitlab/versioner.yml", "github": ".github/actions/versioner/action.yml"}[host]
+	fs := newFlagSet("init " + host)
+	var opts versioner.ScaffoldOptions
+	fs.StringVar(&opts.Path, "o", def, "file to write (- for stdout)")
+	fs.StringVar(&opts.Image, "image", versioner.DefaultImage, "gitlab: Go image the jobs install and run the CLI in")
+	fs.StringVar(&opts.Go, "go", versioner.DefaultGo, "github: Go version to set up")
+	fs.StringVar(&opts.Release, "release", "latest", "CLI module version to install")
+	fs.StringVar(&opts.Publish, "publish", "", "tag job: also create the hosted release (gitlab or github)")
+	fs.Func("flag", "extra flag for every versioner invocation, e.g. -flag=-prefix -flag=api (repeatable)", func(v string) error {
+		opts.Flags = append(opts.Flags, v)
+		return nil
+	})
+	force := fs.Bool("force", false, "overwrite an existing file")
+	fs.Parse(args[1:])
+
+	out := opts.Path
+	if out == "-" {
+		opts.Path = "" // the usage instructions then name the default location
+	}
+	b, err := gen[host](opts)
+	if err != nil {
+		return err
+	}
+	if out == "-" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	if _, err := os.Stat(out); err == nil && !*force {
+		return fmt.Errorf("%s exists (use -force to overwrite)", out)
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, b, 0o644); err != nil {
+		return err
+	}
+	if host == "github" {
+		fmt.Printf("wrote %s; use it in a workflow step:\n\n- id: version\n  uses: ./%s\n", out, filepath.ToSlash(filepath.Dir(out)))
+		return nil
+	}
+	fmt.Printf("wrote %s; add to .gitlab-ci.yml:\n\ninclude:\n  - local: %s\n", out, out)
+	return nil
+}
+
+func runWhere(args []string) error {
+	fs := newFlagSet("where")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return usageError("versioner where <version>")
+	}
+	sha, err := versioner.Resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Println(sha)
+	return nil
+}
+
+func runDiff(args []string) error {
+	fs := newFlagSet("diff")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return usageError("versioner diff <from> <to>")
+	}
+	a, err := versioner.Parse(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := versioner.Parse(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	fmt.Println(versioner.Diff(a, b))
+	return nil
+}
+
+func runWrite(args []string) error {
+	fs := newFlagSet("write")
+	cfg := configFlags(fs)
+	version := fs.String("version", "", "version to write (default: computed for this pipeline)")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return usageError("versioner write [flags] <file[:kind[:expr]]>...")
+	}
+
+	var targets []versioner.FileTarget
+	for _, a := range fs.Args() {
+		t, err := versioner.ParseFileTarget(a)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, t)
+	}
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	v := *version
+	if v == "" {
+		var err error
+		if v, err = c.Version(); err != nil {
+			return err
+		}
+	}
+	changed, err := c.WriteFiles(v, targets)
+	for _, p := range changed {
+		fmt.Println(p)
+	}
+	return err
+}
+
+func runProvenance(args []string) error {
+	fs := newFlagSet("provenance")
+	cfg := configFlags(fs)
+	var opts versioner.ProvenanceOptions
+	fs.StringVar(&opts.BuilderID, "builder", os.Getenv("VERSIONER_BUILDER_ID"), "builder ID (default: pipeline URL)")
+	fs.StringVar(&opts.RepoURL, "repo", os.Getenv("CI_PROJECT_URL"), "source repository URL")
+	fs.Parse(args)
+
+	for _, p := range fs.Args() {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		opts.Subjects = append(opts.Subjects, versioner.Subject{
+			Name: filepath.Base(p), Digest: map[string]string{"sha256": hex.EncodeToString(sum[:])}})
+	}
+	c, err := buildContext(*cfg)
+	if err != nil {
+		return err
+	}
+	m, err := c.Manifest()
+	if err != nil {
+		return err
+	}
+	b, err := c.Provenance(m, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	cfg := configFlags(fs)
+	addr := fs.String("addr", envOr("VERSIONER_ADDR", ":8080"), "listen address")
+	ledger := fs.String("ledger", envOr("VERSIONER_LEDGER", "versions.jsonl"), "JSON-lines ledger shared by all projects")
+	fs.Parse(args)
+
+	// the service answers from the ledger alone; requests must never run git in whatever directory it was started in
+	versioner.SetRunner(versioner.Sandbox{})
+	s := &versioner.Server{Config: *cfg, Ledger: versioner.FileLedger{Path: *ledger}, Metrics: metrics}
+	fmt.Fprintln(os.Stderr, "versioner: serving on", *addr)
+	return http.ListenAndServe(*addr, s)
+}
+
+/* ---------- shared flag/env plumbing ------------------------------------------ */
+
+func configFlags(fs *flag.FlagSet) *versioner.Config {
+	cfg := baseConfig()
+	fs.StringVar(&cfg.DefaultBranch, "default-branch", cfg.DefaultBranch, "default branch name")
+	fs.StringVar(&cfg.Prefix, "prefix", cfg.Prefix, "prepended as '<prefix>-'")
+	fs.StringVar(&cfg.Namespace, "namespace", cfg.Namespace, "tag as '<namespace>/<version>', counting only that namespace's tags")
+	fs.StringVar(&cfg.FeatureSuffix, "suffix", cfg.FeatureSuffix, "appended as '-<suffix>' on feature builds")
+	fs.Func("label", "variant label appended to feature builds (repeatable, e.g. -label arm64 -label debug)", func(s string) error {
+		cfg.SuffixLabels = append(cfg.SuffixLabels, s)
+		return nil
+	})
+	fs.Func("channel", "branch=channel mapping (repeatable; globs and @schedule allowed, e.g. develop=beta)", func(s string) error {
+		br, ch, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("want branch=channel, got %q", s)
+		}
+		if cfg.Channels == nil {
+			cfg.Channels = map[string]versioner.Channel{}
+		}
+		cfg.Channels[br] = versioner.Channel(ch)
+		return nil
+	})
+	fs.BoolVar(&cfg.SanitizeAffixes, "sanitize", cfg.SanitizeAffixes, "rewrite an invalid prefix or suffix instead of failing")
+	fs.StringVar(&cfg.Timezone, "timezone", cfg.Timezone, "IANA timezone for the date (default UTC)")
+	fs.IntVar(&cfg.Epoch, "epoch", cfg.Epoch, "scheme generation written as '<n>!' before the date")
+	fs.BoolVar(&cfg.DailySequence, "daily-sequence", cfg.DailySequence, "number default-branch builds 1, 2, … per day")
+	fs.IntVar(&cfg.MaxLength, "max-length", cfg.MaxLength, "cap the version length, hashing long suffixes (e.g. 63, 128)")
+	fs.IntVar(&cfg.BuildWidth, "build-width", cfg.BuildWidth, "zero-pad the build number to this width")
+	fs.IntVar(&cfg.PatchWidth, "patch-width", cfg.PatchWidth, "zero-pad the release patch to this width")
+	fs.BoolVar(&cfg.Nightly, "nightly", cfg.Nightly, "scheduled pipelines emit YYYYMMDD-nightly.<n>, numbered per day")
+	fs.IntVar(&cfg.NightlyKeepDays, "nightly-keep-days", cfg.NightlyKeepDays, "tagging a nightly prunes nightly tags older than this many days")
+	fs.BoolVar(&cfg.SemVer, "semver", cfg.SemVer, "MAJOR.MINOR.PATCH bumped by conventional commits since the latest tag")
+	fs.Func("bump", "semver: force the bump (major, minor or patch) instead of reading commit messages", func(s string) error {
+		cfg.Bump = versioner.Bump(s)
+		return nil
+	})
+	fs.BoolVar(&cfg.LegacyTags, "legacy-tags", cfg.LegacyTags, "semver: continue numbering from existing vX.Y.Z tags")
+	fs.BoolVar(&cfg.RequireBaseTag, "require-base", cfg.RequireBaseTag, "release builds fail unless the branch's base tag exists")
+	fs.BoolVar(&cfg.Candidates, "rc", cfg.Candidates, "release branches emit <base>-rc.<n> until -rc-final")
+	fs.BoolVar(&cfg.Final, "rc-final", cfg.Final, "with -rc: approve the first final release patch")
+	fs.BoolVar(&cfg.Monotonic, "monotonic", cfg.Monotonic, "fail unless the version sorts after the latest tag")
+	fs.BoolVar(&cfg.NoCollisions, "no-collisions", cfg.NoCollisions, "fail if the tag already exists (release branches take the next patch)")
+	fs.BoolVar(&cfg.BranchSlug, "branch-slug", cfg.BranchSlug, "add the sanitized branch name to feature builds")
+	fs.StringVar(&cfg.TicketPattern, "tickets", cfg.TicketPattern, "record the issue IDs this regex finds in the branch name (e.g. "+versioner.DefaultTicketPattern+")")
+	fs.Func("feature-branch", "glob of the branches allowed feature builds under -unknown-branches (repeatable)", func(v string) error {
+		cfg.FeatureBranches = append(cfg.FeatureBranches, v)
+		return nil
+	})
+	fs.Func("unknown-branches", "other branches: error (exit 4) or skip (no version, exit 0) instead of a feature build", func(v string) error {
+		cfg.UnknownBranches = versioner.UnknownBranches(v)
+		return nil
+	})
+	fs.BoolVar(&cfg.MergeRequest, "mr", cfg.MergeRequest, "add '-mr<IID>' to feature builds in merge-request pipelines")
+	fs.BoolVar(&cfg.Reruns, "reruns", cfg.Reruns, "re-runs of old release commits reproduce their version or fail")
+	fs.BoolVar(&cfg.CommitMeta, "commit-meta", cfg.CommitMeta, "append '+<shortsha>' build metadata")
+	fs.BoolVar(&cfg.BestEffortTags, "best-effort-tags", cfg.BestEffortTags, "treat a failed tag lookup as no tags instead of failing")
+	fs.StringVar(&cfg.ForceVersion, "force-version", cfg.ForceVersion, "emergency override: use this (validated) version as is")
+	fs.StringVar(&pipelineSource, "build-source", os.Getenv("VERSIONER_BUILD_SOURCE"),
+		"pipeline number: iid (CI_PIPELINE_IID), pipeline (CI_PIPELINE_ID) or job (CI_JOB_ID)")
+	fs.Func("policy", "naming policy the version must pass, rewritten if need be: docker, k8s-label or npm (repeatable)", func(v string) error {
+		cfg.Policies = append(cfg.Policies, v)
+		return nil
+	})
+	fs.Func("alias", "moving tag to force-update to each newer final version: latest or stable (repeatable)", func(v string) error {
+		cfg.Aliases = append(cfg.Aliases, versioner.Alias(v))
+		return nil
+	})
+	fs.BoolVar(&cfg.DryRun, "dry-run", cfg.DryRun, "print side effects instead of performing them")
+	fs.DurationVar(&cfg.Timeouts.Git, "git-timeout", orDefault(cfg.Timeouts.Git, versioner.DefaultGitTimeout), "bound on each git command run for the build, tag lookups and fetches included")
+	fs.DurationVar(&cfg.Timeouts.API, "api-timeout", orDefault(cfg.Timeouts.API, versioner.DefaultAPITimeout), "bound on each GitLab/GitHub API call")
+	fs.DurationVar(&cfg.Timeouts.Webhook, "webhook-timeout", orDefault(cfg.Timeouts.Webhook, versioner.DefaultWebhookTimeout), "bound on each webhook delivery attempt")
+	return cfg
+}
+
+func webhookFlags(fs *flag.FlagSet) *versioner.Webhook {
+	wh := &versioner.Webhook{Secret: os.Getenv("VERSIONER_WEBHOOK_SECRET")}
+	fs.StringVar(&wh.URL, "webhook", os.Getenv("VERSIONER_WEBHOOK_URL"), "POST release events to this URL (comma-separated for several)")
+	fs.IntVar(&wh.Retries, "webhook-retries", 3, "extra delivery attempts")
+	fs.StringVar(&wh.DeadLetter, "dead-letter", envOr("VERSIONER_DEAD_LETTER", "versioner-dead-letters.jsonl"),
+		"JSON-lines log of undeliverable events")
+	return wh
+}
+
+// webhooks expands a comma-separated -webhook into one endpoint per URL sharing the other settings.
+func webhooks(wh versioner.Webhook) []versioner.Webhook {
+	var whs []versioner.Webhook
+	for _, u := range strings.Split(wh.URL, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			w := wh
+			w.URL = u
+			whs = append(whs, w)
+		}
+	}
+	return whs
+}
+
+func gitlabFlags(fs *flag.FlagSet) *versioner.GitLab {
+	gl := &versioner.GitLab{Token: os.Getenv("GITLAB_TOKEN"), JobToken: os.Getenv("CI_JOB_TOKEN")}
+	fs.StringVar(&gl.BaseURL, "gitlab-api", os.Getenv("CI_API_V4_URL"), "GitLab API v4 base URL")
+	fs.StringVar(&gl.Project, "project", os.Getenv("CI_PROJECT_ID"), "GitLab project ID or path")
+	return gl
+}
+
+func buildContext(cfg versioner.Config) (versioner.BuildContext, error) {
+	var logger *slog.Logger
+	if os.Getenv("VERSIONER_DEBUG") != "" {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	c := versioner.BuildContext{
+		Branch:         envOr("CI_COMMIT_BRANCH", os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME")),
+		CommitSHA:      os.Getenv("CI_COMMIT_SHA"),
+		MergeReqID:     os.Getenv("CI_MERGE_REQUEST_IID"),
+		PipelineSource: os.Getenv("CI_PIPELINE_SOURCE"),
+		PipelineURL:    os.Getenv("CI_PIPELINE_URL"),
+		Clock:          versioner.SystemClock,
+		Config:         cfg,
+		Logger:         logger,
+		Metrics:        metrics,
+		Context:        versioner.WithTraceParent(context.Background(), os.Getenv("TRACEPARENT")),
+	}
+	var err error
+	if c.PipelineID, err = versioner.PipelineNumber(pipelineSource); err != nil {
+		return versioner.BuildContext{}, err
+	}
+	if logger != nil {
+		c.Tracer = versioner.LogTracer{Logger: logger}
+	}
+	src := c.GitTags
+	remote := os.Getenv("VERSIONER_REMOTE")
+	if remote == "ci" {
+		remote = versioner.ProjectURL(os.Getenv("CI_SERVER_URL"), os.Getenv("CI_PROJECT_PATH"))
+	}
+	state := os.Getenv("VERSIONER_STATE")
+	switch {
+	case state != "":
+		src = versioner.LedgerTags(stateLedger(state))
+	case remote != "":
+		src = c.RemoteTags(remote, os.Getenv("CI_JOB_TOKEN"))
+	case os.Getenv("VERSIONER_SCOPED_TAGS") != "":
+		src = c.RefTags(c.TagPatterns()...)
+	}
+	if state == "" && remote == "" && os.Getenv("VERSIONER_NO_FETCH_TAGS") == "" {
+		src = c.FetchTags(src, "origin")
+	}
+	c.LookupTags = src
+	// The cache is keyed on the local tag refs: remote and ledger sources run without a clone, and a ledger changes
+	// without them, so a Claim retry must re-read it.
+	if state == "" && remote == "" {
+		tc := &versioner.TagCache{Source: src, File: os.Getenv("VERSIONER_TAG_CACHE")}
+		c.LookupTags = tc.Tags
+	}
+	switch a := os.Getenv("VERSIONER_AUDIT"); {
+	case strings.HasPrefix(a, "http://") || strings.HasPrefix(a, "https://"):
+		c.Audit = versioner.HTTPAudit{Webhook: versioner.Webhook{URL: a, Secret: os.Getenv("VERSIONER_AUDIT_SECRET"), Retries: 3}}
+	case a != "":
+		c.Audit = versioner.FileAudit{Path: a}
+	}
+	if n := os.Getenv("VERSIONER_BUILD_NUMBER"); n != "" {
+		c.LookupBuild = func() (string, error) { return n, nil }
+	}
+	return c, nil
+}
+
+// stateLedger opens VERSIONER_STATE: a local JSON file, or gs://bucket/key and s3://bucket/key objects written with
+// generation/ETag preconditions (credentials from GOOGLE_OAUTH_ACCESS_TOKEN and AWS_* respectively).
+func stateLedger(state string) versioner.Ledger {
+	scheme, rest, _ := strings.Cut(state, "://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	switch scheme {
+	case "gs":
+		return versioner.ObjectLedger{Key: key, Store: versioner.GCS{Bucket: bucket, Token: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")}}
+	case "s3":
+		return versioner.ObjectLedger{Key: key, Store: versioner.S3{
+			Bucket:       bucket,
+			Region:       envOr("AWS_REGION", os.Getenv("AWS_DEFAULT_REGION")),
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			Endpoint:     os.Getenv("AWS_ENDPOINT_URL_S3"),
+		}}
+	default:
+		return versioner.StateFile{Path: state}
+	}
+}
+
+// pipelineSource selects the CI variable behind BuildContext.PipelineID; see versioner.PipelineNumber.
+var pipelineSource string
+
+// currentBranch is the checked-out branch for commands that also run outside CI.
+func currentBranch() string {
+	return gitOutput("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// gitOutput runs a git query in the working directory through the library's Runner; failures read as "".
+func gitOutput(args ...string) string {
+	out, err := versioner.Git(args...)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// baseConfig is what the config flags default to, in increasing precedence: main or CI_DEFAULT_BRANCH, the
+// VERSIONER_CONFIG file's default profile and its VERSIONER_PROFILE profile, then the VERSIONER_* variables. A broken
+// file or variable ends the run: no command should fall back to settings nobody asked for.
+func baseConfig() *versioner.Config {
+	cfg := versioner.Config{DefaultBranch: envOr("CI_DEFAULT_BRANCH", "main")}
+	err := func() (err error) {
+		if path := os.Getenv("VERSIONER_CONFIG"); path != "" {
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("%w: %w", versioner.ErrInvalidConfig, err)
+			}
+			defer f.Close()
+			if cfg, err = cfg.Overlay(f, os.Getenv("VERSIONER_PROFILE")); err != nil {
+				return err
+			}
+		}
+		cfg, err = cfg.OverlayEnv(os.LookupEnv)
+		return err
+	}()
+	if err != nil && !describing {
+		fmt.Fprintln(os.Stderr, "versioner:", err)
+		os.Exit(versioner.ExitCode(err))
+	}
+	return &cfg
+}
+
+// orDefault is v unless it is the zero value.
+func orDefault[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+	return v
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
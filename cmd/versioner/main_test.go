@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the tests run the CLI as a subprocess of the test binary, so exit codes and output are observed as
+// a pipeline sees them.
+func TestMain(m *testing.M) {
+	if os.Getenv("VERSIONER_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// cli runs the CLI in dir with only env and a minimal git identity set, and returns stdout, stderr and the exit
+// code.
+func cli(t *testing.T, dir string, env []string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append([]string{
+		"VERSIONER_TEST_MAIN=1", "PATH=" + os.Getenv("PATH"), "HOME=" + t.TempDir(),
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com",
+	}, env...)
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var ee *exec.ExitError
+	if err != nil && !errors.As(err, &ee) {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(out.String()), strings.TrimSpace(errOut.String()), cmd.ProcessState.ExitCode()
+}
+
+// repo creates a git repository with one commit carrying tags and returns its directory.
+func repo(t *testing.T, tags ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := filepath.Join(t.TempDir(), "repo")
+	for _, args := range append([][]string{
+		{"init", "-q", "-b", "main", dir},
+		{"-C", dir, "commit", "-q", "--allow-empty", "-m", "fix: init"},
+	}, tagArgs(dir, tags)...) {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func tagArgs(dir string, tags []string) [][]string {
+	var as [][]string
+	for _, tag := range tags {
+		as = append(as, []string{"-C", dir, "tag", tag})
+	}
+	return as
+}
+
+var mainBuild = []string{"CI_COMMIT_BRANCH=main", "CI_PIPELINE_IID=5", "VERSIONER_NO_FETCH_TAGS=1"}
+
+func TestSemVer(t *testing.T) {
+	dir := repo(t, "1.2.3")
+	for _, args := range [][]string{{"-semver"}, {"-semver", "-monotonic"}, {"-semver", "-json"}} {
+		out, errOut, code := cli(t, dir, mainBuild, args...)
+		if code != 0 || !strings.Contains(out, "1.2.4") {
+			t.Fatalf("%v: exit %d, %q %q", args, code, out, errOut)
+		}
+	}
+}
+
+func TestRemoteTagsWithoutClone(t *testing.T) {
+	origin := repo(t, "20250101.3")
+	env := append([]string{"CI_COMMIT_BRANCH=release/v20250101.3", "CI_PIPELINE_IID=5", "VERSIONER_REMOTE=" + origin},
+		noRepo(t)...)
+	out, errOut, code := cli(t, t.TempDir(), env)
+	if code != 0 || out != "20250101.3.1" {
+		t.Fatalf("exit %d, %q %q", code, out, errOut)
+	}
+}
+
+// noRepo keeps git from finding a repository above the test's temporary directories.
+func noRepo(t *testing.T) []string {
+	return []string{"GIT_CEILING_DIRECTORIES=" + filepath.Dir(t.TempDir())}
+}
+
+func TestStateWithoutClone(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(state, []byte(`{"versions":[{"version":"20250101.3"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := append([]string{"CI_COMMIT_BRANCH=release/v20250101.3", "CI_PIPELINE_IID=5", "CI_COMMIT_SHA=" + strings.Repeat("a", 40),
+		"VERSIONER_STATE=" + state},
+		noRepo(t)...)
+	for _, want := range []string{"20250101.3.1", "20250101.3.2"} {
+		if out, errOut, code := cli(t, dir, env, "record"); code != 0 || out != want {
+			t.Fatalf("exit %d, %q %q want %s", code, out, errOut, want)
+		}
+	}
+}
+
+func TestInvalidBuildSourceIsConfigError(t *testing.T) {
+	_, errOut, code := cli(t, t.TempDir(), append(noRepo(t), mainBuild...), "-build-source", "commit")
+	if code != 3 || !strings.Contains(errOut, "unknown pipeline number source") {
+		t.Fatalf("exit %d, %q want 3", code, errOut)
+	}
+}
+
+func TestCheckInNamespace(t *testing.T) {
+	// the top-level stream misses patch 1; the cli stream is sound
+	dir := repo(t, "cli/20250101.3", "cli/20250101.3.1", "20250101.3", "20250101.3.2")
+	env := append([]string{"CI_COMMIT_BRANCH=main", "VERSIONER_NO_FETCH_TAGS=1"}, noRepo(t)...)
+	if out, errOut, code := cli(t, dir, env, "check", "-namespace", "cli"); code != 0 {
+		t.Fatalf("cli namespace: exit %d, %q %q", code, out, errOut)
+	}
+	if out, _, code := cli(t, dir, env, "check"); code == 0 || !strings.Contains(out, "contiguous") {
+		t.Fatalf("top level: exit %d, %q want a contiguous violation", code, out)
+	}
+}
@@ -0,0 +1,112 @@
+package versioner
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// ComponentVersions computes the version of every monorepo component (a Config.Prefix, "" for the unprefixed one)
+// concurrently, at most parallel at a time (GOMAXPROCS when parallel <= 0). The tags are looked up once and every
+// component computes over that snapshot, so LookupTags must list the tags of all components (not RefTags scoped to
+// one prefix). The first failure stops components that have not started yet and is returned, naming its component.
+func (c BuildContext) ComponentVersions(prefixes []string, parallel int) (map[string]string, error) {
+	c = c.pinTime()
+	ts, err := c.tags()
+	if err != nil {
+		return nil, err
+	}
+	ns := make([]string, len(ts))
+	for i, t := range ts {
+		ns[i] = c.tagName(t)
+	}
+	snapshot := func() ([]string, error) { return slices.Clone(ns), nil }
+	if c.Audit != nil {
+		c.Audit = &lockedAudit{sink: c.Audit}
+	}
+	if parallel <= 0 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+
+	var g group
+	g.limit(parallel)
+	out := make(map[string]string, len(prefixes))
+	var mu sync.Mutex
+	for _, p := range prefixes {
+		cc := c
+		cc.Config.Prefix = p
+		cc.LookupTags = snapshot
+		g.do(func() error {
+			v, err := cc.Version()
+			if err != nil {
+				return fmt.Errorf("component %q: %w", p, err)
+			}
+			mu.Lock()
+			out[p] = v
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.wait(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// group is a minimal errgroup: do runs fn on its own goroutine once one of the limit slots is free, unless an earlier
+// fn failed; wait returns the first error.
+type group struct {
+	wg  sync.WaitGroup
+	sem chan struct{}
+	mu  sync.Mutex
+	err error
+}
+
+func (g *group) limit(n int) { g.sem = make(chan struct{}, n) }
+
+func (g *group) do(fn func() error) {
+	g.sem <- struct{}{}
+	if g.failed() {
+		<-g.sem
+		return
+	}
+	g.wg.Add(1)
+	go func() {
+		defer func() { <-g.sem; g.wg.Done() }()
+		if err := fn(); err != nil {
+			g.mu.Lock()
+			if g.err == nil {
+				g.err = err
+			}
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *group) failed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err != nil
+}
+
+func (g *group) wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+// lockedAudit serializes appends to a sink, such as FileAudit, whose hash chain is not safe for concurrent writers.
+type lockedAudit struct {
+	mu   sync.Mutex
+	sink AuditSink
+}
+
+func (l *lockedAudit) Append(r AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sink.Append(r)
+}
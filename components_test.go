@@ -0,0 +1,41 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestComponentVersions(t *testing.T) {
+	var lookups atomic.Int32
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.LookupTags = func() ([]string, error) {
+		lookups.Add(1)
+		return []string{"api-20250428.300", "web-20250428.320", "20250428.310"}, nil
+	}
+	c.PipelineID = "315"
+
+	got, err := c.ComponentVersions([]string{"api", "web", ""}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"api": "api-20250428.315", "web": "web-20250428.315", "": "20250428.315"}
+	for p, v := range want {
+		if got[p] != v {
+			t.Fatalf("%q: got %s want %s", p, got[p], v)
+		}
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Fatalf("tags looked up %d times want 1", n)
+	}
+}
+
+func TestComponentVersionsError(t *testing.T) {
+	c := ctx("main", Config{DefaultBranch: "main", Monotonic: true}, []string{"web-20250428.400"})
+	_, err := c.ComponentVersions([]string{"api", "web"}, 1)
+	var me *MonotonicityError
+	if !errors.As(err, &me) || !strings.Contains(err.Error(), `component "web"`) {
+		t.Fatalf("got %v want a monotonicity error for web", err)
+	}
+}
@@ -0,0 +1,143 @@
+package versioner
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Validate reports every mistake in cfg at once as a *ConfigError: settings that cannot work (an empty or
+// release-shaped DefaultBranch, affixes that would break tag parsing, an unknown timezone or schedule, negative
+// sizes) and options that contradict each other or are ignored in combination. Version calls it before computing.
+func (cfg Config) Validate() error {
+	var ps []error
+	add := func(format string, args ...any) { ps = append(ps, fmt.Errorf(format, args...)) }
+
+	switch {
+	case cfg.DefaultBranch == "":
+		add("DefaultBranch is empty: set it to the default branch (main, master …) so other branches are not mistaken for it")
+	case strings.HasPrefix(cfg.DefaultBranch, "release/"):
+		add("DefaultBranch %q is a release branch name: release branches are recognized by their 'release/' prefix", cfg.DefaultBranch)
+	case CheckRefName(cfg.DefaultBranch) != nil:
+		add("DefaultBranch %q is not a valid branch name", cfg.DefaultBranch)
+	}
+	if err := checkNamespace(cfg.Namespace); err != nil {
+		add("Namespace: %v", err)
+	}
+	if _, err := checkAffixes(cfg); err != nil {
+		ps = append(ps, err)
+	}
+	for _, p := range slices.Sorted(maps.Keys(cfg.Channels)) {
+		ch := cfg.Channels[p]
+		if _, err := path.Match(p, ""); err != nil && p != ScheduleKey {
+			add("Channels pattern %q is not a valid glob: %v", p, err)
+		}
+		if name := strings.Trim(string(ch), "-"); name != "" && !suffixRE.MatchString(name) {
+			ps = append(ps, &AffixError{"Channels", string(ch), "may contain only letters, digits, '.', '_' and '-'"})
+		}
+	}
+	for _, a := range cfg.Aliases {
+		if err := checkAlias(a); err != nil {
+			add("Aliases: %v", err)
+		}
+	}
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			add("Timezone %q is not an IANA zone name such as Europe/Berlin", cfg.Timezone)
+		}
+	}
+	if cfg.Train != "" {
+		if _, err := ParseSchedule(cfg.Train); err != nil {
+			add("Train %q is not a 5-field cron schedule", cfg.Train)
+		}
+	}
+
+	for _, n := range []struct {
+		name string
+		v    int64
+	}{
+		{"Epoch", int64(cfg.Epoch)}, {"MaxLength", int64(cfg.MaxLength)}, {"BuildWidth", int64(cfg.BuildWidth)},
+		{"PatchWidth", int64(cfg.PatchWidth)}, {"NightlyKeepDays", int64(cfg.NightlyKeepDays)}, {"PushRetries", int64(cfg.PushRetries)},
+		{"PushBackoff", int64(cfg.PushBackoff)}, {"Timeouts.Git", int64(cfg.Timeouts.Git)},
+		{"Timeouts.API", int64(cfg.Timeouts.API)}, {"Timeouts.Webhook", int64(cfg.Timeouts.Webhook)},
+	} {
+		if n.v < 0 {
+			add("%s is negative", n.name)
+		}
+	}
+
+	if cfg.Final && !cfg.Candidates {
+		add("Final approves the first final patch after release candidates: it needs Candidates")
+	}
+	switch {
+	case cfg.Bump != "" && !cfg.SemVer:
+		add("Bump %q applies to SemVer versions only: set SemVer or drop it", cfg.Bump)
+	case cfg.Bump != "" && cfg.Bump != BumpMajor && cfg.Bump != BumpMinor && cfg.Bump != BumpPatch:
+		add("Bump %q is not major, minor or patch", cfg.Bump)
+	}
+	switch {
+	case cfg.Nightly && cfg.SemVer:
+		add("Nightly is a CalVer scheme: SemVer has no date to number nightlies by")
+	case cfg.NightlyKeepDays > 0 && !cfg.Nightly:
+		add("NightlyKeepDays prunes nightlies: it needs Nightly")
+	}
+	if cfg.LegacyTags && !cfg.SemVer {
+		add("LegacyTags imports 'vX.Y.Z' SemVer tags: it needs SemVer (CalVer ignores them anyway)")
+	}
+	if cfg.SemVer {
+		for _, o := range []struct {
+			name string
+			set  bool
+		}{{"DailySequence", cfg.DailySequence}, {"Candidates", cfg.Candidates}, {"Epoch", cfg.Epoch > 0}} {
+			if o.set {
+				add("%s is a CalVer option that SemVer ignores", o.name)
+			}
+		}
+	}
+	switch cfg.UnknownBranches {
+	case UnknownSnapshot:
+		if len(cfg.FeatureBranches) > 0 {
+			add("FeatureBranches lists the feature branches strict mode allows: it needs UnknownBranches (error or skip)")
+		}
+	case UnknownError, UnknownSkip:
+	default:
+		add("UnknownBranches %q is not error or skip", cfg.UnknownBranches)
+	}
+	for _, p := range cfg.FeatureBranches {
+		if _, err := path.Match(p, ""); err != nil {
+			add("FeatureBranches pattern %q is not a valid glob: %v", p, err)
+		}
+	}
+	for _, p := range cfg.Policies {
+		if err := checkPolicy(p); err != nil {
+			add("Policies: %v", err)
+		}
+	}
+	if cfg.TicketPattern != "" {
+		if _, err := Tickets(cfg.TicketPattern, ""); err != nil {
+			ps = append(ps, err)
+		}
+	}
+	if err := checkMilestone(cfg.Milestone); err != nil {
+		ps = append(ps, err)
+	}
+	if cfg.CloseMilestone && cfg.Milestone == "" {
+		add("CloseMilestone closes the milestone linked to the release: it needs Milestone")
+	}
+	if cfg.ChangelogMR && cfg.Changelog == "" {
+		add("ChangelogMR opens a merge request for the Changelog file: it needs Changelog")
+	}
+	if cfg.BestEffortTags && (cfg.Monotonic || cfg.NoCollisions) {
+		add("BestEffortTags treats a failed tag lookup as no tags, which would pass Monotonic and NoCollisions unchecked")
+	}
+
+	if len(ps) > 0 {
+		return &ConfigError{Problems: ps}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{DefaultBranch: "main", Prefix: "api", Candidates: true, Final: true}).Validate(); err != nil {
+		t.Fatalf("valid config: got %v", err)
+	}
+
+	cfg := Config{
+		Prefix:         "my.app",
+		Timezone:       "Mars/Olympus",
+		Final:          true,
+		Bump:           BumpMinor,
+		BestEffortTags: true,
+		NoCollisions:   true,
+		PushRetries:    -1,
+	}
+	err := cfg.Validate()
+	var ce *ConfigError
+	if !errors.As(err, &ce) || !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want *ConfigError", err)
+	}
+	for _, want := range []string{"DefaultBranch is empty", `Prefix "my.app"`, "Timezone", "needs Candidates",
+		"SemVer versions only", "BestEffortTags", "PushRetries is negative"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("%v: missing %q", err, want)
+		}
+	}
+	if len(ce.Problems) != 7 {
+		t.Fatalf("got %d problems want 7: %v", len(ce.Problems), err)
+	}
+	var ae *AffixError
+	if !errors.As(err, &ae) || ae.Field != "Prefix" {
+		t.Fatalf("got %v want the *AffixError of Prefix", err)
+	}
+
+	// Version refuses to compute with a broken config
+	if _, err := ctx("main", Config{DefaultBranch: "release/v1"}, nil).Version(); !errors.As(err, &ce) {
+		t.Fatalf("got %v want *ConfigError", err)
+	}
+}
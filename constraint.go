@@ -0,0 +1,124 @@
+package versioner
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Constraint selects versions, e.g. ">=20250401.0.0", "20250428.*", "202504*" (anything from April) or
+// ">=20250401.0, <20250501.0". Comma- or space-separated terms must all hold; "||" separates alternatives.
+// Wildcards match the numeric core (date.build[.patch]); prefixes and commit metadata are ignored throughout.
+type Constraint struct {
+	any [][]term // OR of ANDs
+	raw string
+}
+
+// ParseConstraint parses a constraint expression.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: s}
+	for _, alt := range strings.Split(s, "||") {
+		var all []term
+		for _, f := range strings.FieldsFunc(alt, func(r rune) bool { return r == ',' || r == ' ' }) {
+			t, err := parseTerm(f)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("constraint %q: %w", s, err)
+			}
+			all = append(all, t)
+		}
+		if len(all) == 0 {
+			return Constraint{}, fmt.Errorf("constraint %q: empty alternative", s)
+		}
+		c.any = append(c.any, all)
+	}
+	return c, nil
+}
+
+// Match reports whether v satisfies the constraint.
+func (c Constraint) Match(v Version) bool {
+	for _, all := range c.any {
+		ok := true
+		for _, t := range all {
+			if !t.match(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) String() string { return c.raw }
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+type term struct {
+	op      string  // "=", "!=", "<", "<=", ">", ">=" or "glob"
+	operand Version // for comparisons
+	pattern string  // for globs
+}
+
+var (
+	termRE     = regexp.MustCompile(`^(=|==|!=|<=|>=|<|>)?(.+)$`)
+	bareDateRE = regexp.MustCompile(`^\d{8}$`)
+)
+
+func parseTerm(s string) (term, error) {
+	m := termRE.FindStringSubmatch(s)
+	op, operand := m[1], m[2]
+	if strings.ContainsAny(operand, "*?[") {
+		if op != "" && op != "=" && op != "==" {
+			return term{}, fmt.Errorf("wildcard %q cannot be combined with %s", operand, op)
+		}
+		if _, err := path.Match(operand, ""); err != nil {
+			return term{}, fmt.Errorf("bad wildcard %q: %w", operand, err)
+		}
+		return term{op: "glob", pattern: operand}, nil
+	}
+
+	if bareDateRE.MatchString(operand) {
+		operand += ".0" // a bare date means its first build
+	}
+	v, err := Parse(operand)
+	if err != nil {
+		return term{}, err
+	}
+	switch op {
+	case "", "==":
+		op = "="
+	}
+	return term{op: op, operand: v}, nil
+}
+
+func (t term) match(v Version) bool {
+	if t.op == "glob" {
+		ok, _ := path.Match(t.pattern, core(v))
+		return ok
+	}
+	c := Compare(v, t.operand)
+	switch t.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+// core is the numeric part of v: date.build[.patch].
+func core(v Version) string {
+	return Version{Date: v.Date, Build: v.Build, Patch: v.Patch}.String()
+}
@@ -0,0 +1,48 @@
+package versioner
+
+import "testing"
+
+func TestConstraintMatch(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=20250401.0.0", "20250428.100.2", true},
+		{">=20250401.0.0", "20250331.900", false},
+		{"20250428.*", "20250428.100.2", true},
+		{"20250428.*", "cli-20250428.321-SNAPSHOT", true},
+		{"20250428.*", "20250429.1", false},
+		{"202504*", "20250415.7", true},
+		{"202504*", "20250501.7", false},
+		{">=20250401, <20250501", "20250430.999.9", true},
+		{">=20250401, <20250501", "20250501.1", false},
+		{"20250428.100.*", "20250428.100.4", true},
+		{"20250428.100.*", "20250428.101", false},
+		{"<20250101 || >=20250428", "20241231.5", true},
+		{"<20250101 || >=20250428", "20250301.5", false},
+		{"!=20250428.100.1", "20250428.100.1", false},
+		{"20250428.100.1", "20250428.100.1+0a1b2c3d", true},
+	}
+	for _, c := range cases {
+		con, err := ParseConstraint(c.constraint)
+		if err != nil {
+			t.Fatalf("%s: %v", c.constraint, err)
+		}
+		v, err := Parse(c.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := con.Match(v); got != c.want {
+			t.Fatalf("%q.Match(%s) = %v want %v", c.constraint, c.version, got, c.want)
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", ">=v1.2.3", ">2025*", "20250428.[", "a ||"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package versioner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Cosign signs release artifacts with the sigstore cosign CLI. Without Key, signing is keyless: cosign exchanges the
+// CI OIDC token (IDToken, e.g. a GitLab id_tokens entry with aud "sigstore") for a short-lived Fulcio certificate
+// and logs the signature in Rekor.
+type Cosign struct {
+	Binary  string // defaults to "cosign" on PATH
+	IDToken string // $SIGSTORE_ID_TOKEN; passed in cosign's environment, never as an argument
+	Key     string // optional key reference (file, KMS URI) for key-based signing
+}
+
+// SignImage signs the container image at digest ("sha256:…") in repository image, attaching the version as the
+// "version" annotation so verifiers can require it: cosign verify --annotations version=<v>.
+func (c BuildContext) SignImage(cs Cosign, image, digest, version string) error {
+	if !strings.HasPrefix(digest, "sha256:") {
+		return fmt.Errorf("%w: image digest %q (want sha256:…); sign digests, never tags", ErrInvalidConfig, digest)
+	}
+	ref := strings.TrimSuffix(image, "@") + "@" + digest
+	args := append([]string{"sign", "--yes", "-a", "version=" + version}, cs.keyArgs()...)
+	return c.effect("sign image "+ref+" for "+version, func() error {
+		_, err := cs.run(append(args, ref)...)
+		return err
+	})
+}
+
+// SignTag signs the annotated tag object of version (its manifest included) with cosign sign-blob and writes the
+// sigstore bundle to bundle, ready to be attached to the release or stored next to the artifacts.
+func (c BuildContext) SignTag(cs Cosign, version, bundle string) error {
+	if err := CheckRefName(version); err != nil {
+		return err
+	}
+	obj, err := c.git("cat-file", "tag", "refs/tags/"+version)
+	if err != nil {
+		return fmt.Errorf("%w: %s is not an annotated tag: %w", ErrNoMatchingTags, version, err)
+	}
+	return c.effect("sign tag "+version+" into "+bundle, func() error {
+		tmp, err := os.CreateTemp("", "versioner-tag-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		if _, err := tmp.WriteString(obj); err != nil {
+			tmp.Close()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		args := append([]string{"sign-blob", "--yes", "--bundle", bundle}, cs.keyArgs()...)
+		_, err = cs.run(append(args, tmp.Name())...)
+		return err
+	})
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (cs Cosign) keyArgs() []string {
+	if cs.Key == "" {
+		return nil
+	}
+	return []string{"--key", cs.Key}
+}
+
+func (cs Cosign) run(args ...string) (string, error) {
+	bin := cs.Binary
+	if bin == "" {
+		bin = "cosign"
+	}
+	var env []string
+	if cs.IDToken != "" {
+		env = []string{"SIGSTORE_ID_TOKEN=" + cs.IDToken}
+	}
+	// keyless signing may wait on the OIDC flow, so cosign runs without a timeout
+	stdout, stderr, err := run(context.Background(), bin, args, env)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", filepath.Base(bin), args[0], err, strings.TrimSpace(stderr))
+	}
+	return stdout, nil
+}
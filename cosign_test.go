@@ -0,0 +1,60 @@
+package versioner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeCosign installs a script recording its arguments and SIGSTORE_ID_TOKEN, one line per call.
+func fakeCosign(t *testing.T) (bin, log string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script stand-in")
+	}
+	dir := t.TempDir()
+	bin, log = filepath.Join(dir, "cosign"), filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho \"$SIGSTORE_ID_TOKEN $*\" >> " + log + "\n" +
+		"case \"$1\" in sign-blob) grep -q '^object ' \"$5\" || exit 3; touch \"$4\";; esac\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, log
+}
+
+func TestCosign(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "-a", "-m", "Release", "20250428.100")
+	bin, log := fakeCosign(t)
+	cs := Cosign{Binary: bin, IDToken: "oidc"}
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+
+	if err := c.SignImage(cs, "reg.example.com/app", "sha256:abc", "20250428.100"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SignImage(cs, "reg.example.com/app", "latest", "20250428.100"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("tag instead of digest: got %v", err)
+	}
+	bundle := filepath.Join(t.TempDir(), "tag.sigstore.json")
+	if err := c.SignTag(cs, "20250428.100", bundle); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(bundle); err != nil {
+		t.Fatal("bundle not written")
+	}
+
+	b, _ := os.ReadFile(log)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 || lines[0] != "oidc sign --yes -a version=20250428.100 reg.example.com/app@sha256:abc" ||
+		!strings.HasPrefix(lines[1], "oidc sign-blob --yes --bundle "+bundle) {
+		t.Fatalf("unexpected calls %q", lines)
+	}
+
+	mustGit(t, "", "tag", "20250428.101")
+	if err := c.SignTag(cs, "20250428.101", bundle); !errors.Is(err, ErrNoMatchingTags) {
+		t.Fatalf("lightweight tag: got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CutRelease creates release/v<YYYYMMDD.B> from the latest final default-branch tag and pushes it to origin, so the
+// branch name is always derived from the tag the way relBranchRE expects. It returns the branch name.
+func (c BuildContext) CutRelease() (string, error) {
+	ts, err := c.tags()
+	if err != nil {
+		return "", err
+	}
+	latest, ok := latestDefault(ts, c.Config.Prefix)
+	if !ok {
+		return "", fmt.Errorf("%w: no default-branch build to cut a release from", ErrNoMatchingTags)
+	}
+
+	branch := "release/v" + latest.Base()
+	if !relBranchRE.MatchString(branch) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidReleaseBranch, branch) // unreachable unless the scheme drifts
+	}
+	c.debug("cutting release", "tag", latest.String(), "branch", branch)
+
+	ref := "refs/heads/" + branch
+	steps := [][]string{
+		{"branch", branch, latest.String() + "^{commit}"},
+		{"push", "origin", ref + ":" + ref},
+	}
+	for _, args := range steps {
+		err := c.effect("run git "+strings.Join(args, " "), func() error {
+			_, err := c.git(args...)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return branch, nil
+}
@@ -0,0 +1,36 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCutRelease(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, "", "tag", "20250427.300")
+	want := mustGit(t, "", "rev-parse", "HEAD")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "later")
+	mustGit(t, "", "tag", "20250428.321-SNAPSHOT")
+
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.LookupTags = GitTags
+	br, err := c.CutRelease()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if br != "release/v20250427.300" {
+		t.Fatalf("got %s want release/v20250427.300", br)
+	}
+	if got := mustGit(t, "", "ls-remote", origin, "refs/heads/"+br); len(got) < 40 || got[:40] != want {
+		t.Fatalf("remote branch %q does not point at %s", got, want)
+	}
+	if _, err := ctx(br, Config{DefaultBranch: "main"}, nil).Version(); err != nil {
+		t.Fatalf("cut branch is not a valid release branch: %v", err)
+	}
+}
+
+func TestCutReleaseWithoutTags(t *testing.T) {
+	if _, err := ctx("main", Config{DefaultBranch: "main"}, nil).CutRelease(); !errors.Is(err, ErrNoMatchingTags) {
+		t.Fatalf("got %v want ErrNoMatchingTags", err)
+	}
+}
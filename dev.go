@@ -0,0 +1,151 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// DevVersion returns a local developer version of the form [<Prefix>-]dev-<user>-<machine>-<n>. It never parses as
+// a CI version, so it cannot clash with (or be mistaken for) a published one, and n is allocated atomically under
+// stateDir (defaulting to the user cache dir) so parallel local builds never share a number.
+func DevVersion(prefix, stateDir string) (string, error) {
+	if stateDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		stateDir = filepath.Join(dir, "versioner", "dev")
+	}
+	n, err := nextDevCounter(stateDir)
+	if err != nil {
+		return "", err
+	}
+	v := fmt.Sprintf("dev-%s-%s-%d", slug(devUser()), slug(devHost()), n)
+	return addPrefix(v, prefix), nil
+}
+
+// LocalVersion derives a version for artifacts built outside CI from the repository itself:
+//
+//	<nearest final tag>-local.<branch>.<commits since>[-dirty]+<shortsha>
+//
+// The "local" suffix makes it a snapshot that can never equal a CI version, the branch and distance keep builds of
+// different work apart, and "-dirty" marks a worktree with uncommitted changes. Without a reachable tag the base is
+// today's date with build 0. c.Branch defaults to the checked-out branch.
+func (c BuildContext) LocalVersion() (string, error) {
+	c = c.pinTime()
+	ts, err := DescribeTags(c.Config.Prefix)()
+	if err != nil {
+		return "", err
+	}
+	var base, rng string
+	if len(ts) > 0 {
+		base, rng = ts[0], ts[0]+"..HEAD"
+	} else {
+		day, err := c.day()
+		if err != nil {
+			return "", err
+		}
+		base, rng = addPrefix(day+".0", c.Config.Prefix), "HEAD"
+	}
+
+	distance, err := c.git("rev-list", "--count", "--end-of-options", rng)
+	if err != nil {
+		return "", err
+	}
+	sha, err := c.git("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	status, err := c.git("status", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+
+	br := c.Branch
+	if br == "" {
+		out, _ := c.git("rev-parse", "--abbrev-ref", "HEAD")
+		br = strings.TrimSpace(out)
+	}
+	v := base + "-local"
+	if s := branchSlug(br); s != "" && br != "HEAD" {
+		v += "." + s
+	}
+	v += "." + strings.TrimSpace(distance)
+	if strings.TrimSpace(status) != "" {
+		v += "-dirty"
+	}
+	return v + "+" + shortSHA(strings.TrimSpace(sha)), nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+const devWindow = 64
+
+// nextDevCounter claims max+1 by exclusively creating a marker file; a concurrent claim of the same number fails
+// with ErrExist and simply moves on to the next one.
+func nextDevCounter(dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	max := 0
+	for _, e := range ents {
+		if n, err := strconv.Atoi(e.Name()); err == nil && n > max {
+			max = n
+		}
+	}
+	for n := max + 1; ; n++ {
+		f, err := os.OpenFile(filepath.Join(dir, strconv.Itoa(n)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		f.Close()
+		// Only the high-water mark matters, but keep a window of recent markers so a slow concurrent claimer that
+		// listed the directory earlier cannot re-create a number that was already handed out.
+		if n > devWindow {
+			os.Remove(filepath.Join(dir, strconv.Itoa(n-devWindow)))
+		}
+		return n, nil
+	}
+}
+
+func devUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		_, name, _ := strings.Cut(u.Username, `\`) // DOMAIN\user on Windows
+		if name == "" {
+			name = u.Username
+		}
+		return name
+	}
+	return os.Getenv("USER")
+}
+
+func devHost() string {
+	h, _ := os.Hostname()
+	h, _, _ = strings.Cut(h, ".")
+	return h
+}
+
+var slugRE = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(s string) string {
+	s = strings.Trim(slugRE.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package versioner
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDevVersionIsNeverACIVersion(t *testing.T) {
+	v, err := DevVersion("cli", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(v, "cli-dev-") || !strings.HasSuffix(v, "-1") {
+		t.Fatalf("unexpected dev version %s", v)
+	}
+	if _, err := Parse(v); err == nil {
+		t.Fatalf("dev version %s must not parse as a CI version", v)
+	}
+}
+
+func TestDevCounterParallel(t *testing.T) {
+	dir := t.TempDir()
+	var (
+		mu   sync.Mutex
+		seen = map[int]bool{}
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := nextDevCounter(dir)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[n] {
+				t.Errorf("counter %d handed out twice", n)
+			}
+			seen[n] = true
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSlug(t *testing.T) {
+	if got := slug("Jane.Doe@Corp"); got != "jane-doe-corp" {
+		t.Fatalf("got %s want jane-doe-corp", got)
+	}
+	if got := slug("..."); got != "unknown" {
+		t.Fatalf("got %s want unknown", got)
+	}
+}
+
+func TestLocalVersion(t *testing.T) {
+	gitRepo(t)
+	c := BuildContext{Time: now}
+	sha := mustGit(t, "", "rev-parse", "--short=8", "HEAD")
+	if got, err := c.LocalVersion(); err != nil || got != "20250428.0-local.main.1+"+sha {
+		t.Fatalf("untagged: got %s, %v", got, err)
+	}
+
+	mustGit(t, "", "tag", "20250427.90")
+	mustGit(t, "", "checkout", "-q", "-b", "feat/Login")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "wip")
+	os.WriteFile("scratch.txt", []byte("x"), 0o644)
+	sha = mustGit(t, "", "rev-parse", "--short=8", "HEAD")
+
+	got, err := c.LocalVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "20250427.90-local.feat-login.1-dirty+" + sha; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	if !IsSnapshot(got) {
+		t.Fatalf("%s should be a valid snapshot version", got)
+	}
+}
@@ -0,0 +1,83 @@
+package versioner
+
+import "strings"
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Direction classifies a move from one version to another.
+type Direction int
+
+const (
+	DirectionSame     Direction = iota // identical strings
+	DirectionUpgrade                   // the new version sorts after the old one
+	DirectionRollback                  // the new version sorts before the old one
+	DirectionRebuild                   // same epoch, date, build and patch; only suffix, prefix, commit or padding differ
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionUpgrade:
+		return "upgrade"
+	case DirectionRollback:
+		return "rollback"
+	case DirectionRebuild:
+		return "rebuild"
+	default:
+		return "same"
+	}
+}
+
+// VersionDiff reports which components differ between two versions and what the move from the first to the second
+// amounts to.
+type VersionDiff struct {
+	Direction Direction
+
+	Prefix, Epoch, Date, Build, Patch, Suffix, Commit bool // component differs
+}
+
+// Changed lists the differing components in scheme order ("date", "build" …).
+func (d VersionDiff) Changed() []string {
+	var out []string
+	for _, c := range []struct {
+		name string
+		on   bool
+	}{{"prefix", d.Prefix}, {"epoch", d.Epoch}, {"date", d.Date}, {"build", d.Build}, {"patch", d.Patch},
+		{"suffix", d.Suffix}, {"commit", d.Commit}} {
+		if c.on {
+			out = append(out, c.name)
+		}
+	}
+	return out
+}
+
+func (d VersionDiff) String() string {
+	if ch := d.Changed(); len(ch) > 0 {
+		return d.Direction.String() + " " + strings.Join(ch, ",")
+	}
+	return d.Direction.String()
+}
+
+// Diff compares the running version a with the incoming version b, so deploy tooling can tell an upgrade from a
+// rollback or a rebuild of the same release from the strings alone.
+func Diff(a, b Version) VersionDiff {
+	d := VersionDiff{
+		Prefix: a.Prefix != b.Prefix,
+		Epoch:  a.Epoch != b.Epoch,
+		Date:   a.Date != b.Date,
+		Build:  a.Build != b.Build,
+		Patch:  a.Patch != b.Patch,
+		Suffix: a.Suffix != b.Suffix,
+		Commit: a.Commit != b.Commit,
+	}
+	switch {
+	case a == b:
+		d.Direction = DirectionSame
+	case !d.Epoch && !d.Date && !d.Build && !d.Patch:
+		d.Direction = DirectionRebuild
+	case Compare(a, b) < 0:
+		d.Direction = DirectionUpgrade
+	default:
+		d.Direction = DirectionRollback
+	}
+	return d
+}
@@ -0,0 +1,20 @@
+package versioner
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	for _, tc := range []struct{ a, b, want string }{
+		{"20250428.100", "20250428.100", "same"},
+		{"20250428.100", "20250429.120", "upgrade date,build"},
+		{"20250428.100.2", "20250428.100.1", "rollback patch"},
+		{"20250428.100+0a1b2c3d", "20250428.100+deadbeef", "rebuild commit"},
+		{"20250428.100-SNAPSHOT", "20250428.100", "rebuild suffix"},
+		{"20250428.100", "1!20240101.1", "upgrade epoch,date,build"},
+	} {
+		a, _ := Parse(tc.a)
+		b, _ := Parse(tc.b)
+		if got := Diff(a, b).String(); got != tc.want {
+			t.Fatalf("Diff(%s, %s) = %s want %s", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package versioner
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// effect performs a side effect, or under Config.DryRun only describes it on BuildContext.DryRunOut.
+func (c BuildContext) effect(desc string, do func() error) error {
+	if !c.Config.DryRun {
+		return do()
+	}
+	var w io.Writer = os.Stderr
+	if c.DryRunOut != nil {
+		w = c.DryRunOut
+	}
+	_, err := fmt.Fprintf(w, "dry-run: would %s\n", desc)
+	return err
+}
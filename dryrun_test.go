@@ -0,0 +1,35 @@
+package versioner
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRunTagAndPush(t *testing.T) {
+	gitRepo(t)
+	var out bytes.Buffer
+	ledger := FileLedger{Path: filepath.Join(t.TempDir(), "ledger.jsonl")}
+	c := ctx("main", Config{DefaultBranch: "main", DryRun: true}, nil)
+	c.DryRunOut, c.Ledger = &out, ledger
+
+	m, err := c.TagAndPush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "20250428.321" {
+		t.Fatalf("got %s want 20250428.321", m.Version)
+	}
+	for _, want := range []string{"would create tag 20250428.321", "would push tag 20250428.321", "would record"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("plan %q lacks %q", out.String(), want)
+		}
+	}
+	if tags, _ := GitTags(); len(tags) != 0 {
+		t.Fatalf("dry run created tags %v", tags)
+	}
+	if ms, _ := ledger.Manifests(); len(ms) != 0 {
+		t.Fatalf("dry run wrote the ledger: %v", ms)
+	}
+}
@@ -0,0 +1,142 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors; returned errors wrap them with %w, so test with errors.Is.
+var (
+	ErrInvalidReleaseBranch = errors.New("invalid release branch")
+	ErrInvalidVersion       = errors.New("invalid version")
+	ErrInvalidConfig        = errors.New("invalid config")
+	ErrTagLookupFailed      = errors.New("tag lookup failed")
+	ErrNoMatchingTags       = errors.New("no matching tags")
+	ErrSubmodulesDiffer     = errors.New("submodule pins differ")
+
+	// ErrVersionExists is returned when Config.NoCollisions is set and the computed tag is already taken,
+	// typically because a pipeline was re-run.
+	ErrVersionExists = errors.New("version already exists")
+
+	// ErrStaleRerun is returned with Config.Reruns set when a pipeline for an old release-branch commit is re-run
+	// after newer patches were cut from later commits, and no version was ever assigned to the old commit.
+	ErrStaleRerun = errors.New("stale release pipeline re-run")
+
+	// ErrMissingBaseTag is returned with Config.RequireBaseTag when a release branch names a base build that was
+	// never tagged, usually a typo in an LTS backport branch.
+	ErrMissingBaseTag = errors.New("release base tag missing")
+
+	// ErrUnsafeRef is returned for branch, tag or version names that are not valid git ref names or could be
+	// mistaken for command-line options (see CheckRefName).
+	ErrUnsafeRef = errors.New("unsafe ref name")
+
+	// ErrUnknownBranch is returned with Config.UnknownBranches set to UnknownError for branches outside the default
+	// branch, release branches and Config.FeatureBranches.
+	ErrUnknownBranch = errors.New("unknown branch")
+
+	// ErrNoVersion is returned with Config.UnknownBranches set to UnknownSkip: the branch gets no version by design,
+	// so the CLI exits 0 without printing one.
+	ErrNoVersion = errors.New("no version for this branch")
+
+	// ErrSandboxed is returned for external commands a Sandbox runner does not allow.
+	ErrSandboxed = errors.New("command not allowed by sandbox")
+)
+
+// Exit codes of the versioner CLI by error class, so pipeline rules can retry transient git failures and stop on
+// configuration mistakes. ExitCode maps an error to its code.
+const (
+	ExitFailure    = 1 // anything not listed below
+	ExitUsage      = 2 // malformed flags or arguments, as with the flag package
+	ExitConfig     = 3 // ErrInvalidConfig: fix the flags or environment
+	ExitBranch     = 4 // ErrInvalidReleaseBranch, ErrMissingBaseTag, ErrUnsafeRef, ErrUnknownBranch: fix the branch name
+	ExitVersion    = 5 // ErrInvalidVersion: a version argument or tag does not parse
+	ExitNotFound   = 6 // ErrNoMatchingTags
+	ExitCollision  = 7 // ErrVersionExists, ErrStaleRerun, ErrPreconditionFailed: another pipeline got there first
+	ExitGitFailure = 8 // ErrTagLookupFailed or a failed git call: usually transient, worth a retry
+)
+
+// ExitCode returns the CLI exit code for err: 0 for nil and ErrNoVersion, else the code of the first matching class
+// above.
+func ExitCode(err error) int {
+	var ge *GitError
+	switch {
+	case err == nil, errors.Is(err, ErrNoVersion):
+		return 0
+	case errors.Is(err, ErrInvalidConfig):
+		return ExitConfig
+	case errors.Is(err, ErrInvalidReleaseBranch), errors.Is(err, ErrMissingBaseTag), errors.Is(err, ErrUnsafeRef),
+		errors.Is(err, ErrUnknownBranch):
+		return ExitBranch
+	case errors.Is(err, ErrInvalidVersion):
+		return ExitVersion
+	case errors.Is(err, ErrNoMatchingTags):
+		return ExitNotFound
+	case errors.Is(err, ErrVersionExists), errors.Is(err, ErrStaleRerun), errors.Is(err, ErrPreconditionFailed):
+		return ExitCollision
+	case errors.Is(err, ErrTagLookupFailed), errors.As(err, &ge):
+		return ExitGitFailure
+	default:
+		return ExitFailure
+	}
+}
+
+// GitError reports a failed git invocation together with what git printed on stderr.
+type GitError struct {
+	Args   []string
+	Output string
+	Err    error
+}
+
+func (e *GitError) Error() string {
+	msg := fmt.Sprintf("git %s: %v", strings.Join(e.Args, " "), e.Err)
+	if out := strings.TrimSpace(e.Output); out != "" {
+		msg += ": " + out
+	}
+	return msg
+}
+
+func (e *GitError) Unwrap() error { return e.Err }
+
+// AffixError reports a Config.Prefix, FeatureSuffix or suffix label that would produce versions registries reject. It
+// wraps ErrInvalidConfig.
+type AffixError struct {
+	Field  string // "Prefix", "FeatureSuffix", "SuffixLabels" or "Channels"
+	Value  string
+	Reason string
+}
+
+func (e *AffixError) Error() string {
+	return fmt.Sprintf("%v: %s %q %s", ErrInvalidConfig, e.Field, e.Value, e.Reason)
+}
+
+func (e *AffixError) Unwrap() error { return ErrInvalidConfig }
+
+// PolicyError reports a version a naming Policy vetoed. It wraps ErrInvalidVersion.
+type PolicyError struct {
+	Policy  string // "docker", "k8s-label", "npm", "banned-words" or a custom policy's name
+	Version string
+	Reason  string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%v: %s rejected by the %s policy: %s", ErrInvalidVersion, e.Version, e.Policy, e.Reason)
+}
+
+func (e *PolicyError) Unwrap() error { return ErrInvalidVersion }
+
+// ConfigError lists every problem Config.Validate found. It wraps ErrInvalidConfig and each problem, so errors.As
+// still finds an *AffixError among them.
+type ConfigError struct {
+	Problems []error
+}
+
+func (e *ConfigError) Error() string {
+	ps := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		ps[i] = strings.TrimPrefix(p.Error(), ErrInvalidConfig.Error()+": ")
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidConfig, strings.Join(ps, "; "))
+}
+
+func (e *ConfigError) Unwrap() []error { return append([]error{ErrInvalidConfig}, e.Problems...) }
@@ -0,0 +1,67 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	if _, err := ctx("release/2025", Config{DefaultBranch: "main"}, nil).Version(); !errors.Is(err, ErrInvalidReleaseBranch) {
+		t.Fatalf("got %v want ErrInvalidReleaseBranch", err)
+	}
+	if _, err := Parse("v1.2.3"); !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("got %v want ErrInvalidVersion", err)
+	}
+	if _, err := ctx("main", Config{DefaultBranch: "main", Timezone: "Nowhere/Else"}, nil).Version(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want ErrInvalidConfig", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	cases := map[error]int{
+		nil:                          0,
+		errors.New("boom"):           ExitFailure,
+		&AffixError{Field: "Prefix"}: ExitConfig,
+		fmt.Errorf("%w: release/x", ErrInvalidReleaseBranch):             ExitBranch,
+		fmt.Errorf("%w: %w", ErrTagLookupFailed, &GitError{Err: io.EOF}): ExitGitFailure,
+		&GitError{Err: io.EOF}:                            ExitGitFailure,
+		fmt.Errorf("push: %w", ErrVersionExists):          ExitCollision,
+		fmt.Errorf("%w: 20250428.999", ErrNoMatchingTags): ExitNotFound,
+	}
+	for err, want := range cases {
+		if got := ExitCode(err); got != want {
+			t.Fatalf("%v: got %d want %d", err, got, want)
+		}
+	}
+}
+
+func TestReadTagManifestMissingTag(t *testing.T) {
+	gitRepo(t)
+	if _, err := ReadTagManifest("20250428.999"); !errors.Is(err, ErrNoMatchingTags) {
+		t.Fatalf("got %v want ErrNoMatchingTags", err)
+	}
+}
+
+func TestGitErrorCarriesOutput(t *testing.T) {
+	gitRepo(t)
+	_, err := git("rev-parse", "--verify", "no-such-ref")
+	var ge *GitError
+	if !errors.As(err, &ge) || ge.Output == "" {
+		t.Fatalf("got %#v want *GitError with output", err)
+	}
+}
+
+func TestTagLookupFailureIsFatal(t *testing.T) {
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, nil)
+	c.LookupTags = func() ([]string, error) { return nil, errors.New("fatal: not a git repository") }
+	if _, err := c.Version(); !errors.Is(err, ErrTagLookupFailed) {
+		t.Fatalf("got %v want ErrTagLookupFailed", err)
+	}
+
+	c.Config.BestEffortTags = true
+	if got, err := c.Version(); err != nil || got != "20250428.100.1" {
+		t.Fatalf("best effort: got %s, %v want 20250428.100.1", got, err)
+	}
+}
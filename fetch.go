@@ -0,0 +1,55 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// FetchTags wraps a tag source for CI clones made with GIT_DEPTH and no tags, where an empty tag list would make
+// every release build look like the first one. Before the first lookup it checks whether the clone is shallow or
+// has no tags at all and, if so, runs `git fetch --tags --force <remote>` (origin when empty). A failed fetch is
+// reported as ErrTagLookupFailed rather than silently computing from missing tags.
+func FetchTags(src func() ([]string, error), remote string) func() ([]string, error) {
+	return BuildContext{}.FetchTags(src, remote)
+}
+
+// FetchTags is FetchTags with the checks and the fetch bounded by Config.Timeouts.Git.
+func (c BuildContext) FetchTags(src func() ([]string, error), remote string) func() ([]string, error) {
+	if remote == "" {
+		remote = "origin"
+	}
+	var once sync.Once
+	var ferr error
+	return func() ([]string, error) {
+		once.Do(func() { ferr = c.ensureTags(remote) })
+		if ferr != nil {
+			return nil, ferr
+		}
+		return src()
+	}
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (c BuildContext) ensureTags(remote string) error {
+	shallow, err := c.git("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	if strings.TrimSpace(shallow) != "true" {
+		any, err := c.git("for-each-ref", "--count=1", "refs/tags")
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+		}
+		if strings.TrimSpace(any) != "" {
+			return nil
+		}
+	}
+	if _, err := c.git("fetch", "--tags", "--force", remote); err != nil {
+		return fmt.Errorf("%w: fetching tags into shallow or tagless clone: %w", ErrTagLookupFailed, err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package versioner
+
+import "testing"
+
+func TestFetchTagsIntoShallowClone(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, "", "tag", "20250428.100")
+	mustGit(t, "", "tag", "20250428.100.1")
+	mustGit(t, "", "push", "-q", "origin", "--tags")
+
+	dir := t.TempDir()
+	mustGit(t, "", "clone", "-q", "--depth=1", "--no-tags", "file://"+origin, dir)
+	t.Chdir(dir)
+
+	if ts, _ := GitTags(); len(ts) != 0 {
+		t.Fatalf("clone unexpectedly has tags %v", ts)
+	}
+	ts, err := FetchTags(GitTags, "")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 2 {
+		t.Fatalf("got %v want both tags", ts)
+	}
+}
@@ -0,0 +1,275 @@
+package versioner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// FileTarget is one place in a file that carries the version.
+type FileTarget struct {
+	Path string
+	Kind string // plain, json, regex; empty infers from the file name (VERSION, package.json, pyproject.toml, Chart.yaml)
+	Expr string // json: dotted key path such as "version" or "$.image.tag"; regex: pattern whose first group is replaced
+}
+
+// ParseFileTarget reads the CLI form "path[:kind[:expr]]", e.g. "package.json", "app.json:json:$.meta.version" or
+// `main.go:regex:version = "([^"]*)"`.
+func ParseFileTarget(s string) (FileTarget, error) {
+	path, rest, _ := strings.Cut(s, ":")
+	kind, expr, _ := strings.Cut(rest, ":")
+	t := FileTarget{Path: path, Kind: kind, Expr: expr}
+	if _, err := t.resolve(); err != nil {
+		return FileTarget{}, err
+	}
+	return t, nil
+}
+
+// WriteFiles stamps version into every target in one transactional pass: all edits are computed first and nothing is
+// written unless every target matched. Under Config.DryRun it prints a diff of each change instead. It returns the
+// files whose content changed.
+func (c BuildContext) WriteFiles(version string, targets []FileTarget) ([]string, error) {
+	var order []string
+	before, after := map[string][]byte{}, map[string][]byte{}
+	for _, t := range targets {
+		t, err := t.resolve()
+		if err != nil {
+			return nil, err
+		}
+		if _, seen := after[t.Path]; !seen {
+			b, err := os.ReadFile(t.Path)
+			if err != nil && !(os.IsNotExist(err) && t.Kind == "plain") {
+				return nil, err
+			}
+			before[t.Path], after[t.Path] = b, b
+			order = append(order, t.Path)
+		}
+		if after[t.Path], err = t.apply(after[t.Path], version); err != nil {
+			return nil, fmt.Errorf("%s: %w", t.Path, err)
+		}
+	}
+
+	var changed []string
+	for _, p := range order {
+		if !bytes.Equal(before[p], after[p]) {
+			changed = append(changed, p)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	var diff strings.Builder
+	for _, p := range changed {
+		writeDiff(&diff, p, before[p], after[p])
+	}
+	desc := fmt.Sprintf("write %s:\n%s", strings.Join(changed, ", "), strings.TrimSuffix(diff.String(), "\n"))
+	return changed, c.effect(desc, func() error { return commitFiles(changed, before, after) })
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+const (
+	tomlVersionRE  = `(?m)^version\s*=\s*"([^"]*)"`
+	chartVersionRE = `(?m)^appVersion:\s*"?([^"\n]*?)"?\s*$` // chart "version" must stay SemVer; appVersion is free-form
+)
+
+// resolve fills in Kind and Expr for well-known file names and checks the expression.
+func (t FileTarget) resolve() (FileTarget, error) {
+	if t.Path == "" {
+		return t, fmt.Errorf("%w: file target without a path", ErrInvalidConfig)
+	}
+	if t.Kind == "" {
+		switch filepath.Base(t.Path) {
+		case "package.json":
+			t.Kind, t.Expr = "json", "version"
+		case "pyproject.toml":
+			t.Kind, t.Expr = "regex", tomlVersionRE
+		case "Chart.yaml":
+			t.Kind, t.Expr = "regex", chartVersionRE
+		default:
+			t.Kind = "plain"
+		}
+	}
+	switch t.Kind {
+	case "plain":
+	case "json":
+		if strings.TrimPrefix(strings.TrimPrefix(t.Expr, "$"), ".") == "" {
+			return t, fmt.Errorf("%w: %s: json target needs a key path", ErrInvalidConfig, t.Path)
+		}
+	case "regex":
+		re, err := regexp.Compile(t.Expr)
+		if err != nil || re.NumSubexp() < 1 {
+			return t, fmt.Errorf("%w: %s: regex target needs a valid pattern with a group", ErrInvalidConfig, t.Path)
+		}
+	default:
+		return t, fmt.Errorf("%w: %s: unknown target kind %q", ErrInvalidConfig, t.Path, t.Kind)
+	}
+	return t, nil
+}
+
+func (t FileTarget) apply(b []byte, version string) ([]byte, error) {
+	switch t.Kind {
+	case "plain":
+		return []byte(version + "\n"), nil
+	case "json":
+		path := strings.Split(strings.TrimPrefix(strings.TrimPrefix(t.Expr, "$"), "."), ".")
+		start, end, err := jsonStringSpan(b, path)
+		if err != nil {
+			return nil, err
+		}
+		q, _ := json.Marshal(version)
+		return append(append(append([]byte{}, b[:start]...), q...), b[end:]...), nil
+	default:
+		re := regexp.MustCompile(t.Expr)
+		ms := re.FindAllSubmatchIndex(b, -1)
+		if ms == nil {
+			return nil, fmt.Errorf("pattern %q matches nothing", t.Expr)
+		}
+		var out []byte
+		last := 0
+		for _, m := range ms {
+			out = append(append(out, b[last:m[2]]...), version...)
+			last = m[3]
+		}
+		return append(out, b[last:]...), nil
+	}
+}
+
+// jsonStringSpan locates the string value at path, quotes included, so it can be replaced without reformatting the
+// rest of the document. Array elements are addressed by index ("images.0.tag").
+func jsonStringSpan(doc []byte, path []string) (start, end int, err error) {
+	type frame struct {
+		obj     bool
+		wantKey bool
+		key     string
+		idx     int
+	}
+	var stack []frame
+	done := func() { // a value just finished in the innermost container
+		if n := len(stack); n > 0 {
+			if stack[n-1].obj {
+				stack[n-1].wantKey = true
+			} else {
+				stack[n-1].idx++
+			}
+		}
+	}
+	at := func() bool {
+		if len(stack) != len(path) {
+			return false
+		}
+		for i, f := range stack {
+			if f.obj && f.key != path[i] || !f.obj && strconv.Itoa(f.idx) != path[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return 0, 0, fmt.Errorf("json key %s not found", strings.Join(path, "."))
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if n := len(stack); n > 0 && stack[n-1].obj && stack[n-1].wantKey {
+			if key, ok := tok.(string); ok {
+				stack[n-1].key, stack[n-1].wantKey = key, false
+				continue
+			}
+			stack = stack[:n-1] // '}'
+			done()
+			continue
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, frame{obj: true, wantKey: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, frame{})
+			continue
+		case json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			done()
+			continue
+		}
+		if at() {
+			if _, ok := tok.(string); !ok {
+				return 0, 0, fmt.Errorf("json key %s is not a string", strings.Join(path, "."))
+			}
+			end = int(dec.InputOffset())
+			for start = end - 2; start > 0 && (doc[start] != '"' || escaped(doc, start)); start-- {
+			}
+			return start, end, nil
+		}
+		done()
+	}
+}
+
+func escaped(b []byte, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && b[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// commitFiles replaces every file via a temporary sibling and rename, restoring the originals if any step fails.
+func commitFiles(paths []string, before, after map[string][]byte) error {
+	var tmps []string
+	defer func() {
+		for _, t := range tmps {
+			os.Remove(t)
+		}
+	}()
+	for _, p := range paths {
+		mode := os.FileMode(0o644)
+		if fi, err := os.Stat(p); err == nil {
+			mode = fi.Mode().Perm()
+		}
+		tmp := p + ".versioner.tmp"
+		if err := os.WriteFile(tmp, after[p], mode); err != nil {
+			return err
+		}
+		tmps = append(tmps, tmp)
+	}
+	for i, p := range paths {
+		if err := os.Rename(tmps[i], p); err != nil {
+			var errs []error
+			for _, done := range paths[:i] {
+				errs = append(errs, os.WriteFile(done, before[done], 0o644))
+			}
+			return errors.Join(append([]error{err}, errs...)...)
+		}
+	}
+	return nil
+}
+
+// writeDiff prints the lines that differ between a and b, in unified-diff notation without hunk headers.
+func writeDiff(w *strings.Builder, path string, a, b []byte) {
+	fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n", path, path)
+	al, bl := strings.Split(string(a), "\n"), strings.Split(string(b), "\n")
+	for i := 0; i < max(len(al), len(bl)); i++ {
+		switch {
+		case i < len(al) && i < len(bl) && al[i] == bl[i]:
+		case i < len(al) && i < len(bl):
+			fmt.Fprintf(w, "-%s\n+%s\n", al[i], bl[i])
+		case i < len(al):
+			fmt.Fprintf(w, "-%s\n", al[i])
+		default:
+			fmt.Fprintf(w, "+%s\n", bl[i])
+		}
+	}
+}
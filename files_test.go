@@ -0,0 +1,83 @@
+package versioner
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		"package.json":     "{\n  \"name\": \"app\",\n  \"deps\": {\"x\": {\"version\": \"1\"}},\n  \"version\": \"0.0.0\"\n}\n",
+		"pyproject.toml":   "[project]\nname = \"app\"\nversion = \"0.0.0\"\n",
+		"chart/Chart.yaml": "apiVersion: v2\nversion: 1.2.3\nappVersion: \"old\"\n",
+		"meta.json":        `{"images":[{"tag":"a"},{"tag":"b"}]}`,
+	}
+	for p, s := range files {
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		os.WriteFile(p, []byte(s), 0o644)
+	}
+	var targets []FileTarget
+	for _, s := range []string{"VERSION", "package.json", "pyproject.toml", "chart/Chart.yaml", "meta.json:json:$.images.1.tag"} {
+		ft, err := ParseFileTarget(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, ft)
+	}
+
+	changed, err := BuildContext{}.WriteFiles("20250428.100.1", targets)
+	if err != nil || len(changed) != 5 {
+		t.Fatalf("changed %v, %v", changed, err)
+	}
+	want := map[string]string{
+		"VERSION":          "20250428.100.1\n",
+		"package.json":     "{\n  \"name\": \"app\",\n  \"deps\": {\"x\": {\"version\": \"1\"}},\n  \"version\": \"20250428.100.1\"\n}\n",
+		"pyproject.toml":   "[project]\nname = \"app\"\nversion = \"20250428.100.1\"\n",
+		"chart/Chart.yaml": "apiVersion: v2\nversion: 1.2.3\nappVersion: \"20250428.100.1\"\n",
+		"meta.json":        `{"images":[{"tag":"a"},{"tag":"20250428.100.1"}]}`,
+	}
+	for p, w := range want {
+		if b, _ := os.ReadFile(p); string(b) != w {
+			t.Fatalf("%s: got %q want %q", p, b, w)
+		}
+	}
+}
+
+func TestWriteFilesIsTransactional(t *testing.T) {
+	t.Chdir(t.TempDir())
+	os.WriteFile("VERSION", []byte("old\n"), 0o644)
+	os.WriteFile("main.go", []byte("package main\n"), 0o644)
+	targets := []FileTarget{{Path: "VERSION"}, {Path: "main.go", Kind: "regex", Expr: `version = "([^"]*)"`}}
+
+	if _, err := (BuildContext{}).WriteFiles("20250428.100", targets); err == nil {
+		t.Fatal("expected an unmatched pattern to fail")
+	}
+	if b, _ := os.ReadFile("VERSION"); string(b) != "old\n" {
+		t.Fatalf("VERSION written despite failure: %q", b)
+	}
+	if _, err := ParseFileTarget("x:yaml"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want ErrInvalidConfig", err)
+	}
+}
+
+func TestWriteFilesDryRunDiff(t *testing.T) {
+	t.Chdir(t.TempDir())
+	os.WriteFile("VERSION", []byte("20250427.90\n"), 0o644)
+	var out bytes.Buffer
+	c := BuildContext{Config: Config{DryRun: true}, DryRunOut: &out}
+	if _, err := c.WriteFiles("20250428.100", []FileTarget{{Path: "VERSION"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "-20250427.90\n+20250428.100") {
+		t.Fatalf("unexpected diff %q", out.String())
+	}
+	if b, _ := os.ReadFile("VERSION"); string(b) != "20250427.90\n" {
+		t.Fatal("dry run wrote the file")
+	}
+}
@@ -0,0 +1,125 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// DeployedVersions reports which version every environment is currently running.
+type DeployedVersions interface {
+	Deployed(ctx context.Context) (map[string]string, error) // environment → version
+}
+
+// DeployedVersionsFunc adapts a plain function to DeployedVersions.
+type DeployedVersionsFunc func(ctx context.Context) (map[string]string, error)
+
+func (f DeployedVersionsFunc) Deployed(ctx context.Context) (map[string]string, error) { return f(ctx) }
+
+// HTTPDeployed asks each environment's version endpoint directly. A response is either the bare version string or
+// a JSON object with a "version" field.
+type HTTPDeployed struct {
+	Endpoints map[string]string // environment → URL
+	Client    *http.Client      // defaults to http.DefaultClient
+}
+
+func (h HTTPDeployed) Deployed(ctx context.Context) (map[string]string, error) {
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	out := make(map[string]string, len(h.Endpoints))
+	for env, u := range h.Endpoints {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", env, err)
+		}
+		b, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", env, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", env, resp.Status)
+		}
+		var doc struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(b, &doc) == nil && doc.Version != "" {
+			out[env] = doc.Version
+		} else {
+			out[env] = strings.TrimSpace(string(b))
+		}
+	}
+	return out, nil
+}
+
+// EnvironmentLag is one row of a FleetReport.
+type EnvironmentLag struct {
+	Environment string `json:"environment"`
+	Running     string `json:"running"`
+	Behind      int    `json:"behind"`  // releases newer than Running
+	Lagging     bool   `json:"lagging"` // Behind exceeds the report's threshold
+	Unknown     bool   `json:"unknown"` // Running is not one of the known releases
+}
+
+// FleetReport cross-references the versions environments are running with the known releases (typically the tag
+// list) and flags every environment more than maxLag releases behind the latest one. Rows are sorted by lag, worst
+// first.
+func FleetReport(ctx context.Context, releases []string, p DeployedVersions, maxLag int) ([]EnvironmentLag, error) {
+	deployed, err := p.Deployed(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var finals []Version
+	for _, r := range releases {
+		if v, err := Parse(r); err == nil && v.Suffix == "" {
+			finals = append(finals, v)
+		}
+	}
+
+	var rows []EnvironmentLag
+	for env, running := range deployed {
+		row := EnvironmentLag{Environment: env, Running: running}
+		rv, err := Parse(running)
+		row.Unknown = err != nil || !containsString(releases, running)
+		if err == nil {
+			for _, f := range finals {
+				if f.Prefix == rv.Prefix && Compare(f, rv) > 0 {
+					row.Behind++
+				}
+			}
+		}
+		row.Lagging = row.Behind > maxLag
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Behind != rows[j].Behind {
+			return rows[i].Behind > rows[j].Behind
+		}
+		return rows[i].Environment < rows[j].Environment
+	})
+	return rows, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
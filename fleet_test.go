@@ -0,0 +1,50 @@
+package versioner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFleetReport(t *testing.T) {
+	releases := []string{"20250401.10", "20250410.20", "20250420.30", "20250428.40", "20250428.40.1"}
+	p := DeployedVersionsFunc(func(context.Context) (map[string]string, error) {
+		return map[string]string{"prod": "20250401.10", "staging": "20250428.40", "dev": "20250428.41-SNAPSHOT"}, nil
+	})
+	rows, err := FleetReport(context.Background(), releases, p, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].Environment != "prod" || rows[0].Behind != 4 || !rows[0].Lagging {
+		t.Fatalf("prod should lag 4 releases, got %+v", rows[0])
+	}
+	for _, r := range rows[1:] {
+		if r.Lagging {
+			t.Fatalf("%s should not be lagging: %+v", r.Environment, r)
+		}
+		if r.Environment == "dev" && !r.Unknown {
+			t.Fatalf("snapshot build should be reported as unknown: %+v", r)
+		}
+	}
+}
+
+func TestHTTPDeployed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/json" {
+			w.Write([]byte(`{"version":"20250428.40.1","commit":"abc"}`))
+			return
+		}
+		w.Write([]byte("20250420.30\n"))
+	}))
+	defer srv.Close()
+
+	got, err := HTTPDeployed{Endpoints: map[string]string{"a": srv.URL + "/json", "b": srv.URL + "/plain"}}.
+		Deployed(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != "20250428.40.1" || got["b"] != "20250420.30" {
+		t.Fatalf("unexpected %v", got)
+	}
+}
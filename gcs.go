@@ -0,0 +1,78 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GCS is an ObjectStore on a Google Cloud Storage bucket using the JSON API; revisions are object generations and
+// writes carry ifGenerationMatch.
+type GCS struct {
+	Bucket  string
+	Token   string // OAuth2 access token, e.g. from `gcloud auth print-access-token` or workload identity
+	BaseURL string // defaults to https://storage.googleapis.com
+	Client  *http.Client
+}
+
+func (g GCS) Get(ctx context.Context, key string) ([]byte, string, error) {
+	u := g.base() + "/storage/v1/b/" + url.PathEscape(g.Bucket) + "/o/" + url.PathEscape(key) + "?alt=media"
+	resp, err := g.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", ErrObjectNotFound
+	case resp.StatusCode >= 300:
+		return nil, "", newAPIError("gcs", http.MethodGet, key, resp)
+	}
+	b, err := io.ReadAll(resp.Body)
+	return b, resp.Header.Get("X-Goog-Generation"), err
+}
+
+func (g GCS) Put(ctx context.Context, key string, data []byte, rev string) error {
+	q := url.Values{"uploadType": {"media"}, "name": {key}, "ifGenerationMatch": {rev}}
+	if rev == "" {
+		q.Set("ifGenerationMatch", "0") // must not exist
+	}
+	u := g.base() + "/upload/storage/v1/b/" + url.PathEscape(g.Bucket) + "/o?" + q.Encode()
+	resp, err := g.do(ctx, http.MethodPost, u, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case resp.StatusCode >= 300:
+		return newAPIError("gcs", http.MethodPost, key, resp)
+	}
+	return nil
+}
+
+func (g GCS) base() string {
+	return strings.TrimSuffix(firstNonEmpty(g.BaseURL, "https://storage.googleapis.com"), "/")
+}
+
+func (g GCS) do(ctx context.Context, method, u string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,79 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// GitHub publishes releases for repositories mirrored to GitHub.
+type GitHub struct {
+	BaseURL string // defaults to $GITHUB_API_URL, then https://api.github.com
+	Repo    string // "owner/name"; defaults to $GITHUB_REPOSITORY
+	Token   string // sent as a Bearer token; defaults to $GITHUB_TOKEN
+	Client  *http.Client
+}
+
+// Publish creates a GitHub Release for r.Version. A 422 answer whose errors say "already_exists" means the release
+// is already there.
+func (gh GitHub) Publish(ctx context.Context, r Release) error {
+	body, err := json.Marshal(map[string]string{
+		"tag_name":         r.tag(),
+		"target_commitish": r.Commit,
+		"name":             r.Version,
+		"body":             r.Notes,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := gh.do(ctx, http.MethodPost, "releases", bytes.NewReader(body))
+	if ae := (*apiError)(nil); errors.As(err, &ae) && ae.Code == http.StatusUnprocessableEntity &&
+		strings.Contains(ae.Body, "already_exists") {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (gh GitHub) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	base := strings.TrimSuffix(firstNonEmpty(gh.BaseURL, os.Getenv("GITHUB_API_URL"), "https://api.github.com"), "/")
+	repo := firstNonEmpty(gh.Repo, os.Getenv("GITHUB_REPOSITORY"))
+	u := fmt.Sprintf("%s/repos/%s/%s", base, repo, path)
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if tok := firstNonEmpty(gh.Token, os.Getenv("GITHUB_TOKEN")); tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+
+	client := gh.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, newAPIError("github", method, path, resp)
+	}
+	return resp, nil
+}
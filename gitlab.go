@@ -0,0 +1,185 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// GitLab is a minimal client for the parts of the GitLab REST API the versioner needs.
+type GitLab struct {
+	BaseURL  string // e.g. https://gitlab.example.com/api/v4; defaults to $CI_API_V4_URL
+	Project  string // numeric ID or full path; defaults to $CI_PROJECT_ID
+	Token    string // personal/project access token, sent as PRIVATE-TOKEN
+	JobToken string // CI_JOB_TOKEN, used when Token is empty
+	Client   *http.Client
+}
+
+// ImportReport summarizes an ImportGitLab run.
+type ImportReport struct {
+	Imported []string // versions added to the ledger
+	Existing []string // versions the ledger already had
+	Foreign  []string // tags outside this package's scheme
+}
+
+// ImportGitLab backfills l from the project's Releases and tags, including ones created before this tool existed,
+// so history and reports cover the project's full past. Releases win over bare tags of the same name; versions the
+// ledger already knows are left untouched, which makes the import safe to re-run.
+func ImportGitLab(ctx context.Context, gl GitLab, l Ledger) (ImportReport, error) {
+	var rep ImportReport
+	known := map[string]bool{}
+	ms, err := l.Manifests()
+	if err != nil {
+		return rep, err
+	}
+	for _, m := range ms {
+		known[m.Version] = true
+	}
+
+	var releases []struct {
+		TagName    string    `json:"tag_name"`
+		ReleasedAt time.Time `json:"released_at"`
+		Commit     struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	if err := gl.list(ctx, "releases", &releases); err != nil {
+		return rep, err
+	}
+	var tags []struct {
+		Name   string `json:"name"`
+		Commit struct {
+			ID        string    `json:"id"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"commit"`
+	}
+	if err := gl.list(ctx, "repository/tags", &tags); err != nil {
+		return rep, err
+	}
+
+	var found []Manifest
+	for _, r := range releases {
+		found = append(found, Manifest{Version: r.TagName, Commit: r.Commit.ID, Time: r.ReleasedAt})
+	}
+	for _, t := range tags {
+		if !containsVersion(found, t.Name) {
+			found = append(found, Manifest{Version: t.Name, Commit: t.Commit.ID, Time: t.Commit.CreatedAt})
+		}
+	}
+
+	for _, m := range found {
+		switch _, err := Parse(m.Version); {
+		case err != nil:
+			rep.Foreign = append(rep.Foreign, m.Version)
+		case known[m.Version]:
+			rep.Existing = append(rep.Existing, m.Version)
+		default:
+			if err := l.Record(m); err != nil {
+				return rep, err
+			}
+			known[m.Version] = true
+			rep.Imported = append(rep.Imported, m.Version)
+		}
+	}
+	return rep, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// list GETs every page of a project collection into out, which must point to a slice.
+func (gl GitLab) list(ctx context.Context, path string, out any) error {
+	var all []json.RawMessage
+	for page := "1"; page != ""; {
+		resp, err := gl.do(ctx, http.MethodGet, path+"?per_page=100&page="+page, nil)
+		if err != nil {
+			return err
+		}
+		var items []json.RawMessage
+		err = json.NewDecoder(resp.Body).Decode(&items)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("gitlab %s: %w", path, err)
+		}
+		all = append(all, items...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+	b, _ := json.Marshal(all)
+	return json.Unmarshal(b, out)
+}
+
+func (gl GitLab) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	base := strings.TrimSuffix(firstNonEmpty(gl.BaseURL, os.Getenv("CI_API_V4_URL")), "/")
+	project := firstNonEmpty(gl.Project, os.Getenv("CI_PROJECT_ID"))
+	u := fmt.Sprintf("%s/projects/%s/%s", base, url.PathEscape(project), path)
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case gl.Token != "":
+		req.Header.Set("PRIVATE-TOKEN", gl.Token)
+	case gl.JobToken != "":
+		req.Header.Set("JOB-TOKEN", gl.JobToken)
+	}
+
+	client := gl.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, newAPIError("gitlab", method, path, resp)
+	}
+	return resp, nil
+}
+
+// apiError is a non-2xx answer from a hosting API; callers inspect Code for outcomes they tolerate.
+type apiError struct {
+	API, Method, Path string
+	Code              int
+	Status, Body      string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s %s: %s: %s", e.API, e.Method, e.Path, e.Status, e.Body)
+}
+
+func newAPIError(api, method, path string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	resp.Body.Close()
+	return &apiError{API: api, Method: method, Path: path, Code: resp.StatusCode, Status: resp.Status,
+		Body: strings.TrimSpace(string(msg))}
+}
+
+func firstNonEmpty(ss ...string) string {
+	for _, s := range ss {
+		if s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func containsVersion(ms []Manifest, v string) bool {
+	for _, m := range ms {
+		if m.Version == v {
+			return true
+		}
+	}
+	return false
+}
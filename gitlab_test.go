@@ -0,0 +1,55 @@
+package versioner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func fakeGitLab(t *testing.T, routes map[string]string) GitLab {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return GitLab{BaseURL: srv.URL, Project: "grp/app", Token: "tok"}
+}
+
+func TestImportGitLab(t *testing.T) {
+	gl := fakeGitLab(t, map[string]string{
+		"GET /projects/grp/app/releases": `[{"tag_name":"20250428.100.1","released_at":"2025-04-29T10:00:00Z",
+			"commit":{"id":"bbb"}}]`,
+		"GET /projects/grp/app/repository/tags": `[
+			{"name":"20250428.100.1","commit":{"id":"bbb","created_at":"2025-04-29T09:00:00Z"}},
+			{"name":"20250428.100","commit":{"id":"aaa","created_at":"2025-04-28T09:00:00Z"}},
+			{"name":"v1.4.2","commit":{"id":"ccc","created_at":"2024-01-01T00:00:00Z"}}]`,
+	})
+	l := FileLedger{Path: filepath.Join(t.TempDir(), "ledger.jsonl")}
+	l.Record(Manifest{Version: "20250428.100"})
+
+	rep, err := ImportGitLab(context.Background(), gl, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Imported) != 1 || rep.Imported[0] != "20250428.100.1" {
+		t.Fatalf("imported %v", rep.Imported)
+	}
+	if len(rep.Existing) != 1 || len(rep.Foreign) != 1 || rep.Foreign[0] != "v1.4.2" {
+		t.Fatalf("unexpected report %+v", rep)
+	}
+	ms, _ := l.Manifests()
+	if last := ms[len(ms)-1]; last.Commit != "bbb" || last.Time.Hour() != 10 {
+		t.Fatalf("release data should win over tag data, got %+v", last)
+	}
+}
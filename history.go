@@ -0,0 +1,96 @@
+package versioner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// HistoryOptions narrows History.
+type HistoryOptions struct {
+	Prefix    string // only versions carrying exactly this prefix; empty selects unprefixed versions
+	Namespace string // only tags in this namespace (see InNamespace); empty selects tags outside any namespace
+	Base      string // optional "YYYYMMDD.<build>": only that default build and its release patches
+	Since     string // optional "YYYYMMDD": only versions dated on or after that day
+	Limit     int    // optional; keep the newest Limit entries
+
+	IncludeRetracted bool          // also list versions marked with Retract
+	GitTimeout       time.Duration // bound on the tag listing; DefaultGitTimeout when zero
+}
+
+// HistoryEntry is one released (final) version.
+type HistoryEntry struct {
+	Version Version
+	Commit  string    // full SHA of the tagged commit, peeled for annotated tags
+	Time    time.Time // tag creation time (commit time for lightweight tags)
+
+	Retracted bool // only with HistoryOptions.IncludeRetracted
+}
+
+// History lists the repository's final versions newest-first, so dashboards and CLIs can show a release timeline
+// without reparsing `git tag` output. Snapshot and foreign tags are skipped, and so are retracted versions unless
+// requested.
+func History(opts HistoryOptions) ([]HistoryEntry, error) {
+	if opts.Since != "" {
+		if _, err := time.Parse("20060102", opts.Since); err != nil || len(opts.Since) != 8 {
+			return nil, fmt.Errorf("%w: since %q is not a YYYYMMDD date", ErrInvalidConfig, opts.Since)
+		}
+	}
+	c := BuildContext{Config: Config{Timeouts: Timeouts{Git: opts.GitTimeout}}}
+	out, err := c.git("for-each-ref", "refs/tags",
+		"--format=%(refname:strip=2)%1f%(objectname)%1f%(*objectname)%1f%(creatordate:iso-strict)")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var names []string
+	for _, line := range lines {
+		name, _, _ := strings.Cut(line, "\x1f")
+		names = append(names, name)
+	}
+	retracted := Retracted(InNamespace(names, opts.Namespace))
+
+	var hs []HistoryEntry
+	for _, line := range lines {
+		f := strings.Split(line, "\x1f")
+		ns := InNamespace(f[:1], opts.Namespace)
+		if len(ns) == 0 {
+			continue
+		}
+		f[0] = ns[0]
+		if len(f) != 4 || !IsFinal(f[0]) || retracted[f[0]] && !opts.IncludeRetracted {
+			continue
+		}
+		v, _ := Parse(f[0])
+		if v.Prefix != opts.Prefix || opts.Base != "" && v.Base() != opts.Base || v.Date < opts.Since {
+			continue
+		}
+		e := HistoryEntry{Version: v, Commit: firstNonEmpty(f[2], f[1]), Retracted: retracted[f[0]]}
+		e.Time, _ = time.Parse(time.RFC3339, f[3])
+		hs = append(hs, e)
+	}
+
+	sort.Slice(hs, func(i, j int) bool { return Compare(hs[i].Version, hs[j].Version) > 0 })
+	if opts.Limit > 0 && len(hs) > opts.Limit {
+		hs = hs[:opts.Limit]
+	}
+	return hs, nil
+}
+
+// Latest is the newest entry History would list, for scripts asking "what is the current release of <prefix>?";
+// ErrNoMatchingTags when there is none.
+func Latest(opts HistoryOptions) (HistoryEntry, error) {
+	opts.Limit = 1
+	hs, err := History(opts)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if len(hs) == 0 {
+		return HistoryEntry{}, fmt.Errorf("%w: no release with prefix %q", ErrNoMatchingTags, opts.Prefix)
+	}
+	return hs[0], nil
+}
@@ -0,0 +1,56 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	gitRepo(t)
+	head := func() string { return mustGit(t, "", "rev-parse", "HEAD") }
+	mustGit(t, "", "tag", "20250427.90")
+	first := head()
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "two")
+	mustGit(t, "", "tag", "-a", "-m", "Release", "20250428.100")
+	second := head()
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "three")
+	mustGit(t, "", "tag", "20250428.100.1")
+	mustGit(t, "", "tag", "20250428.101-feat")
+	mustGit(t, "", "tag", "v1.2.3")
+
+	hs, err := History(HistoryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, h := range hs {
+		got = append(got, h.Version.String())
+	}
+	if want := "[20250428.100.1 20250428.100 20250427.90]"; fmt.Sprint(got) != want {
+		t.Fatalf("got %v want %s", got, want)
+	}
+	if hs[1].Commit != second || hs[2].Commit != first || hs[0].Version.Patch != 1 || hs[0].Time.IsZero() {
+		t.Fatalf("unexpected entries %+v", hs)
+	}
+
+	hs, _ = History(HistoryOptions{Base: "20250428.100", Limit: 1})
+	if len(hs) != 1 || hs[0].Version.String() != "20250428.100.1" {
+		t.Fatalf("unexpected filtered history %+v", hs)
+	}
+
+	hs, _ = History(HistoryOptions{Since: "20250428"})
+	if len(hs) != 2 || hs[1].Version.String() != "20250428.100" {
+		t.Fatalf("unexpected history since 20250428: %+v", hs)
+	}
+	if _, err := History(HistoryOptions{Since: "2025-04-28"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want ErrInvalidConfig", err)
+	}
+
+	if h, err := Latest(HistoryOptions{}); err != nil || h.Version.String() != "20250428.100.1" {
+		t.Fatalf("latest: got %+v, %v", h, err)
+	}
+	if _, err := Latest(HistoryOptions{Prefix: "api"}); !errors.Is(err, ErrNoMatchingTags) {
+		t.Fatalf("latest api: got %v want ErrNoMatchingTags", err)
+	}
+}
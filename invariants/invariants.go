@@ -0,0 +1,146 @@
+// Package invariants verifies the properties a tag history of the versioner scheme must have, so tests and the
+// `versioner audit` command can catch drift (hand-made tags, lost patches, clocks running backwards) early.
+//
+//   - every tag that looks like a version parses and carries a real date
+//   - the patches of each release are contiguous: 1, 2, … with no gaps
+//   - default-branch builds of one prefix and epoch never go back in date as the build number grows
+//   - no version is tagged twice under different spellings (zero padding, build metadata)
+//   - final versions point at commits on the default branch or a release branch (with Options.OnBranch)
+package invariants
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	versioner "github.com/drew-mcl/test"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Rules reported in Violation.Rule.
+const (
+	RuleParse      = "parse"      // a version-like tag is malformed
+	RuleContiguous = "contiguous" // a release is missing patches
+	RuleDateOrder  = "date-order" // a later build carries an earlier date
+	RuleDuplicate  = "duplicate"  // several tags spell the same version
+	RuleOffBranch  = "off-branch" // a final version's commit is on no release line
+)
+
+// Options tunes the checks to the repository's configuration.
+type Options struct {
+	DailySequence bool // builds restart at 1 every day (Config.DailySequence); skips RuleDateOrder
+
+	// OnBranch reports whether a tag's commit is on the default branch or a release branch, e.g.
+	// BuildContext.OnReleaseLine. Nil skips RuleOffBranch.
+	OnBranch func(tag string) (bool, error)
+}
+
+// Violation is one broken invariant and the tags involved.
+type Violation struct {
+	Rule   string   `json:"rule"`
+	Tags   []string `json:"tags"`
+	Detail string   `json:"detail"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s (%s)", v.Rule, v.Detail, strings.Join(v.Tags, ", "))
+}
+
+// Check verifies tags against all invariants and returns the violations, sorted by rule; none means the history is
+// sound. Tags that don't look like versions (no YYYYMMDD.<n>) are ignored.
+func Check(tags []string, opts Options) []Violation {
+	var out []Violation
+	patches := map[string]map[int]string{} // release key → patch → tag
+	builds := map[string][]versioner.Version{}
+	byVersion := map[string]string{}
+	spellings := map[string][]string{} // canonical version → tags
+
+	for _, t := range tags {
+		if !looksRE.MatchString(t) {
+			continue
+		}
+		if err := versioner.Validate(t); err != nil {
+			out = append(out, Violation{RuleParse, []string{t}, err.Error()})
+			continue
+		}
+		v, _ := versioner.Parse(t)
+		canon := v
+		canon.BuildWidth, canon.PatchWidth, canon.Commit = 0, 0, ""
+		spellings[canon.String()] = append(spellings[canon.String()], t)
+		if v.Suffix != "" {
+			continue
+		}
+		if opts.OnBranch != nil {
+			switch ok, err := opts.OnBranch(t); {
+			case err != nil:
+				out = append(out, Violation{RuleOffBranch, []string{t}, "cannot check: " + err.Error()})
+			case !ok:
+				out = append(out, Violation{RuleOffBranch, []string{t}, "commit is on neither the default nor a release branch"})
+			}
+		}
+		line := v.Prefix + "|" + strconv.Itoa(v.Epoch)
+		if v.Patch == 0 {
+			builds[line] = append(builds[line], v)
+			byVersion[v.String()] = t
+			continue
+		}
+		key := line + "|" + v.Base()
+		if patches[key] == nil {
+			patches[key] = map[int]string{}
+		}
+		patches[key][v.Patch] = t
+	}
+
+	for v, ts := range spellings {
+		if len(ts) > 1 {
+			sort.Strings(ts)
+			out = append(out, Violation{RuleDuplicate, ts, v + " is tagged " + strconv.Itoa(len(ts)) + " times"})
+		}
+	}
+
+	for _, ps := range patches {
+		max, any := 0, ""
+		for p, t := range ps {
+			if p > max {
+				max, any = p, t
+			}
+		}
+		var missing []string
+		for p := 1; p < max; p++ {
+			if _, ok := ps[p]; !ok {
+				missing = append(missing, strconv.Itoa(p))
+			}
+		}
+		if missing != nil {
+			out = append(out, Violation{RuleContiguous, []string{any},
+				fmt.Sprintf("patches %s missing below %d", strings.Join(missing, ", "), max)})
+		}
+	}
+
+	if !opts.DailySequence {
+		for _, vs := range builds {
+			sort.Slice(vs, func(i, j int) bool { return vs[i].Build < vs[j].Build })
+			for i := 1; i < len(vs); i++ {
+				if vs[i].Date < vs[i-1].Date {
+					out = append(out, Violation{RuleDateOrder, []string{byVersion[vs[i-1].String()], byVersion[vs[i].String()]},
+						"build number increased but the date went back"})
+				}
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Rule != out[j].Rule {
+			return out[i].Rule < out[j].Rule
+		}
+		return strings.Join(out[i].Tags, " ") < strings.Join(out[j].Tags, " ")
+	})
+	return out
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var looksRE = regexp.MustCompile(`\d{8}\.\d`)
@@ -0,0 +1,40 @@
+package invariants
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tags := []string{
+		"v1.2.3", // not ours
+		"20250101.5", "20250101.5.1", "20250101.5.2",
+		"20250102.9", "20250102.9.1", "20250102.9.4",
+		"20250103.12", "20250102.15", // date went back
+		"20251340.20", // no such date
+		"20250101.5-SNAPSHOT", "svc-20250110.1", "svc-20250111.2",
+	}
+	got := fmt.Sprint(Check(tags, Options{}))
+	want := "[contiguous: patches 2, 3 missing below 4 (20250102.9.4) " +
+		"date-order: build number increased but the date went back (20250103.12, 20250102.15) " +
+		"parse: invalid version: 20251340.20: no such date 20251340 (20251340.20)]"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	if vs := Check([]string{"20250103.1", "20250102.2"}, Options{DailySequence: true}); len(vs) != 0 {
+		t.Fatalf("daily sequence: got %v", vs)
+	}
+}
+
+func TestCheckDuplicatesAndBranches(t *testing.T) {
+	tags := []string{"20250101.5", "20250101.005", "20250101.5.1+abc", "20250101.5.1", "20250102.6", "20250102.6-rc.1"}
+	off := map[string]bool{"20250102.6": true, "20250102.6-rc.1": true}
+	opts := Options{OnBranch: func(tag string) (bool, error) { return !off[tag], nil }}
+	got := fmt.Sprint(Check(tags, opts))
+	want := "[duplicate: 20250101.5 is tagged 2 times (20250101.005, 20250101.5) " +
+		"duplicate: 20250101.5.1 is tagged 2 times (20250101.5.1, 20250101.5.1+abc) " +
+		"off-branch: commit is on neither the default nor a release branch (20250102.6)]"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
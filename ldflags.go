@@ -0,0 +1,27 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LDFlags renders the `go build -ldflags` stanza "-X <pkg>.version=… -X <pkg>.commit=… -X <pkg>.date=…" for m, so
+// Go build jobs embed the version without assembling strings in shell. pkg defaults to main; empty values are left
+// out so the binary keeps its own defaults.
+func LDFlags(pkg string, m Manifest) string {
+	if pkg == "" {
+		pkg = "main"
+	}
+	var date string
+	if !m.Time.IsZero() {
+		date = m.Time.UTC().Format(time.RFC3339)
+	}
+	var fs []string
+	for _, kv := range [][2]string{{"version", m.Version}, {"commit", m.Commit}, {"date", date}} {
+		if kv[1] != "" {
+			fs = append(fs, fmt.Sprintf("-X %s.%s=%s", pkg, kv[0], kv[1]))
+		}
+	}
+	return strings.Join(fs, " ")
+}
@@ -0,0 +1,15 @@
+package versioner
+
+import "testing"
+
+func TestLDFlags(t *testing.T) {
+	m := Manifest{Version: "20250428.100.1", Commit: "abc123", Time: now}
+	want := "-X main.version=20250428.100.1 -X main.commit=abc123 -X main.date=2025-04-28T15:00:00Z"
+	if got := LDFlags("", m); got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	if got := LDFlags("example.com/app/internal/build", Manifest{Version: "20250428.100"}); got !=
+		"-X example.com/app/internal/build.version=20250428.100" {
+		t.Fatalf("got %s", got)
+	}
+}
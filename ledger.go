@@ -0,0 +1,60 @@
+package versioner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Ledger persists the manifests of released versions so they can be inspected after the pipeline is gone.
+type Ledger interface {
+	Record(m Manifest) error
+	Manifests() ([]Manifest, error) // in recording order
+}
+
+// FileLedger is a Ledger stored as JSON lines, e.g. committed next to the code or kept as a CI artifact.
+type FileLedger struct {
+	Path string
+}
+
+func (l FileLedger) Record(m Manifest) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (l FileLedger) Manifests() ([]Manifest, error) {
+	f, err := os.Open(l.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ms []Manifest
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		var m Manifest
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", l.Path, n, err)
+		}
+		ms = append(ms, m)
+	}
+	return ms, sc.Err()
+}
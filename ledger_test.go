@@ -0,0 +1,25 @@
+package versioner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLedgerRoundTrip(t *testing.T) {
+	l := FileLedger{Path: filepath.Join(t.TempDir(), "ledger.jsonl")}
+	if ms, err := l.Manifests(); err != nil || len(ms) != 0 {
+		t.Fatalf("missing ledger should be empty, got %v, %v", ms, err)
+	}
+	for _, v := range []string{"20250428.100", "20250428.100.1"} {
+		if err := l.Record(Manifest{Version: v, Commit: "abc", Time: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ms, err := l.Manifests()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 || ms[1].Version != "20250428.100.1" || !ms[1].Time.Equal(now) {
+		t.Fatalf("unexpected manifests %+v", ms)
+	}
+}
@@ -0,0 +1,202 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Locker serializes patch allocation on a release branch across concurrent pipelines. Lock blocks until the key is
+// held or ctx is done and returns the function that releases it.
+type Locker interface {
+	Lock(ctx context.Context, key string) (unlock func() error, err error)
+}
+
+// FileLocker is a Locker backed by lockfiles in a directory shared by all runners (NFS, a mounted bucket …).
+// Acquisition relies on exclusive file creation, which such mounts honour. Locks left behind by crashed pipelines
+// are broken once they are older than TTL.
+type FileLocker struct {
+	Dir   string
+	Owner string        // recorded in the lockfile for diagnostics; defaults to the hostname
+	Poll  time.Duration // retry interval while the lock is held elsewhere; defaults to 500ms
+	TTL   time.Duration // age after which a lock counts as abandoned; defaults to 15m
+	Clock Clock         // stamps locks and measures their age; defaults to SystemClock
+}
+
+// LockInfo describes a lock held in a FileLocker directory.
+type LockInfo struct {
+	Key      string    `json:"key"`
+	Owner    string    `json:"owner"`
+	Acquired time.Time `json:"acquired"`
+	Stale    bool      `json:"stale"`
+}
+
+func (l FileLocker) Lock(ctx context.Context, key string) (func() error, error) {
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	poll := l.Poll
+	if poll <= 0 {
+		poll = 500 * time.Millisecond
+	}
+	owner := l.Owner
+	if owner == "" {
+		owner, _ = os.Hostname()
+	}
+
+	path := filepath.Join(l.Dir, lockName(key))
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			held := LockInfo{Key: key, Owner: owner, Acquired: l.now().UTC()} // stamped on acquisition, not before the wait
+			info, _ := json.Marshal(held)
+			f.Write(info)
+			f.Close()
+			return func() error { return l.release(path, held) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if l.breakStale(path) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("lock %s: %w", key, ctx.Err())
+		case <-time.After(poll):
+		}
+	}
+}
+
+// List returns every lock currently present, oldest first.
+func (l FileLocker) List() ([]LockInfo, error) {
+	ents, err := os.ReadDir(l.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var locks []LockInfo
+	for _, e := range ents {
+		if !strings.HasSuffix(e.Name(), ".lock") {
+			continue
+		}
+		li, err := l.read(filepath.Join(l.Dir, e.Name()))
+		if err != nil {
+			continue // released meanwhile
+		}
+		locks = append(locks, li)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Acquired.Before(locks[j].Acquired) })
+	return locks, nil
+}
+
+// Clear force-releases the lock on key. It is not an error if the lock is not held.
+func (l FileLocker) Clear(key string) error {
+	err := os.Remove(filepath.Join(l.Dir, lockName(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// ClearStale releases every lock older than TTL and returns their keys.
+func (l FileLocker) ClearStale() ([]string, error) {
+	locks, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+	var cleared []string
+	for _, li := range locks {
+		if li.Stale && l.breakStale(filepath.Join(l.Dir, lockName(li.Key))) {
+			cleared = append(cleared, li.Key)
+		}
+	}
+	return cleared, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (l FileLocker) now() time.Time {
+	if l.Clock == nil {
+		return SystemClock.Now()
+	}
+	return l.Clock.Now()
+}
+
+func (l FileLocker) ttl() time.Duration {
+	if l.TTL <= 0 {
+		return 15 * time.Minute
+	}
+	return l.TTL
+}
+
+// release removes the lockfile at path if it is still the one held describes. A lock that was broken as stale and
+// taken by another pipeline meanwhile is left alone.
+func (l FileLocker) release(path string, held LockInfo) error {
+	li, err := l.read(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("lock %s: broken as stale while held", held.Key)
+	}
+	if err != nil {
+		return err
+	}
+	if li.Owner != held.Owner || !li.Acquired.Equal(held.Acquired) {
+		return fmt.Errorf("lock %s: broken as stale while held, now held by %s since %s", held.Key, li.Owner,
+			li.Acquired.Format(time.RFC3339))
+	}
+	return os.Remove(path)
+}
+
+func (l FileLocker) read(path string) (LockInfo, error) {
+	var li LockInfo
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return li, err
+	}
+	if json.Unmarshal(b, &li) != nil || li.Acquired.IsZero() {
+		// unreadable (or still being written) lockfile: fall back to its mtime
+		st, err := os.Stat(path)
+		if err != nil {
+			return li, err
+		}
+		li.Key, li.Acquired = strings.TrimSuffix(filepath.Base(path), ".lock"), st.ModTime()
+	}
+	li.Stale = l.now().Sub(li.Acquired) > l.ttl()
+	return li, nil
+}
+
+// breakStale removes the lockfile at path if it has outlived the TTL. The file is first renamed to a unique tombstone
+// so that of several waiters breaking the same lock only one succeeds; should a fresh lock have been renamed by
+// mistake it is put back, unless an even newer lock took its place meanwhile.
+func (l FileLocker) breakStale(path string) bool {
+	if li, err := l.read(path); err != nil || !li.Stale {
+		return false
+	}
+	tomb := path + ".stale-" + strconv.FormatUint(rand.Uint64(), 36)
+	if os.Rename(path, tomb) != nil {
+		return false
+	}
+	if li, err := l.read(tomb); err == nil && !li.Stale {
+		os.Link(tomb, path) // unlike a rename, never replaces a lockfile created since
+		os.Remove(tomb)
+		return false
+	}
+	os.Remove(tomb)
+	return true
+}
+
+func lockName(key string) string {
+	return strings.NewReplacer("/", "_", `\`, "_").Replace(key) + ".lock"
+}
@@ -0,0 +1,114 @@
+package versioner
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFileLockerExcludes(t *testing.T) {
+	l := FileLocker{Dir: t.TempDir(), Poll: time.Millisecond}
+	unlock, err := l.Lock(context.Background(), "release/v20250428.100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Lock(ctx, "release/v20250428.100"); err == nil {
+		t.Fatal("second lock acquired while the first is held")
+	}
+	if _, err := l.Lock(context.Background(), "release/v20250428.200"); err != nil {
+		t.Fatalf("unrelated key blocked: %v", err)
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Lock(context.Background(), "release/v20250428.100"); err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+}
+
+func TestFileLockerBreaksStaleLocks(t *testing.T) {
+	l := FileLocker{Dir: t.TempDir(), Poll: time.Millisecond, TTL: time.Hour}
+	if _, err := l.Lock(context.Background(), "release/v20250428.100"); err != nil {
+		t.Fatal(err)
+	}
+	locks, err := l.List()
+	if err != nil || len(locks) != 1 || locks[0].Key != "release/v20250428.100" || locks[0].Stale {
+		t.Fatalf("unexpected locks %+v, %v", locks, err)
+	}
+
+	// the holder crashed; with a short TTL the next pipeline takes over
+	l.TTL = time.Nanosecond
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := l.Lock(ctx, "release/v20250428.100"); err != nil {
+		t.Fatalf("stale lock not broken: %v", err)
+	}
+
+	cleared, err := l.ClearStale()
+	if err != nil || len(cleared) != 1 {
+		t.Fatalf("cleared %v, %v", cleared, err)
+	}
+	if locks, _ := l.List(); len(locks) != 0 {
+		t.Fatalf("locks left behind: %+v", locks)
+	}
+}
+
+func TestFileLockerStampsAcquisition(t *testing.T) {
+	l := FileLocker{Dir: t.TempDir(), Poll: time.Millisecond}
+	unlock, err := l.Lock(context.Background(), "release/v20250428.100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := l.Lock(context.Background(), "release/v20250428.100")
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	released := time.Now()
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if locks, _ := l.List(); len(locks) != 1 || locks[0].Acquired.Before(released) {
+		t.Fatalf("waiter's lock stamped before it was acquired: %+v (released %s)", locks, released)
+	}
+}
+
+func TestFileLockerUnlockKeepsTakenOverLock(t *testing.T) {
+	l := FileLocker{Dir: t.TempDir(), Poll: time.Millisecond, TTL: time.Nanosecond}
+	unlock, err := l.Lock(context.Background(), "release/v20250428.100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	l.Owner = "rival"
+	if _, err := l.Lock(context.Background(), "release/v20250428.100"); err != nil {
+		t.Fatal(err)
+	}
+	if err := unlock(); err == nil {
+		t.Fatal("unlock of a lock broken as stale succeeded")
+	}
+	if locks, _ := l.List(); len(locks) != 1 || locks[0].Owner != "rival" {
+		t.Fatalf("rival's lock removed: %+v", locks)
+	}
+}
+
+func TestFileLockerClock(t *testing.T) {
+	at := time.Date(2025, 4, 28, 15, 0, 0, 0, time.UTC)
+	l := FileLocker{Dir: t.TempDir(), TTL: time.Hour, Clock: ClockFunc(func() time.Time { return at })}
+	if _, err := l.Lock(context.Background(), "release/v20250428.100"); err != nil {
+		t.Fatal(err)
+	}
+	if locks, _ := l.List(); len(locks) != 1 || !locks[0].Acquired.Equal(at) || locks[0].Stale {
+		t.Fatalf("unexpected locks %+v", locks)
+	}
+	at = at.Add(2 * time.Hour)
+	if locks, _ := l.List(); len(locks) != 1 || !locks[0].Stale {
+		t.Fatalf("lock not stale two hours later: %+v", locks)
+	}
+}
@@ -0,0 +1,137 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Metrics collects versioning health in the Prometheus text format: versions computed by kind, tag-lookup latency
+// and tag-push retries. Set it on BuildContext.Metrics (and Server.Metrics); a nil *Metrics records nothing.
+type Metrics struct {
+	mu       sync.Mutex
+	computed map[string]uint64 // by Kind
+	retries  uint64
+	buckets  []uint64 // cumulative counts per lookupBuckets bound
+	count    uint64
+	sum      float64
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b bytes.Buffer
+	b.WriteString("# HELP versioner_versions_computed_total Versions computed, by branch kind.\n")
+	b.WriteString("# TYPE versioner_versions_computed_total counter\n")
+	kinds := make([]string, 0, len(m.computed))
+	for k := range m.computed {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Fprintf(&b, "versioner_versions_computed_total{kind=%q} %d\n", k, m.computed[k])
+	}
+
+	b.WriteString("# HELP versioner_tag_push_retries_total Tag pushes retried after a concurrent pipeline won.\n")
+	b.WriteString("# TYPE versioner_tag_push_retries_total counter\n")
+	fmt.Fprintf(&b, "versioner_tag_push_retries_total %d\n", m.retries)
+
+	b.WriteString("# HELP versioner_tag_lookup_seconds Latency of tag lookups.\n")
+	b.WriteString("# TYPE versioner_tag_lookup_seconds histogram\n")
+	for i, le := range lookupBuckets {
+		var n uint64
+		if m.buckets != nil {
+			n = m.buckets[i]
+		}
+		fmt.Fprintf(&b, "versioner_tag_lookup_seconds_bucket{le=\"%g\"} %d\n", le, n)
+	}
+	fmt.Fprintf(&b, "versioner_tag_lookup_seconds_bucket{le=\"+Inf\"} %d\n", m.count)
+	fmt.Fprintf(&b, "versioner_tag_lookup_seconds_sum %g\n", m.sum)
+	fmt.Fprintf(&b, "versioner_tag_lookup_seconds_count %d\n", m.count)
+
+	return b.WriteTo(w)
+}
+
+// ServeHTTP serves the metrics for Prometheus to scrape.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteTo(w)
+}
+
+// Push sends the metrics to a Prometheus Pushgateway under job, for CLI runs that end before any scrape.
+func (m *Metrics) Push(ctx context.Context, gateway, job string) error {
+	var b bytes.Buffer
+	if _, err := m.WriteTo(&b); err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(gateway, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, &b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pushgateway %s: %s", u, resp.Status)
+	}
+	return nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var lookupBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+func (m *Metrics) versionComputed(k Kind) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.computed == nil {
+		m.computed = map[string]uint64{}
+	}
+	m.computed[k.String()]++
+}
+
+func (m *Metrics) pushRetried() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.retries++
+	m.mu.Unlock()
+}
+
+func (m *Metrics) observeLookup(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buckets == nil {
+		m.buckets = make([]uint64, len(lookupBuckets))
+	}
+	s := d.Seconds()
+	for i, le := range lookupBuckets {
+		if s <= le {
+			m.buckets[i]++
+		}
+	}
+	m.count++
+	m.sum += s
+}
@@ -0,0 +1,49 @@
+package versioner
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	m := &Metrics{}
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, []string{"20250428.100.1"})
+	c.Metrics = m
+	c.Version()
+	c.Branch = "main"
+	c.Version()
+	c.Version()
+	m.pushRetried()
+
+	var b strings.Builder
+	m.WriteTo(&b)
+	for _, want := range []string{
+		`versioner_versions_computed_total{kind="default"} 2`,
+		`versioner_versions_computed_total{kind="release"} 1`,
+		`versioner_tag_push_retries_total 1`,
+		`versioner_tag_lookup_seconds_count 1`,
+		`versioner_tag_lookup_seconds_bucket{le="+Inf"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("missing %q in\n%s", want, b.String())
+		}
+	}
+
+	var pushed string
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/metrics/job/versioner" {
+			body, _ := io.ReadAll(r.Body)
+			pushed = string(body)
+		}
+	}))
+	defer gw.Close()
+	if err := m.Push(t.Context(), gw.URL, "versioner"); err != nil || pushed != b.String() {
+		t.Fatalf("push: %v, got %q", err, pushed)
+	}
+
+	var nilMetrics *Metrics
+	nilMetrics.versionComputed(KindDefault) // must not panic
+}
@@ -0,0 +1,229 @@
+package versioner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// TagMigration maps one legacy 'vX.Y.Z' tag onto the tag the configured scheme gives the same commit.
+type TagMigration struct {
+	From     string    `json:"from"`
+	To       string    `json:"to,omitempty"`
+	Commit   string    `json:"commit"`
+	Time     time.Time `json:"time"`               // creation time of From, which dates its CalVer tag
+	Migrated bool      `json:"migrated,omitempty"` // the commit already carries To, e.g. from an earlier run
+	Conflict string    `json:"conflict,omitempty"` // why From cannot be migrated; To is left alone
+}
+
+// PlanTagMigration maps the repository's legacy tags of Config.Prefix ('vX.Y.Z' or '<prefix>-vX.Y.Z', in
+// Config.Namespace) onto the configured scheme, oldest first, without touching a tag:
+//
+//   - SemVer: 'vX.Y.Z' becomes 'X.Y.Z'.
+//   - CalVer: each MAJOR.MINOR line becomes one default build, dated by its oldest tag in Config.Timezone and numbered
+//     1, 2, … per day around the builds already tagged that day; 'vX.Y.0' becomes '<date>.<n>' and 'vX.Y.Z' its
+//     release patch '<date>.<n>.<Z>'.
+//
+// A commit that already carries a final tag of the scheme keeps it, so running a migration twice changes nothing. A
+// target naming another commit is reported as a Conflict.
+func (c BuildContext) PlanTagMigration() ([]TagMigration, error) {
+	refs, err := c.tagRefs()
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(c.Config.Prefix, "-")
+	byName := map[string]tagRef{}
+	byCommit := map[string][]string{}
+	var legacy []tagRef
+	for _, r := range refs {
+		byName[r.name] = r
+		if p, _, err := ParseLegacySemVer(r.name); err == nil {
+			if p == prefix {
+				legacy = append(legacy, r)
+			}
+		} else if c.inScheme(r.name, prefix) {
+			byCommit[r.commit] = append(byCommit[r.commit], r.name)
+		}
+	}
+	sort.SliceStable(legacy, func(i, j int) bool { return legacy[i].time.Before(legacy[j].time) })
+
+	ms := []TagMigration{}
+	if c.Config.SemVer {
+		for _, r := range legacy {
+			_, v, _ := ParseLegacySemVer(r.name)
+			ms = append(ms, TagMigration{From: r.name, To: addPrefix(v.String(), prefix), Commit: r.commit, Time: r.time})
+		}
+	} else if ms, err = c.calverMigrations(legacy, byCommit, prefix); err != nil {
+		return nil, err
+	}
+
+	for i := range ms {
+		m := &ms[i]
+		switch t, ok := byName[m.To]; {
+		case !ok:
+		case t.commit == m.Commit:
+			m.Migrated = true
+		default:
+			m.Conflict = fmt.Sprintf("%s already tags %s", c.tagName(m.To), shortSHA(t.commit))
+		}
+		m.From, m.To = c.tagName(m.From), c.tagName(m.To)
+	}
+	return ms, nil
+}
+
+// MigrateTags applies PlanTagMigration: it tags each commit with its new tag and pushes them to origin together. With
+// rewrite it then deletes the migrated legacy tags from origin and the local repository, so only one format remains;
+// without it the old tags stay as mirrors. Conflicts are skipped and reported in the returned plan. Under
+// Config.DryRun it only describes the changes.
+func (c BuildContext) MigrateTags(rewrite bool) ([]TagMigration, error) {
+	ms, err := c.PlanTagMigration()
+	if err != nil {
+		return nil, err
+	}
+	var push, drop []string
+	for _, m := range ms {
+		if m.Conflict != "" {
+			continue
+		}
+		drop = append(drop, "refs/tags/"+m.From)
+		if m.Migrated {
+			continue
+		}
+		err := c.effect("create tag "+m.To+" at "+shortSHA(m.Commit)+" (from "+m.From+")", func() error {
+			_, err := c.git("tag", "--end-of-options", m.To, m.Commit)
+			return err
+		})
+		if err != nil {
+			return ms, fmt.Errorf("migrate %s: %w", m.From, err)
+		}
+		push = append(push, "refs/tags/"+m.To)
+	}
+	if len(push) > 0 {
+		err := c.effect(fmt.Sprintf("push %d migrated tags to origin", len(push)), func() error {
+			_, err := c.git(append([]string{"push", "origin"}, push...)...)
+			return err
+		})
+		if err != nil {
+			return ms, fmt.Errorf("push migrated tags: %w", err)
+		}
+	}
+	if !rewrite || len(drop) == 0 {
+		return ms, nil
+	}
+	err = c.effect(fmt.Sprintf("delete %d legacy tags from origin and locally", len(drop)), func() error {
+		if _, err := c.git(append([]string{"push", "origin", "--delete"}, drop...)...); err != nil {
+			return err
+		}
+		for _, ref := range drop {
+			if _, err := c.git("update-ref", "-d", ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ms, fmt.Errorf("delete legacy tags: %w", err)
+	}
+	return ms, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+type tagRef struct {
+	name   string // without the namespace
+	commit string
+	time   time.Time
+}
+
+// tagRefs lists the tags of Config.Namespace with their peeled commits and creation times.
+func (c BuildContext) tagRefs() ([]tagRef, error) {
+	out, err := c.git("for-each-ref", "refs/tags",
+		"--format=%(refname:strip=2)%1f%(objectname)%1f%(*objectname)%1f%(creatordate:iso-strict)")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	var refs []tagRef
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		f := strings.Split(line, "\x1f")
+		if len(f) != 4 || strings.HasPrefix(f[0], RetractedPrefix) {
+			continue
+		}
+		ns := InNamespace(f[:1], c.Config.Namespace)
+		if len(ns) == 0 {
+			continue
+		}
+		r := tagRef{name: ns[0], commit: firstNonEmpty(f[2], f[1])}
+		r.time, _ = time.Parse(time.RFC3339, f[3])
+		refs = append(refs, r)
+	}
+	return refs, nil
+}
+
+// inScheme reports whether tag is a final CalVer or SemVer version of prefix, as the configured scheme writes them.
+func (c BuildContext) inScheme(tag, prefix string) bool {
+	if c.Config.SemVer {
+		p, _, err := ParseSemVer(tag)
+		return err == nil && p == prefix
+	}
+	v, err := Parse(tag)
+	return err == nil && IsFinal(tag) && v.Prefix == prefix && v.Epoch == c.Config.Epoch
+}
+
+// calverMigrations gives each MAJOR.MINOR line of legacy, oldest first, a default build: the one an earlier run
+// already tagged on one of its commits, or the next free build of the day the line started.
+func (c BuildContext) calverMigrations(legacy []tagRef, byCommit map[string][]string, prefix string) ([]TagMigration, error) {
+	loc := time.UTC
+	if c.Config.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(c.Config.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: timezone %q: %v", ErrInvalidConfig, c.Config.Timezone, err)
+		}
+	}
+	used := map[string]map[int]bool{} // builds per date
+	for _, names := range byCommit {
+		for _, n := range names {
+			v, _ := Parse(n)
+			if used[v.Date] == nil {
+				used[v.Date] = map[int]bool{}
+			}
+			used[v.Date][v.Build] = true
+		}
+	}
+
+	bases := map[SemVer]Version{} // keyed by MAJOR.MINOR
+	for _, r := range legacy {
+		_, s, _ := ParseLegacySemVer(r.name)
+		for _, n := range byCommit[r.commit] {
+			if v, _ := Parse(n); v.Patch == s.Patch {
+				bases[SemVer{s.Major, s.Minor, 0}] = v
+			}
+		}
+	}
+
+	ms := make([]TagMigration, 0, len(legacy))
+	for _, r := range legacy {
+		_, s, _ := ParseLegacySemVer(r.name)
+		line := SemVer{s.Major, s.Minor, 0}
+		base, ok := bases[line]
+		if !ok {
+			date := r.time.In(loc).Format("20060102")
+			n := 1
+			for used[date][n] {
+				n++
+			}
+			if used[date] == nil {
+				used[date] = map[int]bool{}
+			}
+			used[date][n] = true
+			base = Version{Prefix: prefix, Epoch: c.Config.Epoch, Date: date, Build: n, BuildWidth: c.Config.BuildWidth}
+			bases[line] = base
+		}
+		v := base
+		v.Patch, v.PatchWidth, v.Suffix, v.Commit = s.Patch, c.Config.PatchWidth, "", ""
+		ms = append(ms, TagMigration{From: r.name, To: v.String(), Commit: r.commit, Time: r.time})
+	}
+	return ms, nil
+}
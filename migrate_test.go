@@ -0,0 +1,85 @@
+package versioner
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	origin := gitRepo(t)
+	commit := func(date string, tags ...string) string {
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		mustGit(t, "", "commit", "-q", "--allow-empty", "-m", date)
+		for _, tag := range tags {
+			mustGit(t, "", "tag", tag)
+		}
+		return mustGit(t, "", "rev-parse", "HEAD")
+	}
+	commit("2024-01-05T10:00:00Z", "v1.2.0")
+	commit("2024-01-05T11:00:00Z", "20240105.1") // a build already numbered 1 that day
+	fix := commit("2024-01-07T09:00:00Z", "v1.2.1")
+	commit("2024-01-05T12:00:00Z", "v1.3.0", "api-v1.0.0")
+	mustGit(t, "", "push", "-q", "origin", "--tags")
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+
+	report := func(ms []TagMigration) string {
+		var s []string
+		for _, m := range ms {
+			s = append(s, fmt.Sprintf("%s>%s%s", m.From, m.To, map[bool]string{true: "!"}[m.Migrated]))
+		}
+		return strings.Join(s, " ")
+	}
+	ms, err := c.PlanTagMigration()
+	if want := "v1.2.0>20240105.2 v1.3.0>20240105.3 v1.2.1>20240105.2.1"; err != nil || report(ms) != want {
+		t.Fatalf("calver plan: got %s, %v want %s", report(ms), err, want)
+	}
+	if ms[2].Commit != fix {
+		t.Fatalf("commit: got %s want %s", ms[2].Commit, fix)
+	}
+	semver := ctx("main", Config{DefaultBranch: "main", SemVer: true, Prefix: "api"}, nil)
+	if ms, err := semver.PlanTagMigration(); err != nil || report(ms) != "api-v1.0.0>api-1.0.0" {
+		t.Fatalf("semver plan: got %s, %v", report(ms), err)
+	}
+
+	var out bytes.Buffer
+	dry := c
+	dry.Config.DryRun, dry.DryRunOut = true, &out
+	if _, err := dry.MigrateTags(true); err != nil || tagged("20240105.2") || !strings.Contains(out.String(), "would create tag 20240105.2 ") {
+		t.Fatalf("dry run: %v\n%s", err, out.String())
+	}
+
+	if _, err := c.MigrateTags(false); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustGit(t, "", "ls-remote", "--refs", origin, "refs/tags/20240105.2.1"); !strings.HasPrefix(got, fix) {
+		t.Fatalf("mirror not pushed: %q", got)
+	}
+	ms, _ = c.PlanTagMigration()
+	if want := "v1.2.0>20240105.2! v1.3.0>20240105.3! v1.2.1>20240105.2.1!"; report(ms) != want {
+		t.Fatalf("second plan: got %s want %s", report(ms), want)
+	}
+
+	if _, err := c.MigrateTags(true); err != nil {
+		t.Fatal(err)
+	}
+	if tagged("v1.2.0") || mustGit(t, "", "ls-remote", "--refs", origin, "refs/tags/v1.*") != "" || !tagged("api-v1.0.0") {
+		t.Fatal("rewrite left legacy tags behind or dropped another prefix")
+	}
+}
+
+func TestMigrateConflict(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "v2.0.0")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "other")
+	mustGit(t, "", "tag", "2.0.0")
+	c := ctx("main", Config{DefaultBranch: "main", SemVer: true}, nil)
+	ms, err := c.PlanTagMigration()
+	if err != nil || len(ms) != 1 || !strings.HasPrefix(ms[0].Conflict, "2.0.0 already tags ") {
+		t.Fatalf("got %+v, %v", ms, err)
+	}
+	if _, err := c.MigrateTags(true); err != nil || !tagged("v2.0.0") {
+		t.Fatalf("conflicting tag migrated: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+-- Versions recorded per project, with the full manifest for audits.
+CREATE TABLE IF NOT EXISTS versioner_versions (
+    seq         bigserial   NOT NULL,
+    project     text        NOT NULL,
+    version     text        NOT NULL,
+    commit_sha  text        NOT NULL DEFAULT '',
+    recorded_at timestamptz NOT NULL,
+    manifest    jsonb       NOT NULL,
+    PRIMARY KEY (project, version)
+);
+
+-- Highest patch handed out per release base, incremented transactionally by ReserveNext.
+CREATE TABLE IF NOT EXISTS versioner_patches (
+    project text    NOT NULL,
+    base    text    NOT NULL,
+    last    integer NOT NULL,
+    PRIMARY KEY (project, base)
+);
@@ -0,0 +1,2 @@
+-- Manifests() lists a project's versions in recording order.
+CREATE INDEX IF NOT EXISTS versioner_versions_project_seq ON versioner_versions (project, seq);
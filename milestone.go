@@ -0,0 +1,118 @@
+package versioner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// MilestoneByDate as Config.Milestone links the active milestone whose start and due dates span the release day.
+const MilestoneByDate = "@date"
+
+// Milestone is a GitLab project milestone.
+type Milestone struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	State     string `json:"state"`      // active or closed
+	StartDate string `json:"start_date"` // YYYY-MM-DD; empty when open-ended
+	DueDate   string `json:"due_date"`
+}
+
+// MilestoneTitle renders a Config.Milestone template for version released on day (YYYYMMDD): {version}, {prefix},
+// {base} (the default build a release descends from, else the version), {date}, {year}, {month} and {day}, so
+// "Sprint {year}-{month}" names a monthly milestone and "{base}" one per release line.
+func MilestoneTitle(template, version, day string) (string, error) {
+	if len(day) != 8 {
+		return "", fmt.Errorf("%w: day %q is not YYYYMMDD", ErrInvalidConfig, day)
+	}
+	base, prefix := version, ""
+	if v, err := Parse(version); err == nil {
+		base, prefix = v.Base(), v.Prefix
+	}
+	title := strings.NewReplacer(
+		"{version}", version, "{prefix}", prefix, "{base}", base,
+		"{date}", day, "{year}", day[:4], "{month}", day[4:6], "{day}", day[6:],
+	).Replace(template)
+	if p := placeholderRE.FindString(title); p != "" {
+		return "", fmt.Errorf("%w: milestone template %q: unknown placeholder %s", ErrInvalidConfig, template, p)
+	}
+	return title, nil
+}
+
+// Milestones lists the project's milestones.
+func (gl GitLab) Milestones(ctx context.Context) ([]Milestone, error) {
+	var ms []Milestone
+	return ms, gl.list(ctx, "milestones", &ms)
+}
+
+// CloseMilestone closes milestone id; closing a closed milestone succeeds.
+func (gl GitLab) CloseMilestone(ctx context.Context, id int) error {
+	resp, err := gl.do(ctx, http.MethodPut, "milestones/"+strconv.Itoa(id)+"?state_event=close", nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// FindMilestone picks the milestone of version per Config.Milestone: the one titled by the template (active ones
+// first) or, with MilestoneByDate, the active milestone spanning the release day that is due first. It returns
+// ok=false when none matches and when Config.Milestone is empty.
+func (c BuildContext) FindMilestone(ctx context.Context, gl GitLab, version string) (m Milestone, ok bool, err error) {
+	if c.Config.Milestone == "" {
+		return Milestone{}, false, nil
+	}
+	day, err := c.day()
+	if err != nil {
+		return Milestone{}, false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, orDefault(c.Config.Timeouts.API, DefaultAPITimeout))
+	defer cancel()
+	ms, err := gl.Milestones(ctx)
+	if err != nil {
+		return Milestone{}, false, err
+	}
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].State == "active" && ms[j].State != "active" })
+
+	if c.Config.Milestone == MilestoneByDate {
+		date := day[:4] + "-" + day[4:6] + "-" + day[6:]
+		for _, cand := range ms {
+			if cand.State != "active" || cand.StartDate == "" && cand.DueDate == "" ||
+				cand.StartDate > date || cand.DueDate != "" && cand.DueDate < date {
+				continue
+			}
+			if !ok || cand.DueDate != "" && (m.DueDate == "" || cand.DueDate < m.DueDate) {
+				m, ok = cand, true
+			}
+		}
+		return m, ok, nil
+	}
+	title, err := MilestoneTitle(c.Config.Milestone, version, day)
+	if err != nil {
+		return Milestone{}, false, err
+	}
+	for _, cand := range ms {
+		if cand.Title == title {
+			return cand, true, nil
+		}
+	}
+	return Milestone{}, false, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var placeholderRE = regexp.MustCompile(`\{[a-z]+\}`)
+
+// checkMilestone validates a Config.Milestone template without a version at hand.
+func checkMilestone(template string) error {
+	if template == "" || template == MilestoneByDate {
+		return nil
+	}
+	_, err := MilestoneTitle(template, "20250428.1", "20250428")
+	return err
+}
@@ -0,0 +1,80 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMilestoneTitle(t *testing.T) {
+	for _, tc := range []struct{ template, version, want string }{
+		{"Sprint {year}-{month}", "20250428.100.2", "Sprint 2025-04"},
+		{"{prefix} {base}", "api-20250401.100.2", "api 20250401.100"},
+		{"{version} on {date}", "1.2.3", "1.2.3 on 20250428"},
+	} {
+		if got, err := MilestoneTitle(tc.template, tc.version, "20250428"); err != nil || got != tc.want {
+			t.Fatalf("%s: got %q want %q (%v)", tc.template, got, tc.want, err)
+		}
+	}
+	if _, err := MilestoneTitle("Sprint {week}", "1.2.3", "20250428"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("unknown placeholder: got %v", err)
+	}
+	if err := (Config{DefaultBranch: "main", Milestone: "{sprint}"}).Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("validate: got %v", err)
+	}
+}
+
+func TestPublishMilestone(t *testing.T) {
+	var published map[string]any
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		switch r.Method + " " + r.URL.Path {
+		case "GET /projects/grp/app/milestones":
+			w.Write([]byte(`[
+				{"id":5,"title":"Sprint 2025-04","state":"closed","start_date":"2025-04-01","due_date":"2025-04-30"},
+				{"id":6,"title":"Q2","state":"active","start_date":"2025-04-01","due_date":"2025-06-30"},
+				{"id":7,"title":"Sprint 2025-04","state":"active","start_date":"2025-04-21","due_date":"2025-05-02"},
+				{"id":8,"title":"Backlog","state":"active"}]`))
+		case "POST /projects/grp/app/releases":
+			json.NewDecoder(r.Body).Decode(&published)
+		case "PUT /projects/grp/app/milestones/7":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	gl := GitLab{BaseURL: srv.URL, Project: "grp/app", Token: "tok"}
+	m := Manifest{Version: "20250428.100.1", Notes: "## fixes"}
+
+	for _, tc := range []struct {
+		milestone string
+		want      string
+	}{
+		{"Sprint {year}-{month}", "[Sprint 2025-04]"},
+		{MilestoneByDate, "[Sprint 2025-04]"}, // both active ones span the day; the sprint is due first
+		{"Release {base}", "<nil>"},
+	} {
+		published = nil
+		c := ctx("release/v20250428.100", Config{DefaultBranch: "main", Milestone: tc.milestone}, nil)
+		if err := c.PublishRelease(context.Background(), gl, m); err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(published["milestones"]); got != tc.want {
+			t.Fatalf("%s: got %s want %s", tc.milestone, got, tc.want)
+		}
+	}
+
+	calls = nil
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", Milestone: MilestoneByDate, CloseMilestone: true}, nil)
+	if err := c.PublishRelease(context.Background(), gl, m); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[GET /projects/grp%2Fapp/milestones?per_page=100&page=1 POST /projects/grp%2Fapp/releases PUT /projects/grp%2Fapp/milestones/7?state_event=close]"; fmt.Sprint(calls) != want {
+		t.Fatalf("got %v want %s", calls, want)
+	}
+}
@@ -0,0 +1,58 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// TagName is the tag of version in namespace: '<namespace>/<version>' ("cli/20250428.321.1"), or the version itself
+// without a namespace.
+func TagName(namespace, version string) string {
+	if namespace == "" {
+		return version
+	}
+	return namespace + "/" + version
+}
+
+// InNamespace returns the versions tagged in namespace, with the '<namespace>/' part removed; retraction markers
+// ("retracted/<namespace>/<version>") are kept as "retracted/<version>". An empty namespace selects the tags outside
+// any namespace, so products tagged under one never leak into the version stream of the repository's top-level
+// product, and vice versa.
+func InNamespace(tags []string, namespace string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		marker := ""
+		if rest, ok := strings.CutPrefix(t, RetractedPrefix); ok {
+			marker, t = RetractedPrefix, rest
+		}
+		if namespace != "" {
+			var ok bool
+			if t, ok = strings.CutPrefix(t, namespace+"/"); !ok {
+				continue
+			}
+		}
+		if !strings.Contains(t, "/") {
+			out = append(out, marker+t)
+		}
+	}
+	return out
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// checkNamespace accepts namespaces that form valid tag names in front of a version: no leading, trailing or doubled
+// slashes and nothing git rejects.
+func checkNamespace(ns string) error {
+	if ns == "" {
+		return nil
+	}
+	if strings.HasPrefix(ns, "/") || strings.HasSuffix(ns, "/") || strings.Contains(ns, "//") {
+		return fmt.Errorf("namespace %q has a leading, trailing or doubled '/'", ns)
+	}
+	if ns+"/" == RetractedPrefix || strings.HasPrefix(ns, RetractedPrefix) {
+		return fmt.Errorf("namespace %q is reserved for retraction markers", ns)
+	}
+	return CheckRefName(ns + "/0")
+}
@@ -0,0 +1,64 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestInNamespace(t *testing.T) {
+	tags := []string{"20250428.100", "cli/20250428.100", "cli/20250428.100.1", "cli/x/20250428.1", "svc/20250427.5",
+		"retracted/20250428.100", "retracted/cli/20250428.100.1", "cli/api-20250428.7"}
+	for _, tc := range []struct{ ns, want string }{
+		{"", "[20250428.100 retracted/20250428.100]"},
+		{"cli", "[20250428.100 20250428.100.1 retracted/20250428.100.1 api-20250428.7]"},
+		{"cli/x", "[20250428.1]"},
+	} {
+		if got := fmt.Sprint(InNamespace(tags, tc.ns)); got != tc.want {
+			t.Fatalf("%q: got %s want %s", tc.ns, got, tc.want)
+		}
+	}
+}
+
+func TestNamespaceVersion(t *testing.T) {
+	tags := []string{"20250428.100", "20250428.100.1", "20250428.100.2", "cli/20250428.100", "cli/20250428.100.1"}
+	got, err := ctx("release/v20250428.100", Config{DefaultBranch: "main", Namespace: "cli"}, tags).Version()
+	if err != nil || got != "20250428.100.2" {
+		t.Fatalf("got %s, %v want 20250428.100.2", got, err)
+	}
+	got, _ = ctx("release/v20250428.100", Config{DefaultBranch: "main"}, tags).Version()
+	if got != "20250428.100.3" {
+		t.Fatalf("got %s want 20250428.100.3", got)
+	}
+
+	for _, ns := range []string{"/cli", "cli/", "a//b", "retracted", "cli..x"} {
+		if _, err := ctx("main", Config{DefaultBranch: "main", Namespace: ns}, nil).Version(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%q: got %v want %v", ns, err, ErrInvalidConfig)
+		}
+	}
+}
+
+func TestNamespaceTagAndPush(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, "", "tag", "20250428.100")
+	mustGit(t, "", "tag", "20250428.100.1")
+	mustGit(t, "", "tag", "cli/20250428.100")
+
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", Namespace: "cli"}, nil)
+	c.LookupTags = GitTags
+	m, err := c.TagAndPush()
+	if err != nil || m.Version != "20250428.100.1" || m.Namespace != "cli" {
+		t.Fatalf("got %+v, %v want 20250428.100.1 in cli", m, err)
+	}
+	if got := mustGit(t, "", "ls-remote", "--tags", origin, "cli/20250428.100.1"); got == "" {
+		t.Fatal("namespaced tag was not pushed")
+	}
+
+	h, err := Latest(HistoryOptions{Namespace: "cli"})
+	if err != nil || h.Version.String() != "20250428.100.1" {
+		t.Fatalf("got %v, %v want 20250428.100.1", h.Version, err)
+	}
+	if h, _ := Latest(HistoryOptions{}); h.Version.String() != "20250428.100.1" {
+		t.Fatalf("top-level latest: got %v", h.Version)
+	}
+}
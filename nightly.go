@@ -0,0 +1,151 @@
+package versioner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Nightly is the version of a scheduled pipeline under Config.Nightly: '[<prefix>-]YYYYMMDD-nightly.<n>', the n-th
+// nightly of the day. Nightlies do not parse as a Version, so unlike builds on ChannelNightly they stay out of the
+// default-branch and release streams, Monotonic, History and the alias tags.
+type Nightly struct {
+	Prefix string // without the trailing '-'
+	Date   string // YYYYMMDD
+	N      int    // 1, 2, … per day
+	Commit string // short SHA build metadata, without the leading '+'
+}
+
+// ParseNightly splits a nightly version into its components.
+func ParseNightly(s string) (Nightly, error) {
+	m := nightlyRE.FindStringSubmatch(s)
+	if m == nil {
+		return Nightly{}, fmt.Errorf("%w: %q is not YYYYMMDD-nightly.<n>", ErrInvalidVersion, s)
+	}
+	n := Nightly{Prefix: m[1], Date: m[2], Commit: m[4]}
+	n.N, _ = strconv.Atoi(m[3])
+	return n, nil
+}
+
+func (n Nightly) String() string {
+	s := addPrefix(n.Date+"-nightly."+strconv.Itoa(n.N), n.Prefix)
+	if n.Commit != "" {
+		s += "+" + n.Commit
+	}
+	return s
+}
+
+// NightlyPolicy is the pruning hook run after a nightly is tagged: given the tagged nightlies of the prefix, newest
+// first, and the build time in Config.Timezone, it returns the ones to delete.
+type NightlyPolicy func(nightlies []Nightly, now time.Time) []Nightly
+
+// KeepNightlyDays keeps the nightlies of the last days calendar days, today included, and prunes older ones.
+func KeepNightlyDays(days int) NightlyPolicy {
+	return func(ns []Nightly, now time.Time) []Nightly {
+		cutoff := now.AddDate(0, 0, 1-days).Format("20060102")
+		var prune []Nightly
+		for _, n := range ns {
+			if n.Date < cutoff {
+				prune = append(prune, n)
+			}
+		}
+		return prune
+	}
+}
+
+// PruneNightlies deletes the nightly tags of Config.Prefix that policy selects from origin and the local repository
+// and returns their names. Under Config.DryRun it only describes the deletion.
+func (c BuildContext) PruneNightlies(policy NightlyPolicy) ([]string, error) {
+	c = c.pinTime()
+	ts, err := c.tags()
+	if err != nil {
+		return nil, err
+	}
+	now, err := c.localTime()
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(c.Config.Prefix, "-")
+	var ns []Nightly
+	names := map[Nightly]string{}
+	for _, t := range ts {
+		if n, err := ParseNightly(t); err == nil && n.Prefix == prefix {
+			ns = append(ns, n)
+			names[n] = t
+		}
+	}
+	sort.Slice(ns, func(i, j int) bool {
+		if ns[i].Date != ns[j].Date {
+			return ns[i].Date > ns[j].Date
+		}
+		return ns[i].N > ns[j].N
+	})
+
+	var pruned, refs []string
+	for _, n := range policy(ns, now) {
+		if t, ok := names[n]; ok {
+			pruned = append(pruned, c.tagName(t))
+			refs = append(refs, "refs/tags/"+c.tagName(t))
+		}
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	err = c.effect(fmt.Sprintf("delete %d nightly tags from origin and locally", len(refs)), func() error {
+		if _, err := c.git(append([]string{"push", "origin", "--delete"}, refs...)...); err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			if _, err := c.git("update-ref", "-d", ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("prune nightlies: %w", err)
+	}
+	c.debug("nightlies pruned", "tags", strings.Join(pruned, " "))
+	return pruned, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var nightlyRE = regexp.MustCompile(`^(?:([^.!]+?)-)?(\d{8})-nightly\.(\d+)(?:\+([0-9a-f]+))?$`)
+
+// isNightly reports whether c builds a nightly: a scheduled pipeline with Config.Nightly.
+func (c BuildContext) isNightly() bool {
+	return c.Config.Nightly && c.PipelineSource == "schedule"
+}
+
+// nightly is the next nightly of day: one past the highest tagged for the prefix.
+func (c BuildContext) nightly(day string) (string, error) {
+	ts, err := c.tags()
+	if err != nil {
+		return "", err
+	}
+	n := Nightly{Prefix: strings.TrimSuffix(c.Config.Prefix, "-"), Date: day, N: 1}
+	for _, t := range ts {
+		if tn, err := ParseNightly(t); err == nil && tn.Prefix == n.Prefix && tn.Date == day && tn.N >= n.N {
+			n.N = tn.N + 1
+		}
+	}
+	c.debug("nightly numbered", "day", day, "n", n.N)
+	return n.String(), nil
+}
+
+// nightlyPolicy is PruneNightly or, with Config.NightlyKeepDays set, KeepNightlyDays; nil keeps every nightly.
+func (c BuildContext) nightlyPolicy() NightlyPolicy {
+	switch {
+	case c.PruneNightly != nil:
+		return c.PruneNightly
+	case c.Config.NightlyKeepDays > 0:
+		return KeepNightlyDays(c.Config.NightlyKeepDays)
+	}
+	return nil
+}
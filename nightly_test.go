@@ -0,0 +1,72 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNightly(t *testing.T) {
+	tags := []string{"20250428-nightly.1", "20250428-nightly.2", "api-20250428-nightly.7", "20250427-nightly.5", "20250428.300"}
+	for _, tc := range []struct {
+		branch, source string
+		cfg            Config
+		want           string
+	}{
+		{"main", "schedule", Config{Nightly: true}, "20250428-nightly.3"},
+		{"feature/x", "schedule", Config{Nightly: true, Monotonic: true, NoCollisions: true}, "20250428-nightly.3"},
+		{"main", "schedule", Config{Nightly: true, Prefix: "api"}, "api-20250428-nightly.8"},
+		{"main", "schedule", Config{Nightly: true, Prefix: "web"}, "web-20250428-nightly.1"},
+		{"main", "push", Config{Nightly: true}, "20250428.321"},
+		{"main", "schedule", Config{}, "20250428.321"},
+	} {
+		tc.cfg.DefaultBranch = "main"
+		c := ctx(tc.branch, tc.cfg, tags)
+		c.PipelineSource = tc.source
+		if got, err := c.Version(); err != nil || got != tc.want {
+			t.Fatalf("%s %s %+v: got %s want %s (%v)", tc.branch, tc.source, tc.cfg, got, tc.want, err)
+		}
+	}
+
+	c := ctx("main", Config{DefaultBranch: "main", Nightly: true}, tags)
+	c.PipelineSource = "schedule"
+	if bi, err := c.BuildInfo(); err != nil || bi.Kind != "nightly" || bi.Date != "20250428" || bi.Build != 3 {
+		t.Fatalf("build info: got %+v, %v", bi, err)
+	}
+	if n, err := ParseNightly("api-20250428-nightly.12+abc123"); err != nil || n != (Nightly{"api", "20250428", 12, "abc123"}) {
+		t.Fatalf("parse: got %+v, %v", n, err)
+	}
+	if _, err := Parse("20250428-nightly.1"); err == nil {
+		t.Fatal("a nightly parses as a Version")
+	}
+	if err := (Config{DefaultBranch: "main", Nightly: true, SemVer: true}).Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("nightly semver: got %v", err)
+	}
+}
+
+func TestPruneNightlies(t *testing.T) {
+	gitRepo(t)
+	for _, tag := range []string{"20250425-nightly.1", "20250427-nightly.1", "20250427-nightly.2", "api-20250420-nightly.1", "20250420.9"} {
+		mustGit(t, "", "tag", tag)
+	}
+	mustGit(t, "", "push", "-q", "origin", "--tags")
+	c := ctx("main", Config{DefaultBranch: "main", Nightly: true}, nil)
+	c.LookupTags = GitTags
+	c.PipelineSource = "schedule"
+
+	var seen []Nightly
+	c.PruneNightly = func(ns []Nightly, now time.Time) []Nightly {
+		seen = ns
+		return KeepNightlyDays(2)(ns, now)
+	}
+	if _, err := c.TagAndPush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[20250428-nightly.1 20250427-nightly.2 20250427-nightly.1 20250425-nightly.1]"; fmt.Sprint(seen) != want {
+		t.Fatalf("policy input: got %v want %s", seen, want)
+	}
+	if tagged("20250425-nightly.1") || !tagged("20250427-nightly.1") || !tagged("api-20250420-nightly.1") || !tagged("20250420.9") {
+		t.Fatal("pruned the wrong tags")
+	}
+}
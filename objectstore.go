@@ -0,0 +1,83 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// ObjectStore is the slice of an object-storage API the ObjectLedger needs: whole-object reads returning a revision
+// token (ETag, generation) and writes conditional on it.
+type ObjectStore interface {
+	// Get returns the object and its revision; a missing object is ErrObjectNotFound.
+	Get(ctx context.Context, key string) (data []byte, rev string, err error)
+	// Put writes the object only if its revision is still rev ("" meaning it must not exist yet), failing with
+	// ErrPreconditionFailed otherwise.
+	Put(ctx context.Context, key string, data []byte, rev string) error
+}
+
+var (
+	ErrObjectNotFound     = errors.New("object not found")
+	ErrPreconditionFailed = errors.New("object changed concurrently")
+)
+
+// ObjectLedger is a Ledger stored as one StateFile-format JSON object per project in shared object storage (S3,
+// GCS), the source of truth for many repositories at once. Every Record is a compare-and-swap on the object's
+// revision, so two pipelines can never both record the same version: the loser gets ErrVersionExists and, through
+// Claim, recomputes the next patch.
+type ObjectLedger struct {
+	Store ObjectStore
+	Key   string // object name, e.g. "versions/grp/app.json"
+}
+
+func (l ObjectLedger) Record(m Manifest) error {
+	ctx := context.Background()
+	for attempt := 0; ; attempt++ {
+		st, rev, err := l.read(ctx)
+		if err != nil {
+			return err
+		}
+		for _, o := range st.Versions {
+			if o.Version == m.Version {
+				return fmt.Errorf("%w: %s in %s", ErrVersionExists, m.Version, l.Key)
+			}
+		}
+		st.Versions = append(st.Versions, m)
+		b, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = l.Store.Put(ctx, l.Key, b, rev)
+		if !errors.Is(err, ErrPreconditionFailed) || attempt >= maxCASAttempts {
+			return err
+		}
+	}
+}
+
+func (l ObjectLedger) Manifests() ([]Manifest, error) {
+	st, _, err := l.read(context.Background())
+	return st.Versions, err
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// maxCASAttempts bounds the re-reads after lost write races; each retry makes progress for some writer.
+const maxCASAttempts = 10
+
+func (l ObjectLedger) read(ctx context.Context) (state, string, error) {
+	var st state
+	b, rev, err := l.Store.Get(ctx, l.Key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return st, "", nil
+	}
+	if err != nil {
+		return st, "", err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, "", fmt.Errorf("%s: %w", l.Key, err)
+	}
+	return st, rev, nil
+}
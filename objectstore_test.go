@@ -0,0 +1,140 @@
+package versioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory ObjectStore with integer revisions.
+type memStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	rev  map[string]int
+}
+
+func (s *memStore) Get(_ context.Context, key string) ([]byte, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.data[key]
+	if !ok {
+		return nil, "", ErrObjectNotFound
+	}
+	return b, strconv.Itoa(s.rev[key]), nil
+}
+
+func (s *memStore) Put(_ context.Context, key string, data []byte, rev string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur := ""
+	if _, ok := s.data[key]; ok {
+		cur = strconv.Itoa(s.rev[key])
+	}
+	if cur != rev {
+		return ErrPreconditionFailed
+	}
+	s.data[key], s.rev[key] = data, s.rev[key]+1
+	return nil
+}
+
+func TestObjectLedgerConcurrentClaims(t *testing.T) {
+	l := ObjectLedger{Store: &memStore{data: map[string][]byte{}, rev: map[string]int{}}, Key: "grp/app.json"}
+	const n = 8
+	var wg sync.WaitGroup
+	got := make([]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := ctx("release/v20250428.100", Config{DefaultBranch: "main", PushRetries: 3 * n}, nil)
+			c.LookupTags, c.Ledger = LedgerTags(l), l
+			c.CommitSHA = fmt.Sprintf("%040x", i)
+			m, err := c.Claim()
+			if err != nil {
+				t.Error(err)
+			}
+			got[i] = m.Version
+		}(i)
+	}
+	wg.Wait()
+	seen := map[string]bool{}
+	for _, v := range got {
+		if seen[v] {
+			t.Fatalf("version %s claimed twice: %v", v, got)
+		}
+		seen[v] = true
+	}
+	if ms, _ := l.Manifests(); len(ms) != n {
+		t.Fatalf("got %d manifests", len(ms))
+	}
+}
+
+func TestGCSConditionalWrite(t *testing.T) {
+	var gen int
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && gen == 0:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet:
+			w.Header().Set("X-Goog-Generation", strconv.Itoa(gen))
+			io.WriteString(w, body)
+		case r.URL.Query().Get("ifGenerationMatch") != strconv.Itoa(gen):
+			w.WriteHeader(http.StatusPreconditionFailed)
+		default:
+			b, _ := io.ReadAll(r.Body)
+			body, gen = string(b), gen+1
+		}
+	}))
+	defer srv.Close()
+
+	g := GCS{Bucket: "b", Token: "tok", BaseURL: srv.URL}
+	l := ObjectLedger{Store: g, Key: "grp/app.json"}
+	if err := l.Record(Manifest{Version: "20250428.100.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Put(context.Background(), "grp/app.json", []byte("{}"), ""); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("stale write: got %v", err)
+	}
+	if ms, err := l.Manifests(); err != nil || len(ms) != 1 || ms[0].Version != "20250428.100.1" {
+		t.Fatalf("got %+v, %v", ms, err)
+	}
+}
+
+func TestS3ConditionalWrite(t *testing.T) {
+	var hdr http.Header
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hdr, path = r.Header, r.URL.EscapedPath()
+		w.WriteHeader(http.StatusPreconditionFailed)
+	}))
+	defer srv.Close()
+
+	s := S3{Bucket: "b", Region: "eu-west-1", AccessKey: "AKID", SecretKey: "secret", Endpoint: srv.URL,
+		Clock: ClockFunc(func() time.Time { return time.Date(2025, 4, 28, 15, 0, 0, 0, time.UTC) })}
+	err := s.Put(context.Background(), "grp/my app.json", []byte("{}"), `"etag1"`)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("got %v", err)
+	}
+	auth := hdr.Get("Authorization")
+	if hdr.Get("If-Match") != `"etag1"` || path != "/b/grp/my%20app.json" ||
+		!strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20250428/eu-west-1/s3/aws4_request, SignedHeaders=host;if-match;") {
+		t.Fatalf("unexpected request %s %v", path, hdr)
+	}
+	s.Put(context.Background(), "k", nil, "")
+	if hdr.Get("If-None-Match") != "*" {
+		t.Fatalf("create must use If-None-Match: got %v", hdr)
+	}
+}
@@ -0,0 +1,64 @@
+package versioner
+
+import "log/slog"
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// DefaultBranch is the default branch New assumes until WithConfig names another one.
+const DefaultBranch = "main"
+
+// Option configures the BuildContext built by New.
+type Option func(*BuildContext)
+
+// New returns the BuildContext of branch with usable defaults: SystemClock, tags from GitTags and
+// DefaultBranch as the default branch. Options apply in order, so a later one overrides an earlier one. Setting
+// BuildContext fields directly keeps working; New is the path that stays stable as fields are added.
+func New(branch string, opts ...Option) BuildContext {
+	c := BuildContext{
+		Branch:     branch,
+		Clock:      SystemClock,
+		Config:     Config{DefaultBranch: DefaultBranch},
+		LookupTags: GitTags,
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
+
+// WithConfig replaces the Config; an empty DefaultBranch keeps the previous one, so a partial Config never
+// classifies an empty branch as the default branch.
+func WithConfig(cfg Config) Option {
+	return func(c *BuildContext) {
+		if cfg.DefaultBranch == "" {
+			cfg.DefaultBranch = c.Config.DefaultBranch
+		}
+		c.Config = cfg
+	}
+}
+
+// WithPipelineID sets the pipeline number used as the build; without it, LookupBuild numbers the build.
+func WithPipelineID(id string) Option {
+	return func(c *BuildContext) { c.PipelineID = id }
+}
+
+// WithCommit sets the commit recorded in manifests.
+func WithCommit(sha string) Option {
+	return func(c *BuildContext) { c.CommitSHA = sha }
+}
+
+// WithClock sets the Clock every operation reads its time from, e.g. a fixed clock in tests.
+func WithClock(clock Clock) Option {
+	return func(c *BuildContext) { c.Clock = clock }
+}
+
+// WithTagSource replaces GitTags, e.g. with RemoteTags, LedgerTags or a TagCache's Tags method. A nil source means
+// no tags.
+func WithTagSource(lookup func() ([]string, error)) Option {
+	return func(c *BuildContext) { c.LookupTags = lookup }
+}
+
+// WithLogger sets the debug logger.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *BuildContext) { c.Logger = l }
+}
@@ -0,0 +1,27 @@
+package versioner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	c := New("main",
+		WithConfig(Config{Prefix: "api"}),
+		WithPipelineID("321"),
+		WithClock(ClockFunc(func() time.Time { return now })),
+		WithTagSource(func() ([]string, error) { return []string{"api-20250428.100"}, nil }),
+	)
+	if c.Config.DefaultBranch != DefaultBranch {
+		t.Fatalf("got %q want %q", c.Config.DefaultBranch, DefaultBranch)
+	}
+	got, err := c.Version()
+	if want := "api-20250428.321"; err != nil || got != want {
+		t.Fatalf("got %s, %v want %s", got, err, want)
+	}
+
+	// zero options: the real clock and git tags, but never an empty default branch
+	if c := New(""); Classify(c.Config, c.Branch) == KindDefault || c.Clock == nil || c.LookupTags == nil {
+		t.Fatalf("unsafe defaults: %+v", c)
+	}
+}
@@ -0,0 +1,216 @@
+package versioner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Version is the parsed form of a string produced by BuildContext.Version.
+type Version struct {
+	Prefix string // without the trailing '-'
+	Epoch  int    // scheme generation, written "<n>!" before the date; bumped to make a clean break, 0 omits it
+	Date   string // YYYYMMDD
+	Build  int    // pipeline ID, or base build on release branches
+	Patch  int    // release patch; 0 on default and feature builds
+
+	BuildWidth int    // zero-padded width of Build ("000321" → 6); 0 when written unpadded
+	PatchWidth int    // likewise for Patch
+	Suffix     string // without the leading '-'
+	Commit     string // short SHA build metadata, without the leading '+'; ignored by Compare
+}
+
+// Parse splits a version string into its components.
+func Parse(s string) (Version, error) {
+	m := versionRE.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("%w: %s", ErrInvalidVersion, s)
+	}
+	v := Version{Prefix: m[1], Date: m[3], Suffix: m[6], Commit: m[7]}
+	v.Epoch, _ = strconv.Atoi(m[2])
+	v.Build, _ = strconv.Atoi(m[4])
+	v.BuildWidth = paddedWidth(m[4])
+	if m[5] != "" {
+		v.Patch, _ = strconv.Atoi(m[5])
+		v.PatchWidth = paddedWidth(m[5])
+	}
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := epochMark(v.Epoch) + v.Base()
+	if v.Patch > 0 {
+		s += "." + pad(strconv.Itoa(v.Patch), v.PatchWidth)
+	}
+	if v.Suffix != "" {
+		s += "-" + v.Suffix
+	}
+	if v.Commit != "" {
+		s += "+" + v.Commit
+	}
+	return addPrefix(s, v.Prefix)
+}
+
+// Base is "YYYYMMDD.<build>", the default-branch build a version belongs to and the name of its release branch
+// (release/v<Base>).
+func (v Version) Base() string {
+	return v.Date + "." + pad(strconv.Itoa(v.Build), v.BuildWidth)
+}
+
+// Compare orders versions by epoch, then numerically by date, build and patch; on a tie an unsuffixed version sorts
+// after a suffixed one, and suffixes follow SemVer pre-release precedence ("rc.9" < "rc.10"). Prefixes and commit
+// metadata are ignored. The result is -1, 0 or +1.
+func Compare(a, b Version) int {
+	switch {
+	case a.Epoch < b.Epoch:
+		return -1
+	case a.Epoch > b.Epoch:
+		return 1
+	}
+	if c := strings.Compare(a.Date, b.Date); c != 0 {
+		return c
+	}
+	for _, d := range [][2]int{{a.Build, b.Build}, {a.Patch, b.Patch}} {
+		switch {
+		case d[0] < d[1]:
+			return -1
+		case d[0] > d[1]:
+			return 1
+		}
+	}
+	switch {
+	case a.Suffix == b.Suffix:
+		return 0
+	case a.Suffix == "":
+		return 1
+	case b.Suffix == "":
+		return -1
+	}
+	return comparePre(a.Suffix, b.Suffix)
+}
+
+// CompareTotal is Compare made total for sorting: versions Compare calls equal are ordered by prefix, then commit
+// metadata, then padding, so only identical strings compare 0.
+func CompareTotal(a, b Version) int {
+	if c := Compare(a, b); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Prefix, b.Prefix); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Commit, b.Commit); c != 0 {
+		return c
+	}
+	return strings.Compare(a.String(), b.String())
+}
+
+// MonotonicityError is returned when Config.Monotonic is set and the computed version does not sort after the latest
+// existing tag of the same stream, e.g. after clock skew or a pipeline ID reset.
+type MonotonicityError struct {
+	Version string
+	Latest  string
+}
+
+func (e *MonotonicityError) Error() string {
+	return fmt.Sprintf("version %s is not greater than existing tag %s", e.Version, e.Latest)
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var versionRE = regexp.MustCompile(`^(?:([^.!]+?)-)?(?:(\d+)!)?(\d{8})\.(\d+)(?:\.(\d+))?(?:-([^+]+))?(?:\+([0-9a-f]+))?$`)
+
+// comparePre compares suffixes like SemVer pre-releases: dot-separated identifiers left to right, numeric ones
+// numerically and below alphanumeric ones, a longer list winning a common prefix.
+func comparePre(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, xerr := strconv.ParseUint(as[i], 10, 64)
+		y, yerr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case xerr == nil && yerr == nil && x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		case xerr == nil && yerr != nil:
+			return -1
+		case xerr != nil && yerr == nil:
+			return 1
+		case xerr != nil:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// paddedWidth is the width of a zero-padded number, or 0 for a plain one.
+func paddedWidth(digits string) int {
+	if len(digits) > 1 && digits[0] == '0' {
+		return len(digits)
+	}
+	return 0
+}
+
+// pad left-pads the decimal digits s with zeros to width w.
+func pad(s string, w int) string {
+	if len(s) >= w {
+		return s
+	}
+	return strings.Repeat("0", w-len(s)) + s
+}
+
+func epochMark(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n) + "!"
+}
+
+// checkMonotonic compares v with the tags of its own stream: patches of the same base on release branches, release
+// candidates of the same base for a candidate, unpatched unsuffixed tags otherwise.
+func checkMonotonic(v string, tags []string) (latestTag string, err error) {
+	cur, err := Parse(v)
+	if err != nil {
+		return "", err
+	}
+	candidate := cur.Patch == 0 && rcRE.MatchString(cur.Suffix)
+	var latest *Version
+	for _, t := range tags {
+		tv, err := Parse(t)
+		if err != nil || tv.Prefix != cur.Prefix {
+			continue
+		}
+		sameBase := tv.Epoch == cur.Epoch && tv.Date == cur.Date && tv.Build == cur.Build
+		switch {
+		case candidate:
+			if !sameBase || tv.Patch > 0 || !rcRE.MatchString(tv.Suffix) {
+				continue
+			}
+		case tv.Suffix != "":
+			continue
+		case cur.Patch > 0 && !sameBase, cur.Patch == 0 && tv.Patch > 0:
+			continue
+		}
+		if latest == nil || Compare(tv, *latest) > 0 {
+			latest = &tv
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	if Compare(cur, *latest) <= 0 {
+		return latest.String(), &MonotonicityError{Version: v, Latest: latest.String()}
+	}
+	return latest.String(), nil
+}
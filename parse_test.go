@@ -0,0 +1,86 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, s := range []string{"20250428.321", "cli-20250428.321-SNAPSHOT", "my-cli-20250428.100.2", "20250428.7-feat", "20250428.7-feat+0a1b2c3d"} {
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if v.String() != s {
+			t.Fatalf("got %s want %s", v, s)
+		}
+	}
+	if _, err := Parse("v1.2.3"); err == nil {
+		t.Fatal("expected error for foreign scheme")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"20250428.9", "20250428.10", -1},
+		{"20250429.1", "20250428.900", 1},
+		{"20250428.100.2", "20250428.100.10", -1},
+		{"20250428.100", "20250428.100.1", -1},
+		{"20250428.100-SNAPSHOT", "20250428.100", -1},
+		{"cli-20250428.100", "20250428.100", 0},
+		{"20250428.100+0a1b2c3d", "20250428.100", 0},
+	}
+	for _, c := range cases {
+		a, _ := Parse(c.a)
+		b, _ := Parse(c.b)
+		if got := Compare(a, b); got != c.want {
+			t.Fatalf("Compare(%s, %s) = %d want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestMonotonicGuard(t *testing.T) {
+	tags := []string{"20250428.400", "20250428.100.3"}
+	_, err := ctx("main", Config{DefaultBranch: "main", Monotonic: true}, tags).Version()
+	var me *MonotonicityError
+	if !errors.As(err, &me) || me.Latest != "20250428.400" {
+		t.Fatalf("expected MonotonicityError against 20250428.400, got %v", err)
+	}
+
+	got, err := ctx("release/v20250428.100", Config{DefaultBranch: "main", Monotonic: true}, tags).Version()
+	if err != nil || got != "20250428.100.4" {
+		t.Fatalf("got %s, %v want 20250428.100.4", got, err)
+	}
+}
+
+func TestMonotonicCandidates(t *testing.T) {
+	tags := []string{"20250428.100", "20250428.100-rc.1"}
+	cfg := Config{DefaultBranch: "main", Monotonic: true, Candidates: true}
+	got, err := ctx("release/v20250428.100", cfg, tags).Version()
+	if err != nil || got != "20250428.100-rc.2" {
+		t.Fatalf("got %s, %v want 20250428.100-rc.2", got, err)
+	}
+
+	var me *MonotonicityError
+	if _, err := checkMonotonic("20250428.100-rc.2", append(tags, "20250428.100-rc.3")); !errors.As(err, &me) ||
+		me.Latest != "20250428.100-rc.3" {
+		t.Fatalf("expected MonotonicityError against 20250428.100-rc.3, got %v", err)
+	}
+}
+
+func TestEpoch(t *testing.T) {
+	v, err := Parse("app-1!20240101.5.2")
+	if err != nil || v.Prefix != "app" || v.Epoch != 1 || v.Date != "20240101" || v.Patch != 2 {
+		t.Fatalf("got %+v, %v", v, err)
+	}
+	if v.String() != "app-1!20240101.5.2" {
+		t.Fatalf("round trip got %s", v)
+	}
+	old, _ := Parse("20991231.900")
+	if Compare(v, old) <= 0 {
+		t.Fatal("a higher epoch must outrank any date")
+	}
+}
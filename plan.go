@@ -0,0 +1,89 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+)
+
+// Plan previews the versions the next builds would produce.
+type Plan struct {
+	Default       string `json:"default"`                  // next default-branch build
+	ReleaseBranch string `json:"release_branch,omitempty"` // Branch if it is a release branch, else the one the latest default build would be cut to
+	Release       string `json:"release,omitempty"`        // next patch on ReleaseBranch
+	Feature       string `json:"feature"`                  // next build of Branch, or of a generic feature branch
+}
+
+// PlanPipeline stands in for the pipeline ID when BuildContext.PipelineID is unknown.
+const PlanPipeline = "<pipeline>"
+
+// Plan reports what the next default, release and feature builds would produce given the current tags, so release
+// managers can check before cutting a branch. Nothing is tagged or recorded.
+func (c BuildContext) Plan() (Plan, error) {
+	c = c.pinTime().memoizeTags()
+	if c.PipelineID == "" {
+		c.PipelineID = PlanPipeline
+		c.Config.Monotonic = false // the placeholder cannot be ordered
+	}
+	c.Config.Reruns = false    // a preview is never a re-run
+	c.Config.ForceVersion = "" // nor an emergency override
+	kind := Classify(c.Config, c.Branch)
+
+	var p Plan
+	var err error
+	at := func(branch string) (string, error) {
+		cc := c
+		cc.Branch = branch
+		return cc.Version()
+	}
+
+	if p.Default, err = at(c.Config.DefaultBranch); err != nil {
+		return Plan{}, err
+	}
+
+	switch kind {
+	case KindRelease:
+		p.ReleaseBranch = c.Branch
+	default:
+		ts, err := c.tags()
+		if err != nil {
+			return Plan{}, err
+		}
+		if latest, ok := latestDefault(ts, c.Config.Prefix); ok {
+			p.ReleaseBranch = "release/v" + latest.Base()
+		}
+	}
+	if p.ReleaseBranch != "" {
+		if p.Release, err = at(p.ReleaseBranch); err != nil {
+			return Plan{}, err
+		}
+	}
+
+	feature := c.Branch
+	if kind != KindFeature {
+		feature = "feature"
+	}
+	switch p.Feature, err = at(feature); {
+	case errors.Is(err, ErrUnknownBranch), errors.Is(err, ErrNoVersion):
+		p.Feature = "" // strict mode versions no such branch
+	case err != nil:
+		return Plan{}, err
+	}
+	return p, nil
+}
+
+// latestDefault is the newest default-branch tag (no patch, no suffix) of the prefix's stream that is not retracted.
+func latestDefault(tags []string, prefix string) (Version, bool) {
+	var latest Version
+	found := false
+	retracted := Retracted(tags)
+	for _, t := range tags {
+		v, err := Parse(t)
+		if err != nil || v.Patch > 0 || v.Suffix != "" || v.Prefix != strings.TrimSuffix(prefix, "-") || retracted[t] {
+			continue
+		}
+		if !found || Compare(v, latest) > 0 {
+			latest, found = v, true
+		}
+	}
+	return latest, found
+}
@@ -0,0 +1,33 @@
+package versioner
+
+import "testing"
+
+func TestPlanFromDefaultBranch(t *testing.T) {
+	tags := []string{"20250420.90", "20250427.300", "20250427.300.1", "20250428.5-SNAPSHOT"}
+	c := ctx("main", Config{DefaultBranch: "main", FeatureSuffix: "SNAPSHOT"}, tags)
+	c.PipelineID = ""
+	p, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Plan{
+		Default:       "20250428.<pipeline>",
+		ReleaseBranch: "release/v20250427.300",
+		Release:       "20250427.300.2",
+		Feature:       "20250428.<pipeline>-SNAPSHOT",
+	}
+	if p != want {
+		t.Fatalf("got %+v want %+v", p, want)
+	}
+}
+
+func TestPlanFromReleaseBranch(t *testing.T) {
+	c := ctx("release/v20250401.10", Config{DefaultBranch: "main"}, []string{"20250401.10.3"})
+	p, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ReleaseBranch != "release/v20250401.10" || p.Release != "20250401.10.4" || p.Default != "20250428.321" {
+		t.Fatalf("unexpected plan %+v", p)
+	}
+}
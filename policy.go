@@ -0,0 +1,135 @@
+package versioner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Policy vets a computed version against the naming rules of wherever it is used: it returns the version, possibly
+// rewritten, or a *PolicyError vetoing it. Version runs Config.Policies and then BuildContext.Policies, each on the
+// previous one's output; a Config.ForceVersion is used as given.
+type Policy interface {
+	Apply(version string) (string, error)
+}
+
+// PolicyFunc adapts a function to Policy.
+type PolicyFunc func(version string) (string, error)
+
+func (f PolicyFunc) Apply(version string) (string, error) { return f(version) }
+
+// Built-in policies, by their Config.Policies name.
+const (
+	PolicyDocker   = "docker"    // image tags: [A-Za-z0-9_.-], at most 128 characters, '+' written as '_'
+	PolicyK8sLabel = "k8s-label" // label values: at most 63 characters, alphanumeric at both ends, '+' written as '_'
+	PolicyNpm      = "npm"       // SemVer 2.0 without prefix; CalVer versions are mapped with ToSemVer
+)
+
+// BuiltinPolicy returns the built-in policy called name.
+func BuiltinPolicy(name string) (Policy, bool) {
+	p, ok := builtinPolicies[name]
+	return p, ok
+}
+
+// BannedWords vetoes versions containing any of words, ignoring case, such as code names or profanity that branch
+// slugs and suffixes would otherwise leak into published tags.
+func BannedWords(words ...string) Policy {
+	return PolicyFunc(func(v string) (string, error) {
+		lower := strings.ToLower(v)
+		for _, w := range words {
+			if w != "" && strings.Contains(lower, strings.ToLower(w)) {
+				return "", &PolicyError{Policy: "banned-words", Version: v, Reason: fmt.Sprintf("contains %q", w)}
+			}
+		}
+		return v, nil
+	})
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var (
+	dockerTagRE = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+	k8sLabelRE  = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_.-]*[A-Za-z0-9])?$`)
+	npmRE       = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*)(?:\.(?:0|[1-9]\d*|\d*[A-Za-z-][0-9A-Za-z-]*))*))?` +
+		`(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+)
+
+var builtinPolicies = map[string]Policy{
+	PolicyDocker:   charsetPolicy(PolicyDocker, 128, dockerTagRE),
+	PolicyK8sLabel: charsetPolicy(PolicyK8sLabel, 63, k8sLabelRE),
+	PolicyNpm:      PolicyFunc(npmPolicy),
+}
+
+// charsetPolicy fits versions into limit characters the way Config.MaxLength does, writes build metadata with '_'
+// instead of '+', and vetoes what re still rejects (an epoch's '!', for one).
+func charsetPolicy(name string, limit int, re *regexp.Regexp) Policy {
+	return PolicyFunc(func(v string) (string, error) {
+		if len(v) > limit {
+			short, err := fitLength(v, limit)
+			if err != nil {
+				return "", &PolicyError{Policy: name, Version: v, Reason: fmt.Sprintf("longer than %d characters", limit)}
+			}
+			v = short
+		}
+		v = strings.ReplaceAll(v, "+", "_")
+		if !re.MatchString(v) {
+			return "", &PolicyError{Policy: name, Version: v, Reason: "contains characters the target rejects"}
+		}
+		return v, nil
+	})
+}
+
+// npmPolicy keeps SemVer 2.0 versions and maps CalVer and prefixed SemVer releases onto one.
+func npmPolicy(v string) (string, error) {
+	if npmRE.MatchString(v) {
+		return v, nil
+	}
+	if pv, err := Parse(v); err == nil {
+		if s, err := ToSemVer(pv); err == nil {
+			return s, nil
+		}
+	}
+	if _, s, err := ParseSemVer(v); err == nil {
+		return s.String(), nil
+	}
+	return "", &PolicyError{Policy: PolicyNpm, Version: v, Reason: "is not SemVer 2.0"}
+}
+
+func checkPolicy(name string) error {
+	if _, ok := builtinPolicies[name]; !ok {
+		return fmt.Errorf("policy %q is not %s, %s or %s", name, PolicyDocker, PolicyK8sLabel, PolicyNpm)
+	}
+	return nil
+}
+
+// applyPolicies runs Config.Policies, then BuildContext.Policies, on v.
+func (c BuildContext) applyPolicies(v string) (string, error) {
+	ps := make([]Policy, 0, len(c.Config.Policies)+len(c.Policies))
+	for _, name := range c.Config.Policies {
+		p, ok := builtinPolicies[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %v", ErrInvalidConfig, checkPolicy(name))
+		}
+		ps = append(ps, p)
+	}
+	for _, p := range append(ps, c.Policies...) {
+		before := v
+		var err error
+		if v, err = p.Apply(v); err != nil {
+			return "", err
+		}
+		if v != before {
+			c.debug("version rewritten by policy", "from", before, "to", v)
+		}
+	}
+	return v, nil
+}
+
+// unpoliced is c without policies, audit or metrics, for reading the components of a version a policy rewrote.
+func (c BuildContext) unpoliced() BuildContext {
+	c.Config.Policies, c.Policies, c.Audit, c.Metrics = nil, nil, nil, nil
+	return c
+}
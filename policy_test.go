@@ -0,0 +1,62 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPolicies(t *testing.T) {
+	long := "20250428.321-" + strings.Repeat("feature-", 20) + "x+abcdef12"
+	for _, tc := range []struct {
+		policy, in, want string
+	}{
+		{PolicyDocker, "20250428.321-feat+abcdef12", "20250428.321-feat_abcdef12"},
+		{PolicyDocker, "1!20250428.321", ""},
+		{PolicyK8sLabel, "20250428.321-feat-", ""},
+		{PolicyNpm, "api-20250428.321.2", "20250428.321.2"},
+		{PolicyNpm, "api-1.4.0", "1.4.0"},
+		{PolicyNpm, "1.4.0-321-SNAPSHOT", "1.4.0-321-SNAPSHOT"},
+		{PolicyNpm, "20250428-nightly.1", ""},
+	} {
+		p, _ := BuiltinPolicy(tc.policy)
+		got, err := p.Apply(tc.in)
+		var pe *PolicyError
+		if tc.want == "" && (!errors.As(err, &pe) || pe.Policy != tc.policy || !errors.Is(err, ErrInvalidVersion)) {
+			t.Fatalf("%s %s: got %s, %v want a veto", tc.policy, tc.in, got, err)
+		}
+		if tc.want != "" && (err != nil || got != tc.want) {
+			t.Fatalf("%s %s: got %s, %v want %s", tc.policy, tc.in, got, err, tc.want)
+		}
+	}
+	for _, name := range []string{PolicyDocker, PolicyK8sLabel} {
+		p, _ := BuiltinPolicy(name)
+		got, err := p.Apply(long)
+		if err != nil || len(got) > 128 || name == PolicyK8sLabel && len(got) > 63 || strings.Contains(got, "+") {
+			t.Fatalf("%s: got %s (%d), %v", name, got, len(got), err)
+		}
+	}
+}
+
+func TestVersionPolicies(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", CommitMeta: true, Monotonic: true, Policies: []string{PolicyDocker}}
+	c := ctx("feature/x", cfg, []string{"20250427.5"})
+	c.CommitSHA = "abcdef1234567890"
+	c.Policies = []Policy{BannedWords("secret")}
+	if v, err := c.Version(); err != nil || v != "20250428.321_abcdef12" {
+		t.Fatalf("got %s, %v", v, err)
+	}
+	bi, err := c.BuildInfo()
+	if err != nil || bi.Version != "20250428.321_abcdef12" || bi.Build != 321 || bi.Commit == "" {
+		t.Fatalf("build info: got %+v, %v", bi, err)
+	}
+
+	c.Branch, c.Config.BranchSlug = "feature/secret-sauce", true
+	var pe *PolicyError
+	if _, err := c.Version(); !errors.As(err, &pe) || pe.Policy != "banned-words" {
+		t.Fatalf("banned word: got %v", err)
+	}
+	if err := (Config{DefaultBranch: "main", Policies: []string{"pypi"}}).Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("unknown policy: got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package versioner
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// SQLLedger is a Ledger in PostgreSQL for the service mode (Server), where the database rather than git tags is the
+// source of truth. DB comes from database/sql with a Postgres driver registered by the binary (e.g.
+// github.com/jackc/pgx/v5/stdlib); Migrate creates the schema from the migrations/ directory.
+type SQLLedger struct {
+	DB      *sql.DB
+	Project string // rows are scoped to this project; when empty, to each manifest's Metadata[ProjectKey]
+}
+
+// Migrate applies the embedded migrations that have not run yet, each in its own transaction, recording them in
+// versioner_schema_migrations. It is safe to call on every start.
+func (l SQLLedger) Migrate(ctx context.Context) error {
+	if _, err := l.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS versioner_schema_migrations (
+		version integer PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())`); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	applied := map[int]bool{}
+	rows, err := l.DB.QueryContext(ctx, `SELECT version FROM versioner_schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			rows.Close()
+			return fmt.Errorf("migrate: %w", err)
+		}
+		applied[n] = true
+	}
+	rows.Close()
+
+	ms, err := Migrations()
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		if applied[m.Version] {
+			continue
+		}
+		tx, err := l.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO versioner_schema_migrations (version) VALUES ($1)`, m.Version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// Record inserts m; a version the project already has is ErrVersionExists.
+func (l SQLLedger) Record(m Manifest) error {
+	doc, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	res, err := l.DB.Exec(`INSERT INTO versioner_versions (project, version, commit_sha, recorded_at, manifest)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (project, version) DO NOTHING`,
+		l.project(m), m.Version, m.Commit, m.Time, string(doc))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s", ErrVersionExists, m.Version)
+	}
+	return nil
+}
+
+// Manifests returns the project's manifests in recording order; with Project empty, every project's.
+func (l SQLLedger) Manifests() ([]Manifest, error) {
+	q, args := `SELECT manifest FROM versioner_versions ORDER BY seq`, []any{}
+	if l.Project != "" {
+		q, args = `SELECT manifest FROM versioner_versions WHERE project = $1 ORDER BY seq`, []any{l.Project}
+	}
+	rows, err := l.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ms []Manifest
+	for rows.Next() {
+		var doc []byte
+		var m Manifest
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(doc, &m); err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	return ms, rows.Err()
+}
+
+// ReserveNext hands out the next release patch of base ("YYYYMMDD.B") for project in one transaction: the counter
+// starts above any patch already recorded and is incremented under the row lock, so concurrent callers never get the
+// same number, even before their versions are recorded.
+func (l SQLLedger) ReserveNext(ctx context.Context, project, base string) (int, error) {
+	tx, err := l.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT version FROM versioner_versions WHERE project = $1`, project)
+	if err != nil {
+		return 0, err
+	}
+	var vs []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		vs = append(vs, v)
+	}
+	rows.Close()
+	_, next, err := nextPatch("release/v"+base, "", vs)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	err = tx.QueryRowContext(ctx, `INSERT INTO versioner_patches AS p (project, base, last) VALUES ($1, $2, $3)
+		ON CONFLICT (project, base) DO UPDATE SET last = GREATEST(p.last + 1, $3) RETURNING last`,
+		project, base, next).Scan(&n)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// Migration is one embedded schema change.
+type Migration struct {
+	Version int    // numeric file name prefix
+	Name    string // file name, e.g. "001_init.sql"
+	SQL     string
+}
+
+// Migrations lists the embedded migrations in order, for tools applying them with their own migration runner.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	var ms []Migration
+	for _, e := range entries {
+		num, _, ok := strings.Cut(e.Name(), "_")
+		n, err := strconv.Atoi(num)
+		if !ok || err != nil || !strings.HasSuffix(e.Name(), ".sql") {
+			return nil, fmt.Errorf("migration %s: want NNN_name.sql", e.Name())
+		}
+		b, err := migrationFS.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, Migration{Version: n, Name: e.Name(), SQL: string(b)})
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	return ms, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+func (l SQLLedger) project(m Manifest) string {
+	if l.Project != "" {
+		return l.Project
+	}
+	return m.Metadata[ProjectKey]
+}
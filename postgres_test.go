@@ -0,0 +1,141 @@
+package versioner
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	ms, err := Migrations()
+	if err != nil || len(ms) < 2 || ms[0].Version != 1 || !strings.Contains(ms[0].SQL, "versioner_patches") {
+		t.Fatalf("got %+v, %v", ms, err)
+	}
+}
+
+func TestSQLLedger(t *testing.T) {
+	db := sql.OpenDB(fakePG{&fakeDB{patches: map[string]int64{}}})
+	l := SQLLedger{DB: db, Project: "grp/app"}
+	for i := 0; i < 2; i++ { // idempotent
+		if err := l.Migrate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Record(Manifest{Version: "20250428.100.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(Manifest{Version: "20250428.100.1"}); !errors.Is(err, ErrVersionExists) {
+		t.Fatalf("duplicate: got %v", err)
+	}
+	if ms, err := l.Manifests(); err != nil || len(ms) != 1 || ms[0].Version != "20250428.100.1" {
+		t.Fatalf("got %+v, %v", ms, err)
+	}
+	for _, want := range []int{2, 3} {
+		if n, err := l.ReserveNext(context.Background(), "grp/app", "20250428.100"); err != nil || n != want {
+			t.Fatalf("got %d want %d (%v)", n, want, err)
+		}
+	}
+
+	srv := &Server{Config: Config{DefaultBranch: "main"}, Ledger: SQLLedger{DB: db}}
+	resp, err := srv.Reserve(VersionRequest{Project: "grp/app", Branch: "release/v20250428.100", PipelineID: "9"})
+	if err != nil || resp.Version != "20250428.100.4" {
+		t.Fatalf("server reserve: got %+v, %v", resp, err)
+	}
+}
+
+// fakePG answers the handful of statements SQLLedger issues, standing in for PostgreSQL.
+type fakePG struct{ db *fakeDB }
+
+type fakeDB struct {
+	mu         sync.Mutex
+	migrations []int64
+	versions   [][2]string // project, version
+	manifests  []string
+	patches    map[string]int64
+}
+
+func (d fakePG) Connect(context.Context) (driver.Conn, error) { return fakeConn{d.db}, nil }
+func (d fakePG) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(q string) (driver.Stmt, error) { return fakeStmt{c.db, q}, nil }
+func (c fakeConn) Close() error                          { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)             { return c, nil }
+func (c fakeConn) Commit() error                         { return nil }
+func (c fakeConn) Rollback() error                       { return nil }
+
+type fakeStmt struct {
+	db *fakeDB
+	q  string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.q, "INSERT INTO versioner_schema_migrations"):
+		d.migrations = append(d.migrations, args[0].(int64))
+	case strings.HasPrefix(s.q, "INSERT INTO versioner_versions"):
+		for _, v := range d.versions {
+			if v == [2]string{args[0].(string), args[1].(string)} {
+				return driver.RowsAffected(0), nil
+			}
+		}
+		d.versions = append(d.versions, [2]string{args[0].(string), args[1].(string)})
+		d.manifests = append(d.manifests, args[4].(string))
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out [][]driver.Value
+	switch {
+	case strings.HasPrefix(s.q, "SELECT version FROM versioner_schema_migrations"):
+		for _, n := range d.migrations {
+			out = append(out, []driver.Value{n})
+		}
+	case strings.HasPrefix(s.q, "SELECT version FROM versioner_versions"):
+		for _, v := range d.versions {
+			if v[0] == args[0] {
+				out = append(out, []driver.Value{v[1]})
+			}
+		}
+	case strings.HasPrefix(s.q, "SELECT manifest"):
+		for i, v := range d.versions {
+			if len(args) == 0 || v[0] == args[0] {
+				out = append(out, []driver.Value{[]byte(d.manifests[i])})
+			}
+		}
+	case strings.HasPrefix(s.q, "INSERT INTO versioner_patches"):
+		key := args[0].(string) + "|" + args[1].(string)
+		d.patches[key] = max(d.patches[key]+1, args[2].(int64))
+		out = append(out, []driver.Value{d.patches[key]})
+	}
+	return &fakeRows{rows: out}, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string { return []string{"c"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
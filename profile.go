@@ -0,0 +1,179 @@
+package versioner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// DefaultProfile is the profile of a config file that every other profile is layered on.
+const DefaultProfile = "default"
+
+// Overlay layers a JSON config file onto cfg: its DefaultProfile first, then the named profile (skipped when empty).
+// The file maps profile names to objects keyed by Config field names (matched case-insensitively, so "prefix" and
+// "defaultBranch" work); Timeouts is a nested object and durations are strings like "90s".
+//
+//	{"default": {"defaultBranch": "main", "prefix": "api"},
+//	 "prod":    {"monotonic": true, "noCollisions": true},
+//	 "sandbox": {"prefix": "sbx", "channels": {"*": "alpha"}}}
+//
+// A profile replaces only the fields it names; Channels maps merge key by key. Unknown fields and profiles are
+// errors, so a typo never silently falls back to a default.
+//
+// Precedence, lowest first: cfg as passed, the default profile, the named profile, then OverlayEnv and command-line
+// flags as the CLI applies them.
+func (cfg Config) Overlay(r io.Reader, profile string) (Config, error) {
+	var profiles map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&profiles); err != nil {
+		return cfg, fmt.Errorf("%w: config file: %v", ErrInvalidConfig, err)
+	}
+	layers := []string{DefaultProfile}
+	if profile != "" && profile != DefaultProfile {
+		if _, ok := profiles[profile]; !ok {
+			names := make([]string, 0, len(profiles))
+			for n := range profiles {
+				names = append(names, n)
+			}
+			slices.Sort(names)
+			return cfg, fmt.Errorf("%w: unknown profile %q (have %s)", ErrInvalidConfig, profile, strings.Join(names, ", "))
+		}
+		layers = append(layers, profile)
+	}
+	for _, name := range layers {
+		raw, ok := profiles[name]
+		if !ok {
+			continue
+		}
+		if err := overlayProfile(&cfg, raw); err != nil {
+			return cfg, fmt.Errorf("%w: profile %q: %v", ErrInvalidConfig, name, err)
+		}
+	}
+	return cfg, nil
+}
+
+// OverlayEnv applies the VERSIONER_* variables of EnvConfig found by lookup (usually os.LookupEnv) to cfg. Empty
+// variables are ignored; booleans take strconv.ParseBool values, so "false" switches off what a profile set.
+func (cfg Config) OverlayEnv(lookup func(string) (string, bool)) (Config, error) {
+	var ps []error
+	for _, e := range envConfig {
+		v, ok := lookup(e.name)
+		if !ok || v == "" {
+			continue
+		}
+		switch p := e.field(&cfg).(type) {
+		case *string:
+			*p = v
+		case *bool:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				ps = append(ps, fmt.Errorf("%s=%q is not a boolean", e.name, v))
+			}
+			*p = b
+		case *int:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				ps = append(ps, fmt.Errorf("%s=%q is not a number", e.name, v))
+			}
+			*p = n
+		}
+	}
+	if len(ps) > 0 {
+		return cfg, &ConfigError{Problems: ps}
+	}
+	return cfg, nil
+}
+
+// EnvConfig lists the variables OverlayEnv reads, in the order it applies them.
+func EnvConfig() []string {
+	names := make([]string, len(envConfig))
+	for i, e := range envConfig {
+		names[i] = e.name
+	}
+	return names
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var envConfig = []struct {
+	name  string
+	field func(*Config) any
+}{
+	{"VERSIONER_DEFAULT_BRANCH", func(c *Config) any { return &c.DefaultBranch }},
+	{"VERSIONER_PREFIX", func(c *Config) any { return &c.Prefix }},
+	{"VERSIONER_NAMESPACE", func(c *Config) any { return &c.Namespace }},
+	{"VERSIONER_SUFFIX", func(c *Config) any { return &c.FeatureSuffix }},
+	{"VERSIONER_TIMEZONE", func(c *Config) any { return &c.Timezone }},
+	{"VERSIONER_EPOCH", func(c *Config) any { return &c.Epoch }},
+	{"VERSIONER_DAILY_SEQUENCE", func(c *Config) any { return &c.DailySequence }},
+	{"VERSIONER_MAX_LENGTH", func(c *Config) any { return &c.MaxLength }},
+	{"VERSIONER_BUILD_WIDTH", func(c *Config) any { return &c.BuildWidth }},
+	{"VERSIONER_PATCH_WIDTH", func(c *Config) any { return &c.PatchWidth }},
+	{"VERSIONER_NIGHTLY", func(c *Config) any { return &c.Nightly }},
+	{"VERSIONER_NIGHTLY_KEEP_DAYS", func(c *Config) any { return &c.NightlyKeepDays }},
+	{"VERSIONER_SEMVER", func(c *Config) any { return &c.SemVer }},
+	{"VERSIONER_LEGACY_TAGS", func(c *Config) any { return &c.LegacyTags }},
+	{"VERSIONER_REQUIRE_BASE", func(c *Config) any { return &c.RequireBaseTag }},
+	{"VERSIONER_RC", func(c *Config) any { return &c.Candidates }},
+	{"VERSIONER_FINAL", func(c *Config) any { return &c.Final }},
+	{"VERSIONER_MONOTONIC", func(c *Config) any { return &c.Monotonic }},
+	{"VERSIONER_NO_COLLISIONS", func(c *Config) any { return &c.NoCollisions }},
+	{"VERSIONER_FORCE_VERSION", func(c *Config) any { return &c.ForceVersion }},
+	{"VERSIONER_UNKNOWN_BRANCHES", func(c *Config) any { return (*string)(&c.UnknownBranches) }},
+	{"VERSIONER_TICKET_PATTERN", func(c *Config) any { return &c.TicketPattern }},
+	{"VERSIONER_MILESTONE", func(c *Config) any { return &c.Milestone }},
+	{"VERSIONER_CHANGELOG", func(c *Config) any { return &c.Changelog }},
+	{"VERSIONER_TRAIN", func(c *Config) any { return &c.Train }},
+	{"VERSIONER_DRY_RUN", func(c *Config) any { return &c.DryRun }},
+}
+
+// durationFields are the Config fields (lower-cased, dotted below Timeouts) a profile writes as duration strings.
+var durationFields = map[string]bool{"pushbackoff": true, "timeouts.git": true, "timeouts.api": true, "timeouts.webhook": true}
+
+// overlayProfile decodes one profile onto cfg after turning its duration strings into the nanoseconds
+// time.Duration decodes from.
+func overlayProfile(cfg *Config, raw json.RawMessage) error {
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return err
+	}
+	if err := parseDurations(m, ""); err != nil {
+		return err
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(cfg)
+}
+
+func parseDurations(m map[string]any, path string) error {
+	for k, v := range m {
+		key := path + strings.ToLower(k)
+		switch v := v.(type) {
+		case map[string]any:
+			if key == "timeouts" {
+				if err := parseDurations(v, key+"."); err != nil {
+					return err
+				}
+			}
+		case string:
+			if durationFields[key] {
+				d, err := time.ParseDuration(v)
+				if err != nil {
+					return fmt.Errorf("%s: %v", k, err)
+				}
+				m[k] = int64(d)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profilesJSON = `{
+	"default": {"defaultBranch": "main", "prefix": "api", "channels": {"develop": "beta"}, "timeouts": {"git": "30s"}},
+	"prod":    {"monotonic": true, "channels": {"feature/*": "alpha"}},
+	"sandbox": {"prefix": "sbx", "pushBackoff": "250ms"}
+}`
+
+func TestConfigOverlay(t *testing.T) {
+	cfg, err := Config{DefaultBranch: "master", Epoch: 2}.Overlay(strings.NewReader(profilesJSON), "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultBranch != "main" || cfg.Prefix != "api" || !cfg.Monotonic || cfg.Epoch != 2 {
+		t.Fatalf("got %+v", cfg)
+	}
+	if len(cfg.Channels) != 2 || cfg.Timeouts.Git != 30*time.Second {
+		t.Fatalf("got channels %v, git timeout %v", cfg.Channels, cfg.Timeouts.Git)
+	}
+
+	cfg, err = Config{}.Overlay(strings.NewReader(profilesJSON), "sandbox")
+	if err != nil || cfg.Prefix != "sbx" || cfg.PushBackoff != 250*time.Millisecond || cfg.Monotonic {
+		t.Fatalf("got %+v, %v", cfg, err)
+	}
+
+	for _, tc := range []struct{ file, profile string }{
+		{profilesJSON, "staging"},
+		{`{"default": {"prefx": "api"}}`, ""},
+		{`{"default": {"pushBackoff": "soon"}}`, ""},
+		{`[]`, ""},
+	} {
+		if _, err := (Config{}).Overlay(strings.NewReader(tc.file), tc.profile); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%s %s: got %v want %v", tc.file, tc.profile, err, ErrInvalidConfig)
+		}
+	}
+}
+
+func TestConfigOverlayEnv(t *testing.T) {
+	env := map[string]string{"VERSIONER_PREFIX": "web", "VERSIONER_MONOTONIC": "false", "VERSIONER_EPOCH": "3",
+		"VERSIONER_SUFFIX": ""}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+
+	cfg, err := Config{Prefix: "api", FeatureSuffix: "SNAPSHOT", Monotonic: true}.OverlayEnv(lookup)
+	if err != nil || cfg.Prefix != "web" || cfg.Monotonic || cfg.Epoch != 3 || cfg.FeatureSuffix != "SNAPSHOT" {
+		t.Fatalf("got %+v, %v", cfg, err)
+	}
+
+	env["VERSIONER_EPOCH"], env["VERSIONER_DRY_RUN"] = "one", "maybe"
+	var ce *ConfigError
+	if _, err := (Config{}).OverlayEnv(lookup); !errors.As(err, &ce) || len(ce.Problems) != 2 {
+		t.Fatalf("got %v want 2 problems", err)
+	}
+}
@@ -0,0 +1,58 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Promote turns an already built snapshot into a release without rebuilding it ("build once, promote later"). The
+// final version is the snapshot's date and build without suffix or metadata; it is tagged on commit together with
+// release/v<final>, so later fixes get patches via the usual release-branch rule. The snapshot → final mapping is
+// kept in the tag annotation (Manifest.PromotedFrom).
+func (c BuildContext) Promote(snapshot, commit string) (Manifest, error) {
+	c = c.pinTime()
+	sv, err := Parse(snapshot)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if sv.Patch > 0 {
+		return Manifest{}, fmt.Errorf("%w: %s is already a release patch", ErrInvalidVersion, snapshot)
+	}
+	if commit == "" {
+		return Manifest{}, fmt.Errorf("%w: promoting %s needs the commit it was built from", ErrInvalidConfig, snapshot)
+	}
+
+	final := Version{Prefix: sv.Prefix, Epoch: sv.Epoch, Date: sv.Date, Build: sv.Build, BuildWidth: sv.BuildWidth}
+	m := Manifest{
+		Version:      final.String(),
+		Commit:       commit,
+		Time:         c.now().UTC(),
+		Metadata:     c.Metadata,
+		PromotedFrom: snapshot,
+	}
+	if c.tagged(m.Version) {
+		return Manifest{}, fmt.Errorf("%w: %s", ErrVersionExists, m.Version)
+	}
+
+	branch := "release/v" + final.Base()
+	ref := "refs/heads/" + branch
+	c.debug("promoting snapshot", "snapshot", snapshot, "version", m.Version, "branch", branch)
+
+	if err := c.effect("create tag "+m.Version+" on "+shortSHA(commit), func() error { return c.tag(m) }); err != nil {
+		return Manifest{}, err
+	}
+	steps := [][]string{
+		{"branch", branch, commit},
+		{"push", "origin", "refs/tags/" + m.Version, ref + ":" + ref},
+	}
+	for _, args := range steps {
+		err := c.effect("run git "+strings.Join(args, " "), func() error {
+			_, err := c.git(args...)
+			return err
+		})
+		if err != nil {
+			return Manifest{}, err
+		}
+	}
+	return m, c.released(m)
+}
@@ -0,0 +1,35 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPromoteSnapshot(t *testing.T) {
+	origin := gitRepo(t)
+	built := mustGit(t, "", "rev-parse", "HEAD")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "unrelated later work")
+
+	c := ctx("feat/payments", Config{DefaultBranch: "main"}, nil)
+	m, err := c.Promote("cli-20250428.321-SNAPSHOT", built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "cli-20250428.321" || m.PromotedFrom != "cli-20250428.321-SNAPSHOT" {
+		t.Fatalf("unexpected manifest %+v", m)
+	}
+	if got := mustGit(t, "", "rev-parse", "cli-20250428.321^{commit}"); got != built {
+		t.Fatalf("tag points at %s want %s", got, built)
+	}
+	if got := mustGit(t, "", "ls-remote", origin, "refs/heads/release/v20250428.321"); got == "" {
+		t.Fatal("release branch not pushed")
+	}
+	back, err := ReadTagManifest("cli-20250428.321")
+	if err != nil || back.PromotedFrom != "cli-20250428.321-SNAPSHOT" {
+		t.Fatalf("mapping not recorded in annotation: %+v, %v", back, err)
+	}
+
+	if _, err := c.Promote("cli-20250428.321-SNAPSHOT", built); !errors.Is(err, ErrVersionExists) {
+		t.Fatalf("second promotion: got %v want ErrVersionExists", err)
+	}
+}
@@ -0,0 +1,92 @@
+package versioner
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Subject is an artifact a provenance statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"` // algorithm → hex, e.g. "sha256"
+}
+
+// ProvenanceOptions fills the parts of a provenance statement the BuildContext doesn't know.
+type ProvenanceOptions struct {
+	BuilderID string    // runDetails.builder.id; defaults to BuildContext.PipelineURL
+	BuildType string    // defaults to ProvenanceBuildType
+	RepoURL   string    // source repository, recorded with the commit as the resolved dependency
+	Subjects  []Subject // the built artifacts
+}
+
+// ProvenanceBuildType identifies builds described by this package's provenance.
+const ProvenanceBuildType = "https://github.com/drew-mcl/test/provenance/v1"
+
+// Provenance renders an in-toto v1 Statement carrying a SLSA v1 provenance predicate for m: the version, branch and
+// pipeline go into the build definition, the commit into the resolved dependencies and the pipeline URL into the
+// invocation, so the version step can feed attestation tooling (cosign attest, slsa-verifier) directly.
+func (c BuildContext) Provenance(m Manifest, opts ProvenanceOptions) ([]byte, error) {
+	if len(opts.Subjects) == 0 {
+		return nil, fmt.Errorf("%w: provenance needs at least one subject", ErrInvalidConfig)
+	}
+	builder := firstNonEmpty(opts.BuilderID, c.PipelineURL)
+	if builder == "" {
+		return nil, fmt.Errorf("%w: provenance needs a builder ID or pipeline URL", ErrInvalidConfig)
+	}
+
+	var st statement
+	st.Type = "https://in-toto.io/Statement/v1"
+	st.Subject = opts.Subjects
+	st.PredicateType = "https://slsa.dev/provenance/v1"
+	bd := &st.Predicate.BuildDefinition
+	bd.BuildType = firstNonEmpty(opts.BuildType, ProvenanceBuildType)
+	bd.ExternalParameters = map[string]string{"version": m.Version, "branch": c.Branch}
+	bd.InternalParameters = map[string]string{"pipeline_id": c.PipelineID, "kind": Classify(c.Config, c.Branch).String()}
+	if commit := firstNonEmpty(m.Commit, c.CommitSHA); commit != "" {
+		dep := resourceDescriptor{Digest: map[string]string{"gitCommit": commit}}
+		if opts.RepoURL != "" {
+			dep.URI = "git+" + opts.RepoURL
+		}
+		bd.ResolvedDependencies = append(bd.ResolvedDependencies, dep)
+	}
+	rd := &st.Predicate.RunDetails
+	rd.Builder.ID = builder
+	rd.Metadata.InvocationID = c.PipelineURL
+	if !m.Time.IsZero() {
+		rd.Metadata.StartedOn = m.Time.UTC().Format(time.RFC3339)
+	}
+	return json.MarshalIndent(st, "", "  ")
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+type resourceDescriptor struct {
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+type statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     struct {
+		BuildDefinition struct {
+			BuildType            string               `json:"buildType"`
+			ExternalParameters   map[string]string    `json:"externalParameters"`
+			InternalParameters   map[string]string    `json:"internalParameters,omitempty"`
+			ResolvedDependencies []resourceDescriptor `json:"resolvedDependencies,omitempty"`
+		} `json:"buildDefinition"`
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Metadata struct {
+				InvocationID string `json:"invocationId,omitempty"`
+				StartedOn    string `json:"startedOn,omitempty"`
+			} `json:"metadata"`
+		} `json:"runDetails"`
+	} `json:"predicate"`
+}
@@ -0,0 +1,34 @@
+package versioner
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, nil)
+	c.PipelineURL = "https://gitlab.example.com/grp/app/-/pipelines/9"
+	m := Manifest{Version: "20250428.100.1", Commit: "abc123", Time: now}
+	b, err := c.Provenance(m, ProvenanceOptions{
+		RepoURL:  "https://gitlab.example.com/grp/app",
+		Subjects: []Subject{{Name: "app.tar.gz", Digest: map[string]string{"sha256": "ff"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st statement
+	if err := json.Unmarshal(b, &st); err != nil {
+		t.Fatal(err)
+	}
+	bd, rd := st.Predicate.BuildDefinition, st.Predicate.RunDetails
+	if st.PredicateType != "https://slsa.dev/provenance/v1" || bd.ExternalParameters["version"] != m.Version ||
+		bd.ResolvedDependencies[0].URI != "git+https://gitlab.example.com/grp/app" ||
+		bd.ResolvedDependencies[0].Digest["gitCommit"] != "abc123" || rd.Builder.ID != c.PipelineURL ||
+		rd.Metadata.StartedOn != "2025-04-28T15:00:00Z" {
+		t.Fatalf("unexpected statement %s", b)
+	}
+	if _, err := c.Provenance(m, ProvenanceOptions{}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want ErrInvalidConfig", err)
+	}
+}
@@ -0,0 +1,47 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// CheckRefName rejects branch and tag names git would not create or that could be taken for an option, following
+// git check-ref-format: no leading '-', no control characters, space or any of ~ ^ : ? * [ \, no "..", "@{" or "//",
+// no component starting with '.' or ending in ".lock", and no trailing '/' or '.'. Branch names of fork pipelines are
+// chosen by whoever opens the merge request, so every name that reaches git is checked. The error wraps ErrUnsafeRef.
+func CheckRefName(name string) error {
+	if reason := refNameProblem(name); reason != "" {
+		return fmt.Errorf("%w: %q %s", ErrUnsafeRef, name, reason)
+	}
+	return nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func refNameProblem(name string) string {
+	switch {
+	case name == "" || name == "@":
+		return "is empty"
+	case strings.HasPrefix(name, "-"):
+		return "starts with '-'"
+	case strings.ContainsAny(name, " ~^:?*[\\\x7f"):
+		return "contains a character git forbids in refs"
+	case strings.Contains(name, ".."), strings.Contains(name, "@{"), strings.Contains(name, "//"):
+		return "contains \"..\", \"@{\" or \"//\""
+	case strings.HasSuffix(name, "/"), strings.HasSuffix(name, "."):
+		return "ends with '/' or '.'"
+	}
+	for _, r := range name {
+		if r < 0x20 {
+			return "contains a control character"
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return "has a component starting with '.' or ending in \".lock\""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRefName(t *testing.T) {
+	for _, ok := range []string{"main", "release/v20250428.100", "feat/JIRA-1_x", "20250428.100.1", "api-20250428.1-rc.2+abc"} {
+		if err := CheckRefName(ok); err != nil {
+			t.Fatalf("%s: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"", "-d", "--upload-pack=touch /tmp/x", "feat/a..b", "x@{1}", "a//b", "feat/", "x.",
+		"feat/.hidden", "x.lock", "a b", "a\tb", "a:b", "a~1", "a^", "a?", "a*", "a[", `a\b`, "@"} {
+		if err := CheckRefName(bad); !errors.Is(err, ErrUnsafeRef) {
+			t.Fatalf("%q: got %v want ErrUnsafeRef", bad, err)
+		}
+	}
+}
+
+func TestUnsafeNamesNeverReachGit(t *testing.T) {
+	if _, err := ctx("--exec=evil", Config{DefaultBranch: "main"}, nil).Version(); !errors.Is(err, ErrUnsafeRef) {
+		t.Fatalf("branch: got %v want ErrUnsafeRef", err)
+	}
+	prev := SetRunner(fakeRunner{}) // any git call would fail with a *GitError instead
+	t.Cleanup(func() { SetRunner(prev) })
+	if err := Tag(Manifest{Version: "-d"}); !errors.Is(err, ErrUnsafeRef) {
+		t.Fatalf("tag: got %v want ErrUnsafeRef", err)
+	}
+	if _, err := ReadTagManifest("--help"); !errors.Is(err, ErrUnsafeRef) {
+		t.Fatalf("manifest: got %v want ErrUnsafeRef", err)
+	}
+	if _, err := ctx("main", Config{DefaultBranch: "main"}, nil).Confirm("-v"); !errors.Is(err, ErrUnsafeRef) {
+		t.Fatalf("confirm: got %v want ErrUnsafeRef", err)
+	}
+	if _, skipped := screenTags([]string{"-x"}); len(skipped) != 1 {
+		t.Fatalf("tag lookup kept %q", "-x")
+	}
+}
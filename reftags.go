@@ -0,0 +1,86 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// RefTags is a tag source for repositories with tens of thousands of tags: `git for-each-ref` applies the patterns
+// (fnmatch, relative to refs/tags/) so only relevant tag names are transferred and parsed, newest version first.
+// Without patterns it lists every tag like GitTags.
+func RefTags(patterns ...string) func() ([]string, error) {
+	return BuildContext{}.RefTags(patterns...)
+}
+
+// RefTags is RefTags bounded by Config.Timeouts.Git.
+func (c BuildContext) RefTags(patterns ...string) func() ([]string, error) {
+	return func() ([]string, error) {
+		args := []string{"for-each-ref", "--format=%(refname:strip=2)", "--sort=-version:refname"}
+		for _, p := range patterns {
+			args = append(args, "refs/tags/"+p)
+		}
+		if len(patterns) == 0 {
+			args = append(args, "refs/tags")
+		}
+		out, err := c.git(args...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+		}
+		return strings.Fields(out), nil
+	}
+}
+
+// TagPatterns are the narrowest RefTags patterns that still cover every tag Version consults for this build: the
+// branch's base, its patches and release candidates on release branches, every nightly for a nightly (pruning needs
+// the old ones), the MAJOR.MINOR.PATCH tags (of the release line) in SemVer mode, otherwise all dated tags carrying
+// Config.Prefix.
+func (c BuildContext) TagPatterns() []string {
+	e := epochMark(c.Config.Epoch)
+	release := Classify(c.Config, c.Branch) == KindRelease
+	ps := []string{e + "????????.*"}
+	switch m, sm := relBranchRE.FindStringSubmatch(c.Branch), semverLineRE.FindStringSubmatch(c.Branch); {
+	case c.Config.SemVer:
+		line := "[0-9]*.*."
+		if sm != nil && release {
+			line = sm[1] + "." + sm[2] + "."
+		}
+		ps = []string{line + "*"}
+		if c.Config.LegacyTags {
+			ps = append(ps, "v"+line+"*")
+		}
+	case c.isNightly():
+		ps = []string{"????????-nightly.*"}
+	case m != nil && release:
+		ps = []string{e + m[1], e + m[1] + ".*", e + m[1] + "-rc.*"}
+	}
+	for i, p := range ps {
+		ps[i] = c.tagName(addPrefix(p, c.Config.Prefix))
+	}
+	return ps
+}
+
+// DescribeTags is a tag source that returns only the nearest final tag reachable from HEAD, found with `git describe
+// --tags`. For feature branches cut from older commits that is both faster and more correct than the globally latest
+// tag. No reachable tag yields an empty list.
+func DescribeTags(prefix string) func() ([]string, error) {
+	return BuildContext{}.DescribeTags(prefix)
+}
+
+// DescribeTags is DescribeTags bounded by Config.Timeouts.Git.
+func (c BuildContext) DescribeTags(prefix string) func() ([]string, error) {
+	match := addPrefix("????????.*", prefix)
+	return func() ([]string, error) {
+		out, err := c.git("describe", "--tags", "--abbrev=0", "--match", match, "--exclude", match+"-*", "HEAD")
+		if ge := (*GitError)(nil); errors.As(err, &ge) &&
+			(strings.Contains(ge.Output, "No names found") || strings.Contains(ge.Output, "cannot describe")) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+		}
+		return strings.Fields(out), nil
+	}
+}
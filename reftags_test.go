@@ -0,0 +1,105 @@
+package versioner
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRefTags(t *testing.T) {
+	gitRepo(t)
+	for _, tag := range []string{"20250427.90", "20250428.100", "20250428.100.1", "20250428.100.2", "app-20250428.7", "v1.0.0"} {
+		mustGit(t, "", "tag", tag)
+	}
+
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, nil)
+	ts, err := RefTags(c.TagPatterns()...)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(ts), "[20250428.100.2 20250428.100.1 20250428.100]"; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	c.LookupTags = RefTags(c.TagPatterns()...)
+	if v, _ := c.Version(); v != "20250428.100.3" {
+		t.Fatalf("got %s want 20250428.100.3", v)
+	}
+
+	c.Config.RequireBaseTag = true
+	if v, err := c.Version(); err != nil || v != "20250428.100.3" {
+		t.Fatalf("scoped with RequireBaseTag: got %s, %v", v, err)
+	}
+
+	c = ctx("main", Config{DefaultBranch: "main", Prefix: "app"}, nil)
+	if ts, _ := RefTags(c.TagPatterns()...)(); fmt.Sprint(ts) != "[app-20250428.7]" {
+		t.Fatalf("prefixed scope got %v", ts)
+	}
+	if ts, _ := RefTags()(); len(ts) != 6 {
+		t.Fatalf("unscoped got %v", ts)
+	}
+}
+
+func TestDescribeTags(t *testing.T) {
+	gitRepo(t)
+	if ts, err := DescribeTags("")(); err != nil || len(ts) != 0 {
+		t.Fatalf("untagged repo: got %v, %v", ts, err)
+	}
+	mustGit(t, "", "tag", "20250427.90")
+	mustGit(t, "", "checkout", "-q", "-b", "feat")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "feature")
+	mustGit(t, "", "tag", "20250428.5-feat")
+	mustGit(t, "", "checkout", "-q", "main")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "newer")
+	mustGit(t, "", "tag", "20250428.100")
+	mustGit(t, "", "checkout", "-q", "feat")
+
+	ts, err := DescribeTags("")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ts) != "[20250427.90]" {
+		t.Fatalf("got %v want the nearest reachable final tag 20250427.90", ts)
+	}
+}
+
+func TestRefTagsCandidates(t *testing.T) {
+	gitRepo(t)
+	for _, tag := range []string{"cli-20250428.100", "cli-20250428.100-rc.1", "cli-20250428.100-rc.2"} {
+		mustGit(t, "", "tag", tag)
+	}
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", Prefix: "cli", Candidates: true}, nil)
+	c.LookupTags = RefTags(c.TagPatterns()...)
+	if v, err := c.Version(); err != nil || v != "cli-20250428.100-rc.3" {
+		t.Fatalf("got %s, %v want cli-20250428.100-rc.3", v, err)
+	}
+}
+
+func TestRefTagsNightlyAndSemVer(t *testing.T) {
+	gitRepo(t)
+	for _, tag := range []string{"20250428-nightly.1", "20250428-nightly.2", "20250427.5", "1.2.3", "1.3.0", "v1.3.1", "api-2.0.0"} {
+		mustGit(t, "", "tag", tag)
+	}
+	c := ctx("main", Config{DefaultBranch: "main", Nightly: true}, nil)
+	c.PipelineSource = "schedule"
+	c.LookupTags = RefTags(c.TagPatterns()...)
+	if v, err := c.Version(); err != nil || v != "20250428-nightly.3" {
+		t.Fatalf("nightly: got %s, %v", v, err)
+	}
+
+	for _, tc := range []struct {
+		branch string
+		cfg    Config
+		want   string
+	}{
+		{"main", Config{}, "1.3.1"},
+		{"main", Config{LegacyTags: true}, "1.3.2"},
+		{"release/v1.2", Config{}, "1.2.4"},
+		{"main", Config{Prefix: "api"}, "api-2.0.1"},
+	} {
+		tc.cfg.DefaultBranch, tc.cfg.SemVer, tc.cfg.Bump = "main", true, BumpPatch
+		c := ctx(tc.branch, tc.cfg, nil)
+		c.LookupTags = RefTags(c.TagPatterns()...)
+		if v, err := c.Version(); err != nil || v != tc.want {
+			t.Fatalf("semver %s %+v: got %s, %v want %s", tc.branch, tc.cfg, v, err, tc.want)
+		}
+	}
+}
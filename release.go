@@ -0,0 +1,113 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Release is a hosted release page for a pushed tag.
+type Release struct {
+	Version string // release name
+	Tag     string // tag name, '<namespace>/<version>' for namespaced releases; Version when empty
+	Commit  string // tagged commit; lets the host create the tag if it has not seen the push yet
+	Notes   string // Markdown body
+
+	Milestones []string // titles of the milestones the release belongs to; hosts without milestones ignore them
+}
+
+// Publisher creates hosted releases. Publishing a version that already has a release succeeds, so re-runs are safe.
+type Publisher interface {
+	Publish(ctx context.Context, r Release) error
+}
+
+// PublishRelease publishes m through p. The notes are m.Notes when TagAndPush put them in the annotation, otherwise
+// the same grouped changelog since the previous tag, so every host shows identical text. On GitLab with
+// Config.Milestone set, the release links the milestone FindMilestone picks (none when nothing matches) and, with
+// Config.CloseMilestone, closes it afterwards.
+func (c BuildContext) PublishRelease(ctx context.Context, p Publisher, m Manifest) error {
+	c = c.pinTime()
+	notes := m.Notes
+	if notes == "" {
+		var err error
+		if notes, err = c.ReleaseNotes(m.Version); err != nil {
+			return err
+		}
+	}
+	r := Release{Version: m.Version, Tag: TagName(m.Namespace, m.Version), Commit: m.Commit, Notes: notes}
+	gl, isGitLab := p.(GitLab)
+	var ms Milestone
+	if isGitLab && c.Config.Milestone != "" {
+		var ok bool
+		var err error
+		if ms, ok, err = c.FindMilestone(ctx, gl, m.Version); err != nil {
+			return fmt.Errorf("milestone: %w", err)
+		}
+		if ok {
+			r.Milestones = []string{ms.Title}
+		} else {
+			c.debug("no milestone matches", "milestone", c.Config.Milestone, "version", m.Version)
+		}
+	}
+	desc := "publish release " + m.Version
+	if len(r.Milestones) > 0 {
+		desc += " in milestone " + r.Milestones[0]
+	}
+	err := c.effect(desc, func() error {
+		ctx, cancel := context.WithTimeout(ctx, orDefault(c.Config.Timeouts.API, DefaultAPITimeout))
+		defer cancel()
+		return p.Publish(ctx, r)
+	})
+	if err != nil || len(r.Milestones) == 0 || !c.Config.CloseMilestone || ms.State != "active" {
+		return err
+	}
+	return c.effect("close milestone "+ms.Title, func() error {
+		ctx, cancel := context.WithTimeout(ctx, orDefault(c.Config.Timeouts.API, DefaultAPITimeout))
+		defer cancel()
+		return gl.CloseMilestone(ctx, ms.ID)
+	})
+}
+
+// Publish creates a GitLab Release for r.Version; 409 Conflict means it already exists.
+func (gl GitLab) Publish(ctx context.Context, r Release) error {
+	req := map[string]any{
+		"tag_name":    r.tag(),
+		"ref":         r.Commit,
+		"name":        r.Version,
+		"description": r.Notes,
+	}
+	if len(r.Milestones) > 0 {
+		req["milestones"] = r.Milestones
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := gl.do(ctx, http.MethodPost, "releases", bytes.NewReader(body))
+	if isAPIStatus(err, http.StatusConflict) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (r Release) tag() string {
+	if r.Tag == "" {
+		return r.Version
+	}
+	return r.Tag
+}
+
+func isAPIStatus(err error, code int) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.Code == code
+}
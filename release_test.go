@@ -0,0 +1,77 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitLabPublish(t *testing.T) {
+	gl := fakeGitLab(t, map[string]string{"POST /projects/grp/app/releases": `{}`})
+	if err := gl.Publish(context.Background(), Release{Version: "20250428.100.1", Notes: "## x"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitHubPublish(t *testing.T) {
+	var got map[string]string
+	exists := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/app/releases" || r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if exists {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"errors":[{"resource":"Release","code":"already_exists","field":"tag_name"}]}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		exists = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	gh := GitHub{BaseURL: srv.URL, Repo: "acme/app", Token: "tok"}
+	r := Release{Version: "20250428.100.1", Commit: "abc", Notes: "## 20250428.100.1"}
+	if err := gh.Publish(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if got["tag_name"] != r.Version || got["target_commitish"] != "abc" || got["body"] != r.Notes {
+		t.Fatalf("unexpected payload %v", got)
+	}
+	if err := gh.Publish(context.Background(), r); err != nil {
+		t.Fatalf("re-publish should be a no-op, got %v", err)
+	}
+}
+
+func TestPublishReleaseDryRun(t *testing.T) {
+	var out bytes.Buffer
+	c := BuildContext{Config: Config{DryRun: true}, DryRunOut: &out}
+	p := GitHub{BaseURL: "http://127.0.0.1:1"}
+	if err := c.PublishRelease(context.Background(), p, Manifest{Version: "20250428.100", Notes: "n"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "dry-run: would publish release 20250428.100\n" {
+		t.Fatalf("got %q", out.String())
+	}
+}
+
+type publisherFunc func(context.Context, Release) error
+
+func (f publisherFunc) Publish(ctx context.Context, r Release) error { return f(ctx, r) }
+
+func TestPublishReleaseInNamespace(t *testing.T) {
+	var got Release
+	p := publisherFunc(func(_ context.Context, r Release) error { got = r; return nil })
+	m := Manifest{Version: "20250428.100", Namespace: "cli", Notes: "n"}
+	if err := (BuildContext{}).PublishRelease(context.Background(), p, m); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "20250428.100" || got.tag() != "cli/20250428.100" {
+		t.Fatalf("got %+v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package versioner
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// RemoteTags is a tag source that asks the remote directly with `git ls-remote --tags --refs <url>`, for jobs that
+// never clone the repository. A non-empty token (e.g. $CI_JOB_TOKEN) authenticates HTTPS URLs as gitlab-ci-token;
+// it is passed in git's environment, never on the command line, so it cannot leak into a *GitError. SSH URLs use
+// the job's SSH agent or GIT_SSH_COMMAND as usual.
+func RemoteTags(url, token string) func() ([]string, error) {
+	return BuildContext{}.RemoteTags(url, token)
+}
+
+// RemoteTags is RemoteTags with ls-remote bounded by Config.Timeouts.Git, so a remote or credential helper that hangs
+// fails the job in time.
+func (c BuildContext) RemoteTags(url, token string) func() ([]string, error) {
+	return func() ([]string, error) {
+		var env []string
+		if token != "" && strings.HasPrefix(url, "https://") {
+			auth := base64.StdEncoding.EncodeToString([]byte("gitlab-ci-token:" + token))
+			env = []string{
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=http.extraHeader",
+				"GIT_CONFIG_VALUE_0=Authorization: Basic " + auth,
+			}
+		}
+		out, err := c.gitEnv(env, "ls-remote", "--tags", "--refs", url)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+		}
+		return parseLsRemote(out), nil
+	}
+}
+
+// ProjectURL is the HTTPS clone URL of the current GitLab project from CI_SERVER_URL and CI_PROJECT_PATH.
+func ProjectURL(server, project string) string {
+	return strings.TrimSuffix(server, "/") + "/" + strings.Trim(project, "/") + ".git"
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// parseLsRemote keeps the tag names of "<sha>\trefs/tags/<name>" lines.
+func parseLsRemote(out string) []string {
+	var ts []string
+	for _, line := range strings.Split(out, "\n") {
+		if _, ref, ok := strings.Cut(line, "\t"); ok {
+			if name, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+				ts = append(ts, name)
+			}
+		}
+	}
+	return ts
+}
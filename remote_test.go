@@ -0,0 +1,31 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRemoteTags(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, origin, "tag", "20250428.100")
+	mustGit(t, origin, "tag", "-a", "20250428.100.1", "-m", "rel")
+	t.Chdir(t.TempDir()) // no clone needed
+
+	ts, err := RemoteTags(origin, "")()
+	if err != nil || strings.Join(ts, " ") != "20250428.100 20250428.100.1" {
+		t.Fatalf("got %v, %v", ts, err)
+	}
+
+	_, err = RemoteTags("https://example.invalid/x.git", "s3cret")()
+	var ge *GitError
+	if !errors.Is(err, ErrTagLookupFailed) || !errors.As(err, &ge) || strings.Contains(err.Error(), "s3cret") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestProjectURL(t *testing.T) {
+	if got := ProjectURL("https://gitlab.example.com/", "grp/app"); got != "https://gitlab.example.com/grp/app.git" {
+		t.Fatalf("got %s", got)
+	}
+}
@@ -0,0 +1,71 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// previousAssignment looks for the version an earlier run of this commit produced on the release branch: first in
+// the ledger, then among the tags pointing at the commit. Without one, a commit that is already an ancestor of a
+// newer patch fails with ErrStaleRerun rather than minting a misleading next patch.
+func (c BuildContext) previousAssignment(base string) (string, bool, error) {
+	sha, err := c.commit()
+	if err != nil {
+		return "", false, err
+	}
+	inStream := func(v string) bool {
+		pv, err := Parse(v)
+		return err == nil && pv.Patch > 0 && pv.Prefix == strings.TrimSuffix(c.Config.Prefix, "-") &&
+			pv.Base() == base
+	}
+
+	if c.Ledger != nil {
+		ms, err := c.Ledger.Manifests()
+		if err != nil {
+			return "", false, err
+		}
+		for i := len(ms) - 1; i >= 0; i-- {
+			if ms[i].Commit == sha && inStream(ms[i].Version) {
+				return ms[i].Version, true, nil
+			}
+		}
+	}
+
+	out, err := c.git("tag", "--points-at", sha)
+	if err != nil {
+		return "", false, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	for _, t := range InNamespace(strings.Fields(out), c.Config.Namespace) {
+		if inStream(t) {
+			return t, true, nil
+		}
+	}
+
+	ts, err := c.tags()
+	if err != nil {
+		return "", false, err
+	}
+	for _, t := range ts {
+		if !inStream(t) {
+			continue
+		}
+		if _, err := c.git("merge-base", "--is-ancestor", "--end-of-options", sha, "refs/tags/"+c.tagName(t)); err == nil {
+			return "", false, fmt.Errorf("%w: %s already shipped in %s or later on %s; re-run the pipeline for "+
+				"the branch head instead, or tag this commit by hand if it really needs a new patch",
+				ErrStaleRerun, shortSHA(sha), t, c.Branch)
+		}
+	}
+	return "", false, nil
+}
+
+// commit is BuildContext.CommitSHA, falling back to the checked-out HEAD.
+func (c BuildContext) commit() (string, error) {
+	if c.CommitSHA != "" {
+		return c.CommitSHA, nil
+	}
+	sha, err := c.git("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sha), nil
+}
@@ -0,0 +1,63 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRerunReproducesTaggedVersion(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "checkout", "-q", "-b", "release/v20250428.100")
+	old := mustGit(t, "", "rev-parse", "HEAD")
+	mustGit(t, "", "tag", "20250428.100.1")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "fix")
+	mustGit(t, "", "tag", "20250428.100.2")
+
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", Reruns: true}, nil)
+	c.LookupTags, c.CommitSHA = GitTags, old
+	got, err := c.Version()
+	if err != nil || got != "20250428.100.1" {
+		t.Fatalf("got %s, %v want 20250428.100.1", got, err)
+	}
+}
+
+func TestRerunOfSupersededCommitFails(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "checkout", "-q", "-b", "release/v20250428.100")
+	old := mustGit(t, "", "rev-parse", "HEAD")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "fix")
+	mustGit(t, "", "tag", "20250428.100.1")
+
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", Reruns: true}, nil)
+	c.LookupTags, c.CommitSHA = GitTags, old
+	if _, err := c.Version(); !errors.Is(err, ErrStaleRerun) {
+		t.Fatalf("got %v want ErrStaleRerun", err)
+	}
+
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "another fix")
+	c.CommitSHA = mustGit(t, "", "rev-parse", "HEAD")
+	if got, err := c.Version(); err != nil || got != "20250428.100.2" {
+		t.Fatalf("branch head should get the next patch, got %s, %v", got, err)
+	}
+}
+
+func TestRerunInNamespace(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "checkout", "-q", "-b", "release/v20250428.100")
+	old := mustGit(t, "", "rev-parse", "HEAD")
+	mustGit(t, "", "tag", "cli/20250428.100.1")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "fix")
+	mid := mustGit(t, "", "rev-parse", "HEAD")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "fix")
+	mustGit(t, "", "tag", "cli/20250428.100.2")
+
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", Reruns: true, Namespace: "cli"}, nil)
+	c.LookupTags, c.CommitSHA = GitTags, old
+	if got, err := c.Version(); err != nil || got != "20250428.100.1" {
+		t.Fatalf("got %s, %v want 20250428.100.1", got, err)
+	}
+	c.CommitSHA = mid
+	if _, err := c.Version(); !errors.Is(err, ErrStaleRerun) {
+		t.Fatalf("untagged superseded commit: got %v want ErrStaleRerun", err)
+	}
+}
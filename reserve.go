@@ -0,0 +1,146 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// PendingRefs is the ref namespace on origin holding reserved, not yet confirmed versions; each ref points at the
+// commit the version was reserved for.
+const PendingRefs = "refs/versioner/pending/"
+
+// Reserve claims the next version for the build before it runs: the version is computed with pending reservations
+// counted as taken and pushed as PendingRefs<version>, create-only, so two pipelines can't reserve the same one
+// (the loser recomputes, up to Config.PushRetries times). Confirm tags it once the build succeeded, Abandon frees it
+// when the build failed, so failed builds leave neither gaps nor tags pointing at broken artifacts.
+func (c BuildContext) Reserve() (string, error) {
+	c = c.pinTime()
+	sha, err := c.commit()
+	if err != nil {
+		return "", err
+	}
+	backoff := c.Config.PushBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	lookup := c.LookupTags
+	for attempt := 0; ; attempt++ {
+		pending, err := c.pendingVersions()
+		if err != nil {
+			return "", err
+		}
+		c.LookupTags = func() ([]string, error) {
+			var ts []string
+			if lookup != nil {
+				var err error
+				if ts, err = lookup(); err != nil {
+					return nil, err
+				}
+			}
+			return append(ts, pending...), nil
+		}
+		v, err := c.Version()
+		if err != nil {
+			return "", err
+		}
+		ref := PendingRefs + c.tagName(v)
+		err = c.effect("reserve "+v+" as "+ref, func() error {
+			_, err := c.git("push", "--force-with-lease="+ref+":", "origin", sha+":"+ref)
+			return err
+		})
+		if err == nil {
+			return v, nil
+		}
+		if attempt >= c.Config.PushRetries {
+			return "", fmt.Errorf("reserve %s (attempt %d): %w", v, attempt+1, err)
+		}
+		c.debug("reservation lost, recomputing", "version", v, "attempt", attempt+1)
+		time.Sleep(backoff << attempt)
+	}
+}
+
+// Confirm turns a reservation into the release: an annotated tag with the manifest on the reserved commit, pushed
+// to origin and recorded in the Ledger, after which the pending ref is removed. An unknown reservation is
+// ErrNoMatchingTags.
+func (c BuildContext) Confirm(version string) (Manifest, error) {
+	c = c.pinTime()
+	name := c.tagName(version)
+	sha, err := c.reservedCommit(name)
+	if err != nil {
+		return Manifest{}, err
+	}
+	m := Manifest{Version: version, Namespace: c.Config.Namespace, Commit: sha, Time: c.now().UTC(), Metadata: c.Metadata}
+	if err := c.effect("create tag "+name+" on "+shortSHA(sha), func() error { return c.tag(m) }); err != nil {
+		return Manifest{}, err
+	}
+	err = c.effect("push tag "+name+" to origin", func() error {
+		_, err := c.git("push", "origin", "refs/tags/"+name)
+		return err
+	})
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := c.dropReservation(name); err != nil {
+		return Manifest{}, err
+	}
+	return m, c.released(m)
+}
+
+// Abandon releases a reservation without tagging, so the version can be handed out again.
+func (c BuildContext) Abandon(version string) error {
+	name := c.tagName(version)
+	if _, err := c.reservedCommit(name); err != nil {
+		return err
+	}
+	return c.dropReservation(name)
+}
+
+// PendingVersions fetches the reservations from origin and lists their versions, as tag names (see TagName).
+func PendingVersions() ([]string, error) {
+	return BuildContext{}.pendingVersions()
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (c BuildContext) pendingVersions() ([]string, error) {
+	if _, err := c.git("fetch", "-q", "--prune", "origin", "+"+PendingRefs+"*:"+PendingRefs+"*"); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	out, err := c.git("for-each-ref", "--format=%(refname)", PendingRefs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	var vs []string
+	for _, ref := range strings.Fields(out) {
+		vs = append(vs, strings.TrimPrefix(ref, PendingRefs))
+	}
+	return vs, nil
+}
+
+func (c BuildContext) reservedCommit(version string) (string, error) {
+	if err := CheckRefName(version); err != nil {
+		return "", err
+	}
+	if _, err := c.pendingVersions(); err != nil {
+		return "", err
+	}
+	sha, err := c.git("rev-parse", "-q", "--verify", PendingRefs+version+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: no reservation for %s", ErrNoMatchingTags, version)
+	}
+	return strings.TrimSpace(sha), nil
+}
+
+func (c BuildContext) dropReservation(version string) error {
+	ref := PendingRefs + version
+	return c.effect("delete reservation "+ref, func() error {
+		if _, err := c.git("push", "-q", "origin", ":"+ref); err != nil {
+			return err
+		}
+		_, err := c.git("update-ref", "-d", ref)
+		return err
+	})
+}
@@ -0,0 +1,45 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReserveConfirmAbandon(t *testing.T) {
+	origin := gitRepo(t)
+	cfg := Config{DefaultBranch: "main", PushRetries: 2, PushBackoff: time.Millisecond}
+	c := ctx("release/v20250428.100", cfg, nil)
+	c.LookupTags = GitTags
+
+	first, err := c.Reserve()
+	if err != nil || first != "20250428.100.1" {
+		t.Fatalf("got %s, %v", first, err)
+	}
+	// a concurrent pipeline in another clone skips the pending version
+	rival := cloneRepo(t, origin)
+	t.Chdir(rival)
+	second, err := c.Reserve()
+	if err != nil || second != "20250428.100.2" {
+		t.Fatalf("got %s, %v", second, err)
+	}
+
+	if err := c.Abandon(second); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Abandon(second); !errors.Is(err, ErrNoMatchingTags) {
+		t.Fatalf("double abandon: got %v", err)
+	}
+	m, err := c.Confirm(first)
+	if err != nil || m.Version != first || m.Commit == "" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	if got := mustGit(t, "", "ls-remote", "--refs", origin, "refs/tags/*", PendingRefs+"*"); !strings.HasSuffix(got, "\trefs/tags/"+first) ||
+		strings.Contains(got, "\n") {
+		t.Fatalf("want only the confirmed tag on origin, got %q", got)
+	}
+	if next, err := c.Reserve(); err != nil || next != "20250428.100.2" {
+		t.Fatalf("after abandon: got %s, %v", next, err)
+	}
+}
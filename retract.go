@@ -0,0 +1,52 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// RetractedPrefix names the marker tag of a retracted version: "retracted/<version>" ("retracted/<namespace>/<version>"
+// in a namespace), annotated with the reason. The
+// marker travels with the ordinary tag list, so every tag source sees retractions without another lookup.
+const RetractedPrefix = "retracted/"
+
+// Retract marks a released version as bad: the marker tag goes on the same commit and is pushed to origin. The
+// version itself stays tagged, so its artifacts can still be traced, but History, CutRelease, Plan and Train stop
+// treating it as the latest release, and its patch number is never handed out again.
+func (c BuildContext) Retract(version, reason string) error {
+	name := c.tagName(version)
+	if err := CheckRefName(name); err != nil {
+		return err
+	}
+	if !c.tagged(name) {
+		return fmt.Errorf("%w: %s", ErrNoMatchingTags, version)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: retracting %s needs a reason", ErrInvalidConfig, version)
+	}
+	marker := RetractedPrefix + name
+	err := c.effect("create tag "+marker, func() error {
+		_, err := c.git("tag", "-a", "-m", "Retracted "+name+"\n\n"+reason, "--end-of-options", marker, "refs/tags/"+name+"^{commit}")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return c.effect("push tag "+marker+" to origin", func() error {
+		_, err := c.git("push", "origin", "refs/tags/"+marker)
+		return err
+	})
+}
+
+// Retracted returns the versions marked retracted among tags.
+func Retracted(tags []string) map[string]bool {
+	out := map[string]bool{}
+	for _, t := range tags {
+		if v, ok := strings.CutPrefix(t, RetractedPrefix); ok {
+			out[v] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestRetract(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, "", "tag", "20250427.90")
+	mustGit(t, "", "tag", "20250428.100")
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.LookupTags = GitTags
+
+	if err := c.Retract("20250428.100", ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("no reason: got %v", err)
+	}
+	if err := c.Retract("20250428.999", "x"); !errors.Is(err, ErrNoMatchingTags) {
+		t.Fatalf("unknown version: got %v", err)
+	}
+	if err := c.Retract("20250428.100", "corrupt artifacts"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustGit(t, "", "ls-remote", "--refs", origin, "refs/tags/retracted/*"); got == "" {
+		t.Fatal("marker was not pushed")
+	}
+
+	hs, _ := History(HistoryOptions{})
+	if len(hs) != 1 || hs[0].Version.String() != "20250427.90" {
+		t.Fatalf("history: got %+v", hs)
+	}
+	hs, _ = History(HistoryOptions{IncludeRetracted: true})
+	if len(hs) != 2 || !hs[0].Retracted {
+		t.Fatalf("history with retracted: got %+v", hs)
+	}
+	p, err := c.Plan()
+	if err != nil || p.ReleaseBranch != "release/v20250427.90" {
+		t.Fatalf("plan: got %+v, %v", p, err)
+	}
+}
+
+func TestLedgerRetraction(t *testing.T) {
+	st := StateFile{Path: filepath.Join(t.TempDir(), "state.json")}
+	st.Record(Manifest{Version: "20250427.90"})
+	st.Record(Manifest{Version: "20250428.100", Retracted: "bad build"})
+	ts, _ := LedgerTags(st)()
+	if got := fmt.Sprint(ts); got != "[20250427.90 20250428.100 retracted/20250428.100]" {
+		t.Fatalf("got %s", got)
+	}
+	if v, ok := latestDefault(ts, ""); !ok || v.String() != "20250427.90" {
+		t.Fatalf("latest: got %s", v)
+	}
+}
@@ -0,0 +1,128 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Runner starts the external programs the package needs (git, cosign). Run executes name with args in the working
+// directory, with env added to the process environment, and returns what it printed; a non-zero exit is an error,
+// and so is ctx ending first, in which case the process is killed.
+type Runner interface {
+	Run(ctx context.Context, name string, args, env []string) (stdout, stderr string, err error)
+}
+
+// ExecRunner is the default Runner, built on os/exec.
+type ExecRunner struct {
+	// Git is the git binary. Empty resolves "git" on PATH (git.exe through PATHEXT on Windows) and, on Windows, falls
+	// back to the Git for Windows install directories, which runners often leave off PATH.
+	Git string
+}
+
+func (r ExecRunner) Run(ctx context.Context, name string, args, env []string) (string, string, error) {
+	bin := name
+	if name == "git" {
+		bin = r.Git
+		if bin == "" {
+			var err error
+			if bin, err = gitBinary(); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		err = fmt.Errorf("%s %s: %w", name, firstNonEmpty(args...), ctx.Err())
+	}
+	return stdout.String(), stderr.String(), err
+}
+
+// Sandbox is a Runner for services: it passes only the allowed programs and git subcommands on to Next and refuses
+// everything else with ErrSandboxed, so a request can never make the process run arbitrary commands or touch the
+// repository it happens to run in. The zero Sandbox runs nothing.
+type Sandbox struct {
+	Next  Runner              // defaults to ExecRunner{}
+	Allow map[string][]string // program → permitted first arguments (git subcommands); an empty list permits any
+}
+
+func (s Sandbox) Run(ctx context.Context, name string, args, env []string) (string, string, error) {
+	subs, ok := s.Allow[name]
+	if !ok || len(subs) > 0 && (len(args) == 0 || !slices.Contains(subs, args[0])) {
+		return "", "", fmt.Errorf("%w: %s %s", ErrSandboxed, name, firstNonEmpty(args...))
+	}
+	next := s.Next
+	if next == nil {
+		next = ExecRunner{}
+	}
+	return next.Run(ctx, name, args, env)
+}
+
+// SetRunner routes every external command of the package through r (nil restores ExecRunner) and returns the previous
+// runner, so tests can replay another platform's output and services can confine what runs.
+func SetRunner(r Runner) Runner {
+	if r == nil {
+		r = ExecRunner{}
+	}
+	runnerMu.Lock()
+	defer runnerMu.Unlock()
+	prev := runner
+	runner = r
+	return prev
+}
+
+// Git runs a git query in the working directory through the Runner, bounded by DefaultGitTimeout, for callers such as
+// the CLI that need one outside a BuildContext. Failures are *GitError.
+func Git(args ...string) (string, error) {
+	return git(args...)
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var (
+	runnerMu sync.RWMutex
+	runner   Runner = ExecRunner{}
+)
+
+func run(ctx context.Context, name string, args, env []string) (string, string, error) {
+	runnerMu.RLock()
+	r := runner
+	runnerMu.RUnlock()
+	return r.Run(ctx, name, args, env)
+}
+
+var gitBinary = sync.OnceValues(func() (string, error) {
+	bin, err := exec.LookPath("git")
+	if err == nil || runtime.GOOS != "windows" || errors.Is(err, exec.ErrDot) {
+		return bin, err
+	}
+	for _, env := range []string{"ProgramW6432", "ProgramFiles", "LocalAppData"} {
+		dir := os.Getenv(env)
+		if dir == "" {
+			continue
+		}
+		if env == "LocalAppData" {
+			dir = filepath.Join(dir, "Programs")
+		}
+		p := filepath.Join(dir, "Git", "cmd", "git.exe")
+		if _, serr := os.Stat(p); serr == nil {
+			return p, nil
+		}
+	}
+	return "", err
+})
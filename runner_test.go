@@ -0,0 +1,66 @@
+package versioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeRunner answers git invocations from a table keyed by the joined arguments.
+type fakeRunner map[string]string
+
+func (f fakeRunner) Run(ctx context.Context, name string, args, env []string) (string, string, error) {
+	out, ok := f[strings.Join(args, " ")]
+	if name != "git" || !ok {
+		return "", "fatal: unexpected " + name + " " + strings.Join(args, " "), errors.New("exit status 128")
+	}
+	return out, "", nil
+}
+
+func TestRunnerWindowsOutput(t *testing.T) {
+	f := fakeRunner{
+		"tag": "20250427.90\r\n20250428.100\r\n",
+		"for-each-ref refs/tags --format=%(refname:strip=2)%1f%(objectname)%1f%(*objectname)%1f%(creatordate:iso-strict)": "20250428.100\x1fabc\x1f\x1f2025-04-28T10:00:00+02:00\r\n",
+	}
+	prev := SetRunner(f)
+	t.Cleanup(func() { SetRunner(prev) })
+
+	ts, err := GitTags()
+	if err != nil || fmt.Sprint(ts) != "[20250427.90 20250428.100]" {
+		t.Fatalf("got %q, %v", ts, err)
+	}
+	hs, err := History(HistoryOptions{})
+	if err != nil || len(hs) != 1 || hs[0].Time.IsZero() || hs[0].Commit != "abc" {
+		t.Fatalf("got %+v, %v", hs, err)
+	}
+
+	_, err = Git("rev-parse", "HEAD")
+	var ge *GitError
+	if !errors.As(err, &ge) || !strings.Contains(ge.Output, "unexpected git rev-parse HEAD") {
+		t.Fatalf("got %v want *GitError from the runner", err)
+	}
+}
+
+func TestExecRunnerGitOverride(t *testing.T) {
+	_, _, err := ExecRunner{Git: "/nonexistent/git"}.Run(context.Background(), "git", []string{"version"}, nil)
+	if err == nil {
+		t.Fatal("want an error for a missing git binary")
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	s := Sandbox{Next: fakeRunner{"tag": "20250428.100\n"}, Allow: map[string][]string{"git": {"tag"}}}
+	if out, _, err := s.Run(context.Background(), "git", []string{"tag"}, nil); err != nil || out != "20250428.100\n" {
+		t.Fatalf("got %q, %v", out, err)
+	}
+	for _, cmd := range [][]string{{"git", "push", "origin"}, {"git"}, {"cosign", "sign"}} {
+		if _, _, err := s.Run(context.Background(), cmd[0], cmd[1:], nil); !errors.Is(err, ErrSandboxed) {
+			t.Fatalf("%v: got %v want %v", cmd, err, ErrSandboxed)
+		}
+	}
+	if _, _, err := (Sandbox{}).Run(context.Background(), "git", []string{"tag"}, nil); !errors.Is(err, ErrSandboxed) {
+		t.Fatalf("zero sandbox: got %v want %v", err, ErrSandboxed)
+	}
+}
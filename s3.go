@@ -0,0 +1,145 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 is an ObjectStore on an S3 bucket (or an S3-compatible service supporting conditional writes); revisions are
+// ETags and writes carry If-Match, or If-None-Match: * for new objects. Requests are signed with AWS Signature V4.
+type S3 struct {
+	Bucket       string
+	Region       string // e.g. eu-west-1
+	AccessKey    string // $AWS_ACCESS_KEY_ID
+	SecretKey    string // $AWS_SECRET_ACCESS_KEY
+	SessionToken string // $AWS_SESSION_TOKEN for temporary credentials; optional
+	Endpoint     string // defaults to https://<bucket>.s3.<region>.amazonaws.com; path-style when set
+	Client       *http.Client
+	Clock        Clock // signing clock; defaults to SystemClock
+}
+
+func (s S3) Get(ctx context.Context, key string) ([]byte, string, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", ErrObjectNotFound
+	case resp.StatusCode >= 300:
+		return nil, "", newAPIError("s3", http.MethodGet, key, resp)
+	}
+	b, err := io.ReadAll(resp.Body)
+	return b, resp.Header.Get("ETag"), err
+}
+
+func (s S3) Put(ctx context.Context, key string, data []byte, rev string) error {
+	h := map[string]string{"If-Match": rev}
+	if rev == "" {
+		h = map[string]string{"If-None-Match": "*"}
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, data, h)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed, resp.StatusCode == http.StatusConflict:
+		return ErrPreconditionFailed // 409: a concurrent conditional write is still in flight
+	case resp.StatusCode >= 300:
+		return newAPIError("s3", http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+func (s S3) do(ctx context.Context, method, key string, body []byte, headers map[string]string) (*http.Response, error) {
+	u := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, s3Escape(key))
+	if s.Endpoint != "" {
+		u = strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + s3Escape(key)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	clock := s.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	s.sign(req, body, clock.Now().UTC())
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// sign adds an AWS Signature V4 Authorization header covering host, the x-amz-* and conditional headers.
+func (s S3) sign(req *http.Request, body []byte, t time.Time) {
+	sum := sha256.Sum256(body)
+	payload := hex.EncodeToString(sum[:])
+	stamp, day := t.Format("20060102T150405Z"), t.Format("20060102")
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	names := []string{"host"}
+	values := map[string]string{"host": req.URL.Host}
+	for k := range req.Header {
+		lk := strings.ToLower(k)
+		names = append(names, lk)
+		values[lk] = strings.TrimSpace(req.Header.Get(k))
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, n := range names {
+		canonHeaders.WriteString(n + ":" + values[n] + "\n")
+	}
+	signed := strings.Join(names, ";")
+	canon := strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonHeaders.String(), signed, payload}, "\n")
+
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	csum := sha256.Sum256([]byte(canon))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(csum[:])
+	key := []byte("AWS4" + s.SecretKey)
+	for _, part := range []string{day, s.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signed, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape percent-encodes an object key the way SigV4 canonicalizes it: everything but unreserved characters and
+// the slashes.
+func s3Escape(key string) string {
+	var b strings.Builder
+	for _, c := range []byte(key) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', strings.IndexByte("-_.~/", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package versioner
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Toolchains the generated CI configuration installs and runs the CLI with.
+const (
+	DefaultImage = "golang:1.23" // GitLab job image
+	DefaultGo    = "1.23"        // GitHub actions/setup-go version
+)
+
+// ScaffoldOptions parameterizes the CI configuration emitted by `versioner init`.
+type ScaffoldOptions struct {
+	Path    string   // where the file is written, quoted in the usage instructions
+	Image   string   // GitLab: image with a Go toolchain (and git); defaults to DefaultImage
+	Go      string   // GitHub: Go version to set up; defaults to DefaultGo
+	Release string   // module version of the CLI to go install; defaults to "latest"
+	Flags   []string // extra CLI flags for every invocation, e.g. "-prefix", "api"
+	Publish string   // tag job: also create the hosted release ("gitlab" or "github"); empty for tags only
+}
+
+// GitLabCI renders a .gitlab-ci.yml include with a version job publishing $VERSION as a dotenv report to the rest of
+// the pipeline and a tag job pushing the version tag on default and release branches once the pipeline passed.
+func GitLabCI(opts ScaffoldOptions) ([]byte, error) {
+	if opts.Path == "" {
+		opts.Path = ".gitlab/versioner.yml"
+	}
+	return renderScaffold("gitlab-ci.yml", opts)
+}
+
+// GitHubAction renders an action.yml composite action that installs the CLI, maps the workflow context onto the
+// CI_* variables it reads and exposes the version and branch kind as the outputs "version" and "kind". With the input
+// tag set to "true" it also tags and pushes default and release builds.
+func GitHubAction(opts ScaffoldOptions) ([]byte, error) {
+	if opts.Path == "" {
+		opts.Path = ".github/actions/versioner/action.yml"
+	}
+	for _, f := range opts.Flags {
+		// the flags input is word-split by the shell, without quote removal
+		if f == "" || strings.ContainsAny(f, " \t\n\"'\\$`") {
+			return nil, fmt.Errorf("%w: flag %q cannot be passed through an action input", ErrInvalidConfig, f)
+		}
+	}
+	return renderScaffold("action.yml", opts)
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+//go:embed templates/*
+var scaffoldFS embed.FS
+
+// scaffolds use [[ ]] so GitHub's ${{ }} expressions pass through.
+var scaffolds = template.Must(template.New("").Delims("[[", "]]").ParseFS(scaffoldFS, "templates/*"))
+
+func renderScaffold(name string, opts ScaffoldOptions) ([]byte, error) {
+	if opts.Image == "" {
+		opts.Image = DefaultImage
+	}
+	if opts.Go == "" {
+		opts.Go = DefaultGo
+	}
+	if opts.Release == "" {
+		opts.Release = "latest"
+	}
+	if strings.ContainsAny(opts.Image+opts.Go+opts.Release, "\"' \n") {
+		return nil, fmt.Errorf("%w: image %q, go %q, release %q", ErrInvalidConfig, opts.Image, opts.Go, opts.Release)
+	}
+	data := struct {
+		ScaffoldOptions
+		Dir, Args, Words, TagArgs string
+	}{ScaffoldOptions: opts, Dir: path.Dir(opts.Path), Args: shellArgs(opts.Flags), Words: strings.Join(opts.Flags, " ")}
+	switch opts.Publish {
+	case "":
+	case "gitlab", "github":
+		data.TagArgs = shellArgs([]string{"-publish", opts.Publish})
+	default:
+		return nil, fmt.Errorf("%w: unknown release host %q (want gitlab or github)", ErrInvalidConfig, opts.Publish)
+	}
+	var buf bytes.Buffer
+	if err := scaffolds.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// shellArgs renders args as a space-prefixed, single-quoted POSIX shell argument list.
+func shellArgs(args []string) string {
+	var b strings.Builder
+	for _, a := range args {
+		b.WriteByte(' ')
+		if a != "" && strings.Trim(a, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,@") == "" {
+			b.WriteString(a)
+			continue
+		}
+		b.WriteString("'" + strings.ReplaceAll(a, "'", `'\''`) + "'")
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package versioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGitLabCI(t *testing.T) {
+	b, err := GitLabCI(ScaffoldOptions{Flags: []string{"-prefix", "api", "-label", "it's"}, Publish: "gitlab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, want := range []string{
+		"- local: .gitlab/versioner.yml",
+		`VERSIONER_IMAGE: "` + DefaultImage + `"`,
+		`VERSION=$(versioner version -prefix api -label 'it'\''s')`,
+		`go install "$VERSIONER_MODULE"`,
+		"github.com/drew-mcl/test/cmd/versioner@latest",
+		"dotenv: versioner.env",
+		`- versioner tag -prefix api -label 'it'\''s' -publish gitlab`,
+		"$CI_COMMIT_BRANCH =~ /^release\\//",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestScaffoldErrors(t *testing.T) {
+	for _, opts := range []ScaffoldOptions{{Publish: "bitbucket"}, {Image: "a\"b"}, {Release: "v1 x"}} {
+		if _, err := GitLabCI(opts); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%+v: got %v want ErrInvalidConfig", opts, err)
+		}
+	}
+}
+
+func TestGitHubAction(t *testing.T) {
+	b, err := GitHubAction(ScaffoldOptions{Flags: []string{"-prefix", "api"}, Publish: "github", Release: "v1.2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, want := range []string{
+		"uses: ./.github/actions/versioner",
+		`default: "-prefix api"`,
+		"value: ${{ steps.version.outputs.version }}",
+		`go-version: "` + DefaultGo + `"`,
+		"github.com/drew-mcl/test/cmd/versioner@${{ inputs.release }}",
+		`default: "v1.2.0"`,
+		`echo "version=$version" >> "$GITHUB_OUTPUT"`,
+		"run: versioner tag $VERSIONER_FLAGS -publish github",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in\n%s", want, out)
+		}
+	}
+	if _, err := GitHubAction(ScaffoldOptions{Flags: []string{"-label", "it's"}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want ErrInvalidConfig", err)
+	}
+}
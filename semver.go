@@ -0,0 +1,223 @@
+package versioner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Bump is the SemVer component a release increments.
+type Bump string
+
+const (
+	BumpPatch Bump = "patch" // fixes and anything else
+	BumpMinor Bump = "minor" // "feat:" commits
+	BumpMajor Bump = "major" // "type!:" or a "BREAKING CHANGE:" footer
+)
+
+// SemVer is a MAJOR.MINOR.PATCH release number.
+type SemVer struct {
+	Major, Minor, Patch int
+}
+
+func (s SemVer) String() string { return fmt.Sprintf("%d.%d.%d", s.Major, s.Minor, s.Patch) }
+
+// Next increments s by b, resetting the lower components.
+func (s SemVer) Next(b Bump) SemVer {
+	switch b {
+	case BumpMajor:
+		return SemVer{s.Major + 1, 0, 0}
+	case BumpMinor:
+		return SemVer{s.Major, s.Minor + 1, 0}
+	default:
+		return SemVer{s.Major, s.Minor, s.Patch + 1}
+	}
+}
+
+// Less orders s before o.
+func (s SemVer) Less(o SemVer) bool {
+	if s.Major != o.Major {
+		return s.Major < o.Major
+	}
+	if s.Minor != o.Minor {
+		return s.Minor < o.Minor
+	}
+	return s.Patch < o.Patch
+}
+
+// ParseSemVer reads a final "[<prefix>-]MAJOR.MINOR.PATCH" tag; pre-releases are rejected with ErrInvalidVersion.
+func ParseSemVer(s string) (prefix string, v SemVer, err error) {
+	m := semverRE.FindStringSubmatch(s)
+	if m == nil {
+		return "", SemVer{}, fmt.Errorf("%w: %q is not MAJOR.MINOR.PATCH", ErrInvalidVersion, s)
+	}
+	v.Major, _ = strconv.Atoi(m[2])
+	v.Minor, _ = strconv.Atoi(m[3])
+	v.Patch, _ = strconv.Atoi(m[4])
+	return m[1], v, nil
+}
+
+// ParseLegacySemVer reads a "[<prefix>-]vMAJOR.MINOR.PATCH" tag as written by most SemVer tooling before versioner
+// took over a repository. Config.LegacyTags counts such tags as releases of the same number.
+func ParseLegacySemVer(s string) (prefix string, v SemVer, err error) {
+	m := legacySemverRE.FindStringSubmatch(s)
+	if m == nil {
+		return "", SemVer{}, fmt.Errorf("%w: %q is not vMAJOR.MINOR.PATCH", ErrInvalidVersion, s)
+	}
+	v.Major, _ = strconv.Atoi(m[2])
+	v.Minor, _ = strconv.Atoi(m[3])
+	v.Patch, _ = strconv.Atoi(m[4])
+	return m[1], v, nil
+}
+
+// DecideBump maps conventional commits to a bump the way semantic-release does: any breaking change is major, any
+// feat is minor, everything else patch. Every build gets a version, so there is no "no release" outcome.
+func DecideBump(cs Changes) Bump {
+	b := BumpPatch
+	for _, c := range cs {
+		switch {
+		case c.Breaking:
+			return BumpMajor
+		case c.Type == "feat":
+			b = BumpMinor
+		}
+	}
+	return b
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+var (
+	semverRE       = regexp.MustCompile(`^(?:([^.]+?)-)?(\d+)\.(\d+)\.(\d+)$`)
+	legacySemverRE = regexp.MustCompile(`^(?:([^.]+?)-)?v(\d+)\.(\d+)\.(\d+)$`)
+	semverLineRE   = regexp.MustCompile(`^release/v(\d+)\.(\d+)$`)
+	semverBuildRE  = regexp.MustCompile(`^(?:([^.]+?)-)?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+)
+
+// parseSemVerBuild reads any SemVer-mode version, pre-releases and build metadata included, into its prefix, core
+// and pre-release.
+func parseSemVerBuild(s string) (prefix string, v SemVer, pre string, err error) {
+	m := semverBuildRE.FindStringSubmatch(s)
+	if m == nil {
+		return "", SemVer{}, "", fmt.Errorf("%w: %q is not MAJOR.MINOR.PATCH[-pre][+meta]", ErrInvalidVersion, s)
+	}
+	v.Major, _ = strconv.Atoi(m[2])
+	v.Minor, _ = strconv.Atoi(m[3])
+	v.Patch, _ = strconv.Atoi(m[4])
+	return m[1], v, m[5], nil
+}
+
+// checkSemVerMonotonic is checkMonotonic for SemVer mode: v must sort after the latest final tag of its prefix, on
+// its MAJOR.MINOR line for release branches. A pre-release sorts before the final version of its core.
+func checkSemVerMonotonic(v string, release bool, tags []string) (latestTag string, err error) {
+	prefix, cur, _, err := parseSemVerBuild(v)
+	if err != nil {
+		return "", err
+	}
+	var line *SemVer
+	if release {
+		line = &SemVer{cur.Major, cur.Minor, 0}
+	}
+	latest, tag, ok := latestSemVer(tags, prefix, line, false)
+	if !ok {
+		return "", nil
+	}
+	if !latest.Less(cur) {
+		return tag, &MonotonicityError{Version: v, Latest: tag}
+	}
+	return tag, nil
+}
+
+// semver computes the SemVer-mode version: the default branch releases the next version after the latest tag,
+// release/vMAJOR.MINOR branches the next patch of that line, and feature builds are '<next>-<build>…' pre-releases.
+func (c BuildContext) semver() (string, error) {
+	ts, err := c.tags()
+	if err != nil {
+		return "", err
+	}
+	prefix := strings.TrimSuffix(c.Config.Prefix, "-")
+	kind := Classify(c.Config, c.Branch)
+
+	if kind == KindRelease {
+		m := semverLineRE.FindStringSubmatch(c.Branch)
+		if m == nil {
+			return "", fmt.Errorf("%w: %s (want release/vMAJOR.MINOR in SemVer mode)", ErrInvalidReleaseBranch, c.Branch)
+		}
+		major, _ := strconv.Atoi(m[1])
+		minor, _ := strconv.Atoi(m[2])
+		next := SemVer{major, minor, 0}
+		if latest, _, ok := latestSemVer(ts, prefix, &next, c.Config.LegacyTags); ok {
+			next = latest.Next(BumpPatch)
+		}
+		return addPrefix(next.String(), prefix), nil
+	}
+
+	latest, tag, _ := latestSemVer(ts, prefix, nil, c.Config.LegacyTags)
+	bump := c.Config.Bump
+	if bump == "" {
+		since := tag
+		if since != "" {
+			since = c.tagName(since)
+		}
+		cs, err := c.changes(since)
+		if err != nil {
+			return "", err
+		}
+		bump = DecideBump(cs)
+	}
+	switch bump {
+	case BumpMajor, BumpMinor, BumpPatch:
+	default:
+		return "", fmt.Errorf("%w: bump %q (want major, minor or patch)", ErrInvalidConfig, bump)
+	}
+	next := latest.Next(bump)
+	c.debug("semver bump decided", "latest", tag, "bump", bump, "next", next)
+	if kind == KindDefault {
+		return addPrefix(next.String(), prefix), nil
+	}
+
+	build, err := c.build()
+	if err != nil {
+		return "", err
+	}
+	extra, err := c.featureExtras(build)
+	if err != nil {
+		return "", err
+	}
+	return addPrefix(next.String()+"-"+build+extra, prefix), nil
+}
+
+// latestSemVer is the highest final tag of prefix, restricted to line's MAJOR.MINOR when line is set. A repository
+// without one starts at 0.0.0. With legacy, 'vX.Y.Z' tags count too; a versioner tag wins a tie, so the changes since
+// a release are read from the tag versioner wrote.
+func latestSemVer(ts []string, prefix string, line *SemVer, legacy bool) (SemVer, string, bool) {
+	var best SemVer
+	var tag string
+	bestLegacy := false
+	for _, t := range ts {
+		p, v, err := ParseSemVer(t)
+		isLegacy := false
+		if err != nil && legacy {
+			p, v, err = ParseLegacySemVer(t)
+			isLegacy = true
+		}
+		if err != nil || p != prefix || line != nil && (v.Major != line.Major || v.Minor != line.Minor) {
+			continue
+		}
+		if tag == "" || best.Less(v) || v == best && bestLegacy && !isLegacy {
+			best, tag, bestLegacy = v, t, isLegacy
+		}
+	}
+	return best, tag, tag != ""
+}
+
+// changes runs LookupChanges, defaulting to the git log from tag since (all history when empty) to HEAD.
+func (c BuildContext) changes(since string) (Changes, error) {
+	if c.LookupChanges != nil {
+		return c.LookupChanges(since)
+	}
+	return c.changesBetween(since, "HEAD")
+}
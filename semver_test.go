@@ -0,0 +1,139 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSemVerBump(t *testing.T) {
+	tags := []string{"1.2.3", "1.10.0", "1.9.9", "svc-4.0.0", "20250101.5"}
+	for _, tc := range []struct {
+		branch string
+		cfg    Config
+		cs     Changes
+		want   string
+	}{
+		{"main", Config{}, Changes{{Type: "fix"}}, "1.10.1"},
+		{"main", Config{}, Changes{{Type: "fix"}, {Type: "feat"}}, "1.11.0"},
+		{"main", Config{}, Changes{{Type: "feat"}, {Type: "fix", Breaking: true}}, "2.0.0"},
+		{"main", Config{}, nil, "1.10.1"},
+		{"main", Config{Bump: BumpMajor}, Changes{{Type: "fix"}}, "2.0.0"},
+		{"main", Config{Prefix: "svc"}, Changes{{Type: "feat"}}, "svc-4.1.0"},
+		{"main", Config{Prefix: "new"}, nil, "new-0.0.1"},
+		{"feat/x", Config{FeatureSuffix: "SNAPSHOT"}, Changes{{Type: "feat"}}, "1.11.0-321-SNAPSHOT"},
+		{"release/v1.9", Config{}, nil, "1.9.10"},
+		{"release/v1.4", Config{}, nil, "1.4.0"},
+	} {
+		tc.cfg.DefaultBranch, tc.cfg.SemVer = "main", true
+		c := ctx(tc.branch, tc.cfg, tags)
+		c.LookupChanges = func(since string) (Changes, error) { return tc.cs, nil }
+		if got, err := c.Version(); err != nil || got != tc.want {
+			t.Fatalf("%s %+v: got %s want %s (%v)", tc.branch, tc.cs, got, tc.want, err)
+		}
+	}
+}
+
+func TestSemVerErrors(t *testing.T) {
+	c := ctx("release/v1", Config{DefaultBranch: "main", SemVer: true}, nil)
+	if _, err := c.Version(); !errors.Is(err, ErrInvalidReleaseBranch) {
+		t.Fatalf("release branch without minor: got %v", err)
+	}
+	c = ctx("main", Config{DefaultBranch: "main", SemVer: true, Bump: "huge"}, nil)
+	if _, err := c.Version(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("bad bump: got %v", err)
+	}
+	if _, _, err := ParseSemVer("1.2.3-rc.1"); !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("pre-release: got %v", err)
+	}
+}
+
+func TestSemVerLegacyTags(t *testing.T) {
+	tags := []string{"v1.2.3", "v1.4.0", "api-v3.1.0", "1.3.0", "v0.9"}
+	for _, tc := range []struct {
+		branch string
+		cfg    Config
+		want   string
+		since  string
+	}{
+		{"main", Config{}, "1.3.1", "1.3.0"},
+		{"main", Config{LegacyTags: true}, "1.4.1", "v1.4.0"},
+		{"main", Config{LegacyTags: true, Prefix: "api"}, "api-3.1.1", "api-v3.1.0"},
+		{"release/v1.2", Config{LegacyTags: true}, "1.2.4", ""},
+	} {
+		tc.cfg.DefaultBranch, tc.cfg.SemVer = "main", true
+		c := ctx(tc.branch, tc.cfg, tags)
+		var since string
+		c.LookupChanges = func(s string) (Changes, error) { since = s; return nil, nil }
+		if got, err := c.Version(); err != nil || got != tc.want || since != tc.since {
+			t.Fatalf("%s %+v: got %s since %q want %s since %q (%v)", tc.branch, tc.cfg, got, since, tc.want, tc.since, err)
+		}
+	}
+
+	// A versioner tag wins the tie with the legacy tag of the same release.
+	if _, tag, _ := latestSemVer([]string{"v2.0.0", "2.0.0", "v2.0.0"}, "", nil, true); tag != "2.0.0" {
+		t.Fatalf("tie: got %s want 2.0.0", tag)
+	}
+	if _, _, err := ParseLegacySemVer("1.2.3"); !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("unprefixed: got %v", err)
+	}
+}
+
+func TestSemVerBuildInfo(t *testing.T) {
+	tags := []string{"svc-1.2.3"}
+	for _, tc := range []struct {
+		branch, want string
+		build, patch int
+		suffix       string
+	}{
+		{"main", "svc-1.2.4", 0, 4, ""},
+		{"feat/x", "svc-1.2.4-321-SNAPSHOT", 321, 4, "SNAPSHOT"},
+	} {
+		c := ctx(tc.branch, Config{DefaultBranch: "main", SemVer: true, Prefix: "svc", FeatureSuffix: "SNAPSHOT"}, tags)
+		c.LookupChanges = func(string) (Changes, error) { return nil, nil }
+		bi, err := c.BuildInfo()
+		if err != nil || bi.Version != tc.want || bi.Build != tc.build || bi.Patch != tc.patch || bi.Suffix != tc.suffix ||
+			bi.Prefix != "svc" || bi.Date != "20250428" {
+			t.Fatalf("%s: got %+v (%v)", tc.branch, bi, err)
+		}
+	}
+}
+
+func TestSemVerMonotonic(t *testing.T) {
+	for _, branch := range []string{"main", "feat/x", "release/v1.1"} {
+		c := ctx(branch, Config{DefaultBranch: "main", SemVer: true, Monotonic: true, Bump: BumpPatch}, []string{"1.1.0", "1.2.3"})
+		if v, err := c.Version(); err != nil {
+			t.Fatalf("%s: got %s, %v", branch, v, err)
+		}
+	}
+	for _, tc := range []struct {
+		v       string
+		release bool
+		latest  string
+	}{
+		{"1.2.3", false, "1.2.3"},
+		{"1.2.3-321-feature", false, "1.2.3"}, // a pre-release sorts before its final version
+		{"1.1.9", false, "1.2.3"},
+		{"1.1.0", true, "1.1.0"},
+	} {
+		var me *MonotonicityError
+		if _, err := checkSemVerMonotonic(tc.v, tc.release, []string{"1.1.0", "1.2.3", "api-9.0.0"}); !errors.As(err, &me) ||
+			me.Latest != tc.latest {
+			t.Fatalf("%s: got %v want latest %s", tc.v, err, tc.latest)
+		}
+	}
+	if _, err := checkSemVerMonotonic("1.1.1+abc", true, []string{"1.1.0", "1.2.3"}); err != nil {
+		t.Fatalf("next patch on its line: got %v", err)
+	}
+}
+
+func TestSemVerNamespace(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "cli/1.0.0")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "feat: flag")
+	mustGit(t, "", "tag", "1.0.0") // another product's tag, after the change
+	c := ctx("main", Config{DefaultBranch: "main", SemVer: true, Namespace: "cli"}, nil)
+	c.LookupTags = GitTags
+	if got, err := c.Version(); err != nil || got != "1.1.0" {
+		t.Fatalf("got %s, %v want 1.1.0", got, err)
+	}
+}
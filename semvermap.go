@@ -0,0 +1,68 @@
+package versioner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// ToSemVer maps v onto a SemVer 2.0 string for tooling built on SemVer libraries (Masterminds/semver and the like):
+// MAJOR is the date (plus epoch × 10⁸), MINOR the build, PATCH the release patch, the suffix becomes the pre-release
+// and the commit the build metadata. SemVer precedence of the result equals Compare. The prefix and zero padding are
+// dropped; a suffix that is not a valid SemVer pre-release (e.g. containing '_') is ErrInvalidVersion.
+func ToSemVer(v Version) (string, error) {
+	if v.Date == "" {
+		return "", fmt.Errorf("%w: empty version", ErrInvalidVersion)
+	}
+	date, _ := strconv.Atoi(v.Date)
+	s := fmt.Sprintf("%d.%d.%d", v.Epoch*epochMajor+date, v.Build, v.Patch)
+	if v.Suffix != "" {
+		for _, id := range strings.Split(v.Suffix, ".") {
+			if !preIdentRE.MatchString(id) || len(id) > 1 && id[0] == '0' && isDigits(id) {
+				return "", fmt.Errorf("%w: suffix %q is not a SemVer pre-release", ErrInvalidVersion, v.Suffix)
+			}
+		}
+		s += "-" + v.Suffix
+	}
+	if v.Commit != "" {
+		s += "+" + v.Commit
+	}
+	return s, nil
+}
+
+// FromSemVer is the inverse of ToSemVer (without prefix or padding). A leading 'v' is accepted; a MAJOR that is not a
+// real date, or build metadata that is not a commit SHA, is ErrInvalidVersion.
+func FromSemVer(s string) (Version, error) {
+	m := semverFullRE.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("%w: %q is not SemVer", ErrInvalidVersion, s)
+	}
+	major, err := strconv.Atoi(m[1])
+	if err != nil {
+		return Version{}, fmt.Errorf("%w: %s: %v", ErrInvalidVersion, s, err)
+	}
+	v := Version{Epoch: major / epochMajor, Date: fmt.Sprintf("%08d", major%epochMajor), Suffix: m[4], Commit: m[5]}
+	v.Build, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	if err := Validate(v.String()); err != nil {
+		return Version{}, fmt.Errorf("%w: %s does not map to a dated version", ErrInvalidVersion, s)
+	}
+	return v, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// epochMajor keeps every epoch above any YYYYMMDD date in the SemVer MAJOR.
+const epochMajor = 100000000
+
+var (
+	preIdentRE   = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+	semverFullRE = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9a-f]+))?$`)
+)
+
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
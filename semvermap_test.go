@@ -0,0 +1,60 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSemVerMapping(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"20250428.321", "20250428.321.0"},
+		{"20250428.100.2", "20250428.100.2"},
+		{"2!20250428.000321.01", "220250428.321.1"},
+		{"20250428.321-feat-x.SNAPSHOT+0a1b2c3d", "20250428.321.0-feat-x.SNAPSHOT+0a1b2c3d"},
+	} {
+		v, _ := Parse(tc.in)
+		got, err := ToSemVer(v)
+		if err != nil || got != tc.want {
+			t.Fatalf("ToSemVer(%s): got %s want %s (%v)", tc.in, got, tc.want, err)
+		}
+		back, err := FromSemVer("v" + got)
+		if err != nil || Compare(back, v) != 0 || back.Commit != v.Commit {
+			t.Fatalf("FromSemVer(%s): got %+v (%v)", got, back, err)
+		}
+	}
+
+	for _, bad := range []string{"20250428.1-a_b", "20250428.1-rc.01"} {
+		v, _ := Parse(bad)
+		if _, err := ToSemVer(v); !errors.Is(err, ErrInvalidVersion) {
+			t.Fatalf("ToSemVer(%s): got %v", bad, err)
+		}
+	}
+	for _, bad := range []string{"1.2.3", "20251340.1.0", "20250428.1"} {
+		if _, err := FromSemVer(bad); !errors.Is(err, ErrInvalidVersion) {
+			t.Fatalf("FromSemVer(%s): got %v", bad, err)
+		}
+	}
+}
+
+func TestComparePreRelease(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"20250428.1-rc.9", "20250428.1-rc.10", -1},
+		{"20250428.1-rc.1", "20250428.1-rc", 1},
+		{"20250428.1-2", "20250428.1-alpha", -1},
+		{"20250428.1-beta", "20250428.1-alpha.5", 1},
+	} {
+		a, _ := Parse(tc.a)
+		b, _ := Parse(tc.b)
+		if got := Compare(a, b); got != tc.want {
+			t.Fatalf("Compare(%s, %s) = %d want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+	a, _ := Parse("cli-20250428.100")
+	b, _ := Parse("20250428.100")
+	if CompareTotal(a, b) != 1 || CompareTotal(b, a) != -1 || CompareTotal(a, a) != 0 {
+		t.Fatalf("CompareTotal must break Compare's prefix tie")
+	}
+}
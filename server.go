@@ -0,0 +1,235 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Server is a central versioning authority for organisations that want one service handing out versions instead of
+// per-repository logic. It answers POST /v1/version and uses the versions recorded in its Ledger, per project, in
+// place of git tags. Version, Reserve and List are what a gRPC front end for proto/versioner/v1 calls; that file is
+// only the schema, no stubs or gRPC listener ship.
+type Server struct {
+	Config  Config   // defaults; non-zero fields of a request's config override them
+	Ledger  Ledger   // required; recorded versions act as the project's tag set
+	Clock   Clock    // reads each request's time; defaults to SystemClock
+	Metrics *Metrics // optional; served on GET /metrics
+
+	mu sync.Mutex // serializes compute-and-record so concurrent release builds never share a patch
+}
+
+// ServiceConfig is the per-request subset of Config a client may set.
+type ServiceConfig struct {
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Prefix        string `json:"prefix,omitempty"`
+	FeatureSuffix string `json:"feature_suffix,omitempty"`
+	Timezone      string `json:"timezone,omitempty"`
+	BranchSlug    bool   `json:"branch_slug,omitempty"`
+	MergeRequest  bool   `json:"merge_request,omitempty"`
+	CommitMeta    bool   `json:"commit_meta,omitempty"`
+	Monotonic     bool   `json:"monotonic,omitempty"`
+	NoCollisions  bool   `json:"no_collisions,omitempty"`
+}
+
+// VersionRequest is the body of POST /v1/version.
+type VersionRequest struct {
+	Project    string            `json:"project"` // namespace in the ledger, e.g. "grp/app"
+	Branch     string            `json:"branch"`
+	PipelineID string            `json:"pipeline_id"`
+	Commit     string            `json:"commit,omitempty"`
+	MergeReqID string            `json:"merge_request_id,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Config     ServiceConfig     `json:"config"`
+	Record     bool              `json:"record,omitempty"` // claim the version in the ledger
+}
+
+// VersionResponse is the answer to POST /v1/version.
+type VersionResponse struct {
+	Version  string   `json:"version"`
+	Kind     string   `json:"kind"`
+	Final    bool     `json:"final"`
+	Recorded bool     `json:"recorded"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// PatchReserver is implemented by ledgers that allocate release patches atomically (SQLLedger), so several Server
+// replicas can share one database; the mutex only serializes a single process.
+type PatchReserver interface {
+	ReserveNext(ctx context.Context, project, base string) (int, error)
+}
+
+// ProjectKey is the Manifest.Metadata key under which Server records the requesting project.
+const ProjectKey = "project"
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/metrics" && s.Metrics != nil {
+		s.Metrics.ServeHTTP(w, r)
+		return
+	}
+	if r.URL.Path != "/v1/version" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return
+	}
+	var req VersionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp, err := s.Version(req)
+	if err != nil {
+		writeJSONError(w, statusFor(err), err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Version computes (and with req.Record claims) the version for req.
+func (s *Server) Version(req VersionRequest) (VersionResponse, error) {
+	if req.Project == "" || req.Branch == "" {
+		return VersionResponse{}, fmt.Errorf("%w: project and branch are required", ErrInvalidConfig)
+	}
+	c := BuildContext{
+		Branch:     req.Branch,
+		PipelineID: req.PipelineID,
+		CommitSHA:  req.Commit,
+		MergeReqID: req.MergeReqID,
+		Clock:      s.Clock,
+		Config:     s.config(req.Config),
+		Metadata:   map[string]string{ProjectKey: req.Project},
+		Metrics:    s.Metrics,
+		LookupTags: func() ([]string, error) { return s.projectVersions(req.Project) },
+		LookupBuild: func() (string, error) {
+			return "", errors.New("pipeline_id is required")
+		},
+	}
+	for k, v := range req.Metadata {
+		if k != ProjectKey {
+			c.Metadata[k] = v
+		}
+	}
+
+	if req.Record {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	kind := Classify(c.Config, c.Branch)
+	if r, ok := s.Ledger.(PatchReserver); ok && req.Record && kind == KindRelease {
+		v, err := reservePatch(r, req.Project, c)
+		if err != nil {
+			return VersionResponse{}, err
+		}
+		c.Config.ForceVersion = v
+	}
+	m, err := c.Manifest()
+	if err != nil {
+		return VersionResponse{}, err
+	}
+	resp := VersionResponse{Version: m.Version, Kind: kind.String(), Final: kind.Final(), Manifest: m}
+	if req.Record {
+		if err := s.Ledger.Record(m); err != nil {
+			return VersionResponse{}, err
+		}
+		resp.Recorded = true
+	}
+	return resp, nil
+}
+
+// Reserve is Version with req.Record set: the ReserveVersion RPC.
+func (s *Server) Reserve(req VersionRequest) (VersionResponse, error) {
+	req.Record = true
+	return s.Version(req)
+}
+
+// List returns the manifests recorded for project, oldest first: the ListVersions RPC.
+func (s *Server) List(project string) ([]Manifest, error) {
+	ms, err := s.Ledger.Manifests()
+	if err != nil {
+		return nil, err
+	}
+	var out []Manifest
+	for _, m := range ms {
+		if m.Metadata[ProjectKey] == project {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (s *Server) config(rc ServiceConfig) Config {
+	cfg := s.Config
+	cfg.Submodules, cfg.Reruns, cfg.Changelog, cfg.ChangelogMR = false, false, "", false // these need the repository, which the service lacks
+	for _, o := range []struct {
+		dst *string
+		src string
+	}{{&cfg.DefaultBranch, rc.DefaultBranch}, {&cfg.Prefix, rc.Prefix}, {&cfg.FeatureSuffix, rc.FeatureSuffix},
+		{&cfg.Timezone, rc.Timezone}} {
+		if o.src != "" {
+			*o.dst = o.src
+		}
+	}
+	cfg.BranchSlug = cfg.BranchSlug || rc.BranchSlug
+	cfg.MergeRequest = cfg.MergeRequest || rc.MergeRequest
+	cfg.CommitMeta = cfg.CommitMeta || rc.CommitMeta
+	cfg.Monotonic = cfg.Monotonic || rc.Monotonic
+	cfg.NoCollisions = cfg.NoCollisions || rc.NoCollisions
+	return cfg
+}
+
+func (s *Server) projectVersions(project string) ([]string, error) {
+	ms, err := s.List(project)
+	var vs []string
+	for _, m := range ms {
+		vs = append(vs, m.Version)
+	}
+	return vs, err
+}
+
+// reservePatch builds the release version for c around a patch number reserved in r.
+func reservePatch(r PatchReserver, project string, c BuildContext) (string, error) {
+	m := relBranchRE.FindStringSubmatch(c.Branch)
+	if m == nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidReleaseBranch, c.Branch)
+	}
+	v, err := Parse(m[1])
+	if err != nil {
+		return "", err
+	}
+	if v.Patch, err = r.ReserveNext(context.Background(), project, m[1]); err != nil {
+		return "", err
+	}
+	v.Prefix, v.Epoch, v.PatchWidth = strings.TrimSuffix(c.Config.Prefix, "-"), c.Config.Epoch, c.Config.PatchWidth
+	return v.String(), nil
+}
+
+func statusFor(err error) int {
+	var me *MonotonicityError
+	switch {
+	case errors.Is(err, ErrInvalidConfig), errors.Is(err, ErrInvalidReleaseBranch), errors.Is(err, ErrInvalidVersion):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrVersionExists), errors.As(err, &me):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,99 @@
+package versioner
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerVersion(t *testing.T) {
+	s := &Server{
+		Config: Config{DefaultBranch: "main"},
+		Ledger: FileLedger{Path: filepath.Join(t.TempDir(), "ledger.jsonl")},
+		Clock:  ClockFunc(func() time.Time { return now }),
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	post := func(req VersionRequest) (int, VersionResponse) {
+		t.Helper()
+		b, _ := json.Marshal(req)
+		r, err := http.Post(srv.URL+"/v1/version", "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+		var resp VersionResponse
+		json.NewDecoder(r.Body).Decode(&resp)
+		return r.StatusCode, resp
+	}
+
+	code, resp := post(VersionRequest{Project: "grp/app", Branch: "main", PipelineID: "100", Record: true})
+	if code != 200 || resp.Version != "20250428.100" || resp.Kind != "default" || !resp.Recorded {
+		t.Fatalf("default: %d %+v", code, resp)
+	}
+	for _, want := range []string{"20250428.100.1", "20250428.100.2"} {
+		_, resp = post(VersionRequest{Project: "grp/app", Branch: "release/v20250428.100", Record: true})
+		if resp.Version != want || !resp.Final {
+			t.Fatalf("got %+v want %s", resp, want)
+		}
+	}
+	// another project's history is separate
+	if _, resp = post(VersionRequest{Project: "grp/other", Branch: "release/v20250428.100"}); resp.Version != "20250428.100.1" {
+		t.Fatalf("other project got %s", resp.Version)
+	}
+
+	if code, _ = post(VersionRequest{Project: "grp/app", Branch: "release/x"}); code != http.StatusBadRequest {
+		t.Fatalf("bad branch: got %d want 400", code)
+	}
+	code, _ = post(VersionRequest{Project: "grp/app", Branch: "main", PipelineID: "100",
+		Config: ServiceConfig{NoCollisions: true}})
+	if code != http.StatusConflict {
+		t.Fatalf("collision: got %d want 409", code)
+	}
+}
+
+func TestServerReserveAndList(t *testing.T) {
+	s := &Server{Config: Config{DefaultBranch: "main"}, Ledger: FileLedger{Path: filepath.Join(t.TempDir(), "l.jsonl")}}
+	for _, p := range []string{"a", "b", "a"} {
+		if _, err := s.Reserve(VersionRequest{Project: p, Branch: "main", PipelineID: "7"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ms, err := s.List("a")
+	if err != nil || len(ms) != 2 || ms[0].Metadata[ProjectKey] != "a" {
+		t.Fatalf("got %+v, %v", ms, err)
+	}
+}
+
+func TestProtoMirrorsStructs(t *testing.T) {
+	b, err := os.ReadFile("proto/versioner/v1/versioner.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for msg, v := range map[string]any{"Manifest": Manifest{}, "Config": ServiceConfig{}} {
+		m := regexp.MustCompile(`(?s)\nmessage ` + msg + ` \{\n(.*?)\n\}`).FindSubmatch(b)
+		if m == nil {
+			t.Fatalf("message %s missing", msg)
+		}
+		fields := map[string]bool{}
+		for _, f := range regexp.MustCompile(`(\w+) = \d+;`).FindAllSubmatch(m[1], -1) {
+			fields[string(f[1])] = true
+		}
+		typ := reflect.TypeOf(v)
+		for i := range typ.NumField() {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if name != "-" && !fields[name] {
+				t.Errorf("message %s lacks %s.%s (%s)", msg, typ.Name(), typ.Field(i).Name, name)
+			}
+		}
+	}
+}
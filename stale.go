@@ -0,0 +1,76 @@
+package versioner
+
+import (
+	"fmt"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// StaleOptions sets the thresholds of StaleTags; a zero threshold disables its rule.
+type StaleOptions struct {
+	HistoryOptions // the versions considered; Limit is ignored
+
+	MaxAge     time.Duration // flag tags created longer ago than this
+	Superseded int           // flag versions with at least this many newer patches on their release line
+	Clock      Clock         // the time ages are measured from; defaults to SystemClock
+}
+
+// StaleTag is a prune candidate: a final version tag and why it was flagged.
+type StaleTag struct {
+	Tag     string    `json:"tag"` // in HistoryOptions.Namespace
+	Commit  string    `json:"commit"`
+	Time    time.Time `json:"time"`
+	Reasons []string  `json:"reasons"`
+}
+
+// StaleTags reports the final tags selected by opts that are older than MaxAge or superseded by Superseded newer
+// patches, newest first, for repository hygiene; it deletes nothing. The newest release always stays off the
+// report, and so does the default build a release line descends from while a patch of that line does: release
+// branches need it to number their next patch.
+func StaleTags(opts StaleOptions) ([]StaleTag, error) {
+	if opts.MaxAge < 0 || opts.Superseded < 0 {
+		return nil, fmt.Errorf("%w: stale thresholds must not be negative", ErrInvalidConfig)
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	now := opts.Clock.Now()
+	opts.Limit = 0
+	hs, err := History(opts.HistoryOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	newer := map[string]int{} // per base: the patches seen so far, newest first
+	kept := map[string]bool{} // bases with a patch that is not stale
+	var stale []StaleTag
+	for i, h := range hs {
+		base := h.Version.Base()
+		var reasons []string
+		if opts.MaxAge > 0 && now.Sub(h.Time) > opts.MaxAge {
+			reasons = append(reasons, fmt.Sprintf("created %s, older than %s", h.Time.Format(time.DateOnly), inDays(opts.MaxAge)))
+		}
+		if n := newer[base]; opts.Superseded > 0 && n >= opts.Superseded {
+			reasons = append(reasons, fmt.Sprintf("superseded by %d newer patches", n))
+		}
+		newer[base]++
+		if i == 0 || len(reasons) == 0 || h.Version.Patch == 0 && kept[base] {
+			kept[base] = true
+			continue
+		}
+		stale = append(stale, StaleTag{Tag: TagName(opts.Namespace, h.Version.String()), Commit: h.Commit, Time: h.Time,
+			Reasons: reasons})
+	}
+	return stale, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// inDays renders d in whole days when it is a multiple of one, as retention periods are usually given.
+func inDays(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d days", d/(24*time.Hour))
+	}
+	return d.String()
+}
@@ -0,0 +1,48 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStaleTags(t *testing.T) {
+	gitRepo(t)
+	for _, tag := range []string{"20250101.1", "20250101.1.1", "20250101.1.2", "20250101.1.3", "20250301.7", "20250301.7.1"} {
+		mustGit(t, "", "tag", tag)
+	}
+	report := func(opts StaleOptions) string {
+		t.Helper()
+		st, err := StaleTags(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range st {
+			got = append(got, s.Tag)
+		}
+		return fmt.Sprint(got)
+	}
+
+	// The base of a line whose newest patch survives stays, however many patches supersede it.
+	if got, want := report(StaleOptions{Superseded: 2}), "[20250101.1.1]"; got != want {
+		t.Fatalf("superseded: got %s want %s", got, want)
+	}
+	// Everything is a year old, but the newest release and its base remain.
+	inAYear := ClockFunc(func() time.Time { return time.Now().AddDate(1, 0, 0) })
+	later := StaleOptions{MaxAge: 180 * 24 * time.Hour, Clock: inAYear}
+	if got, want := report(later), "[20250101.1.3 20250101.1.2 20250101.1.1 20250101.1]"; got != want {
+		t.Fatalf("age: got %s want %s", got, want)
+	}
+	st, _ := StaleTags(later)
+	if r := st[0].Reasons; len(r) != 1 || r[0] != "created "+time.Now().Format(time.DateOnly)+", older than 180 days" {
+		t.Fatalf("reasons: got %q", r)
+	}
+	if got := report(StaleOptions{MaxAge: 180 * 24 * time.Hour}); got != "[]" {
+		t.Fatalf("fresh tags: got %s", got)
+	}
+	if _, err := StaleTags(StaleOptions{Superseded: -1}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("negative threshold: got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package versioner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// StateFile is a Ledger kept as a single JSON document ({"versions": [manifest …]}), committed to the repository or
+// stored as a CI artifact, for projects whose git tags are unusable (read-only mirrors, squashed histories). Paired
+// with LedgerTags as BuildContext.LookupTags, its versions take the place of tags with the same latest/patch rules.
+type StateFile struct {
+	Path string
+}
+
+// Record appends m; a version that is already recorded is ErrVersionExists. The file is replaced atomically.
+func (s StateFile) Record(m Manifest) error {
+	st, err := s.read()
+	if err != nil {
+		return err
+	}
+	for _, o := range st.Versions {
+		if o.Version == m.Version {
+			return fmt.Errorf("%w: %s in %s", ErrVersionExists, m.Version, s.Path)
+		}
+	}
+	st.Versions = append(st.Versions, m)
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".versioner-state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// Manifests returns the recorded versions in recording order; a missing file is an empty state.
+func (s StateFile) Manifests() ([]Manifest, error) {
+	st, err := s.read()
+	return st.Versions, err
+}
+
+// Claim computes the manifest and records it in c.Ledger without creating a git tag, for ledgers such as StateFile
+// and ObjectLedger that replace tags as the source of truth. When another pipeline recorded the version first
+// (ErrVersionExists), the version is recomputed up to Config.PushRetries times, handing release builds the next patch.
+func (c BuildContext) Claim() (Manifest, error) {
+	c = c.pinTime()
+	if c.Ledger == nil {
+		return Manifest{}, fmt.Errorf("%w: Claim needs a Ledger", ErrInvalidConfig)
+	}
+	for attempt := 0; ; attempt++ {
+		m, err := c.Manifest()
+		if err != nil {
+			return Manifest{}, err
+		}
+		switch err = c.record(m); {
+		case err == nil:
+			return m, nil
+		case !errors.Is(err, ErrVersionExists) || attempt >= c.Config.PushRetries:
+			return Manifest{}, err
+		}
+		c.debug("version claimed concurrently, recomputing", "version", m.Version, "attempt", attempt+1)
+	}
+}
+
+// LedgerTags adapts a Ledger into a tag source listing its recorded versions, plus a RetractedPrefix marker for each
+// manifest with Retracted set.
+func LedgerTags(l Ledger) func() ([]string, error) {
+	return func() ([]string, error) {
+		ms, err := l.Manifests()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+		}
+		vs := make([]string, 0, len(ms))
+		for _, m := range ms {
+			vs = append(vs, m.Version)
+			if m.Retracted != "" {
+				vs = append(vs, RetractedPrefix+m.Version)
+			}
+		}
+		return vs, nil
+	}
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+type state struct {
+	Versions []Manifest `json:"versions"`
+}
+
+func (s StateFile) read() (state, error) {
+	var st state
+	b, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return st, nil
+}
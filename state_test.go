@@ -0,0 +1,27 @@
+package versioner
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateFile(t *testing.T) {
+	st := StateFile{Path: filepath.Join(t.TempDir(), "versions.json")}
+	cfg := Config{DefaultBranch: "main"}
+	for i, want := range []string{"20250428.100.1", "20250428.100.2"} {
+		c := ctx("release/v20250428.100", cfg, nil)
+		c.LookupTags, c.Ledger, c.CommitSHA = LedgerTags(st), st, "0123456789abcdef"
+		m, err := c.Claim()
+		if err != nil || m.Version != want {
+			t.Fatalf("run %d: got %s want %s (%v)", i, m.Version, want, err)
+		}
+	}
+	if err := st.Record(Manifest{Version: "20250428.100.2"}); !errors.Is(err, ErrVersionExists) {
+		t.Fatalf("duplicate: got %v", err)
+	}
+	ms, err := st.Manifests()
+	if err != nil || len(ms) != 2 || ms[1].Commit != "0123456789abcdef" || ms[0].Version != "20250428.100.1" {
+		t.Fatalf("got %+v, %v", ms, err)
+	}
+}
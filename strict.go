@@ -0,0 +1,47 @@
+package versioner
+
+import (
+	"fmt"
+	"path"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// UnknownBranches decides what builds of unknown branches get: branches that are neither the default branch, a
+// release branch nor matched by Config.FeatureBranches.
+type UnknownBranches string
+
+const (
+	UnknownSnapshot UnknownBranches = ""      // a feature snapshot, like any other branch (the default)
+	UnknownError    UnknownBranches = "error" // ErrUnknownBranch
+	UnknownSkip     UnknownBranches = "skip"  // ErrNoVersion: the build is deliberately left unversioned
+)
+
+// IsKnownBranch reports whether branch is the default branch, a release branch or matches one of
+// cfg.FeatureBranches (path.Match globs such as "feature/*"). Without FeatureBranches no feature branch is known.
+func IsKnownBranch(cfg Config, branch string) bool {
+	if Classify(cfg, branch) != KindFeature {
+		return true
+	}
+	for _, p := range cfg.FeatureBranches {
+		if ok, _ := path.Match(p, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// checkKnownBranch applies Config.UnknownBranches. Scheduled nightlies are versioned on any branch.
+func (c BuildContext) checkKnownBranch() error {
+	if c.Config.UnknownBranches == UnknownSnapshot || c.isNightly() || IsKnownBranch(c.Config, c.Branch) {
+		return nil
+	}
+	c.debug("unknown branch", "branch", c.Branch, "outcome", c.Config.UnknownBranches)
+	if c.Config.UnknownBranches == UnknownSkip {
+		return fmt.Errorf("%w: %q is not an allowed branch", ErrNoVersion, c.Branch)
+	}
+	return fmt.Errorf("%w: %q is not the default branch, a release branch or one of the allowed feature branches",
+		ErrUnknownBranch, c.Branch)
+}
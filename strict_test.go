@@ -0,0 +1,41 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnknownBranches(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", FeatureBranches: []string{"feature/*"}, UnknownBranches: UnknownError}
+	tags := []string{"20250427.3"}
+	for _, branch := range []string{"main", "feature/login"} {
+		if _, err := ctx(branch, cfg, tags).Version(); err != nil {
+			t.Fatalf("%s: %v", branch, err)
+		}
+	}
+	if !IsKnownBranch(cfg, "release/v20250427.3") || IsKnownBranch(cfg, "hotfix/x") {
+		t.Fatal("IsKnownBranch misclassifies")
+	}
+
+	if _, err := ctx("spike/x", cfg, tags).Version(); !errors.Is(err, ErrUnknownBranch) || ExitCode(err) != ExitBranch {
+		t.Fatalf("error: got %v", err)
+	}
+	cfg.UnknownBranches = UnknownSkip
+	if _, err := ctx("spike/x", cfg, tags).Version(); !errors.Is(err, ErrNoVersion) || ExitCode(err) != 0 {
+		t.Fatalf("skip: got %v", err)
+	}
+	p, err := ctx("spike/x", cfg, tags).Plan()
+	if err != nil || p.Feature != "" || p.Default == "" {
+		t.Fatalf("plan: got %+v, %v", p, err)
+	}
+
+	for _, bad := range []Config{
+		{FeatureBranches: []string{"feature/*"}},
+		{UnknownBranches: "warn"},
+		{UnknownBranches: UnknownError, FeatureBranches: []string{"feature/["}},
+	} {
+		if err := bad.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%+v: got %v", bad, err)
+		}
+	}
+}
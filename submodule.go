@@ -0,0 +1,123 @@
+package versioner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Manifest pins a computed version to the submodule commits it was built from and any metadata attached to it.
+type Manifest struct {
+	Version    string            `json:"version"`
+	Namespace  string            `json:"namespace,omitempty"` // the tag is '<namespace>/<version>'; see TagName
+	Commit     string            `json:"commit,omitempty"`
+	Time       time.Time         `json:"time"`
+	Submodules map[string]string `json:"submodules,omitempty"` // path → commit SHA
+	Metadata   map[string]string `json:"metadata,omitempty"`   // e.g. enabled feature flags, config schema version
+	Tickets    []string          `json:"tickets,omitempty"`    // issue IDs from the branch name; see Config.TicketPattern
+
+	PromotedFrom string `json:"promoted_from,omitempty"` // snapshot version this release was promoted from
+	Notes        string `json:"-"`                       // Markdown release notes; lives in the tag annotation only
+	Retracted    string `json:"retracted,omitempty"`     // ledger-only: why the version was withdrawn; see Retract
+}
+
+// Manifest computes the version and bundles it with BuildContext.Metadata and, when Config.Submodules is set, every
+// submodule pin.
+func (c BuildContext) Manifest() (Manifest, error) {
+	c = c.pinTime()
+	v, err := c.Version()
+	if err != nil {
+		return Manifest{}, err
+	}
+	m := Manifest{Version: v, Namespace: c.Config.Namespace, Commit: c.CommitSHA, Time: c.now().UTC(), Metadata: c.Metadata}
+	if m.Tickets, err = c.tickets(); err != nil {
+		return Manifest{}, err
+	}
+	if !c.Config.Submodules {
+		return m, nil
+	}
+
+	lookup := c.LookupSubmodules
+	if lookup == nil {
+		lookup = func() (map[string]string, error) { return SubmodulePins("HEAD") }
+	}
+	if m.Submodules, err = lookup(); err != nil {
+		return Manifest{}, fmt.Errorf("submodule lookup: %w", err)
+	}
+	return m, nil
+}
+
+// Verify reports every submodule whose pin differs from the manifest (added, removed or moved).
+func (m Manifest) Verify(pins map[string]string) error {
+	var bad []string
+	for p, want := range m.Submodules {
+		switch got, ok := pins[p]; {
+		case !ok:
+			bad = append(bad, fmt.Sprintf("%s: missing (want %s)", p, want))
+		case got != want:
+			bad = append(bad, fmt.Sprintf("%s: %s (want %s)", p, got, want))
+		}
+	}
+	for p, got := range pins {
+		if _, ok := m.Submodules[p]; !ok {
+			bad = append(bad, fmt.Sprintf("%s: unexpected %s", p, got))
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	sort.Strings(bad)
+	return fmt.Errorf("%w from %s: %s", ErrSubmodulesDiffer, m.Version, strings.Join(bad, "; "))
+}
+
+// WriteManifest stores m as indented JSON at path.
+func WriteManifest(path string, m Manifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// ReadManifest loads a manifest previously written by WriteManifest.
+func ReadManifest(path string) (Manifest, error) {
+	var m Manifest
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
+
+// SubmodulePins returns the submodule commits recorded in ref's tree (e.g. "HEAD" or a release tag). Unsafe ref
+// names are ErrUnsafeRef.
+func SubmodulePins(ref string) (map[string]string, error) {
+	if err := CheckRefName(ref); err != nil {
+		return nil, err
+	}
+	out, err := git("ls-tree", "-r", "--full-tree", "--end-of-options", ref)
+	if err != nil {
+		return nil, err
+	}
+	return parseGitlinks(out), nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// parseGitlinks extracts "160000 commit <sha>\t<path>" entries from ls-tree output.
+func parseGitlinks(out string) map[string]string {
+	pins := map[string]string{}
+	for _, ln := range strings.Split(out, "\n") {
+		meta, path, ok := strings.Cut(ln, "\t")
+		if f := strings.Fields(meta); ok && len(f) == 3 && f[0] == "160000" && f[1] == "commit" {
+			pins[path] = f[2]
+		}
+	}
+	return pins
+}
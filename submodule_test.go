@@ -0,0 +1,66 @@
+package versioner
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestRecordsSubmodules(t *testing.T) {
+	c := ctx("main", Config{DefaultBranch: "main", Submodules: true}, nil)
+	c.LookupSubmodules = func() (map[string]string, error) {
+		return map[string]string{"vendor/lib": "abc123"}, nil
+	}
+	m, err := c.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "20250428.321" || m.Submodules["vendor/lib"] != "abc123" {
+		t.Fatalf("unexpected manifest %+v", m)
+	}
+}
+
+func TestManifestVerify(t *testing.T) {
+	m := Manifest{Version: "20250428.321", Submodules: map[string]string{"a": "1", "b": "2"}}
+	if err := m.Verify(map[string]string{"a": "1", "b": "2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := m.Verify(map[string]string{"a": "1", "b": "3", "c": "4"})
+	want := "submodule pins differ from 20250428.321: b: 3 (want 2); c: unexpected 4"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v want %s", err, want)
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	in := Manifest{Version: "20250428.100.1", Submodules: map[string]string{"a": "1"}}
+	if err := WriteManifest(path, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != in.Version || out.Submodules["a"] != "1" {
+		t.Fatalf("got %+v want %+v", out, in)
+	}
+}
+
+func TestParseGitlinks(t *testing.T) {
+	out := "100644 blob 111\tREADME.md\n160000 commit 222\tvendor/lib\n"
+	pins := parseGitlinks(out)
+	if len(pins) != 1 || pins["vendor/lib"] != "222" {
+		t.Fatalf("unexpected pins %v", pins)
+	}
+}
+
+func TestSubmodulePinsRejectsOptions(t *testing.T) {
+	gitRepo(t)
+	if _, err := SubmodulePins("--output=pwned"); !errors.Is(err, ErrUnsafeRef) {
+		t.Fatalf("got %v want ErrUnsafeRef", err)
+	}
+	if pins, err := SubmodulePins("HEAD"); err != nil || len(pins) != 0 {
+		t.Fatalf("got %v, %v", pins, err)
+	}
+}
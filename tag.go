@@ -0,0 +1,202 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Tag creates an annotated tag named m.Version (in m.Namespace) on m.Commit (HEAD if unset). The manifest (submodule pins, metadata)
+// is stored as JSON in the annotation body so ReadTagManifest can answer "what shipped in <version>?" later.
+func Tag(m Manifest) error {
+	return BuildContext{}.tag(m)
+}
+
+// TagAndPush computes the manifest, tags HEAD and pushes the tag to origin. When the push is rejected – typically a
+// concurrent pipeline on the same release branch claimed the patch first – the local tag is dropped, tags are
+// re-fetched, the version is recomputed and the push retried up to Config.PushRetries times with doubling backoff.
+// With a Locker set, release branches hold the branch lock for the whole allocation so races are avoided outright.
+// A tag that already exists is ErrVersionExists, unless Config.Reruns is set and it points at the commit being built.
+func (c BuildContext) TagAndPush() (m Manifest, err error) {
+	c = c.pinTime()
+	sp := c.span("versioner.tag_and_push", slog.String("branch", c.Branch))
+	defer func() { sp.End(err) }()
+
+	if c.Locker != nil && !c.Config.DryRun && Classify(c.Config, c.Branch) == KindRelease {
+		unlock, err := c.Locker.Lock(context.Background(), c.Branch)
+		if err != nil {
+			return Manifest{}, err
+		}
+		defer unlock()
+		// another pipeline may have pushed while we waited
+		if _, err := c.git("fetch", "--tags", "--force", "origin"); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	backoff := c.Config.PushBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		m, err := c.Manifest()
+		if err != nil {
+			return Manifest{}, err
+		}
+		name := c.tagName(m.Version)
+		if c.tagged(name) {
+			if c.Config.Reruns && c.tagsCommit(name) {
+				return m, c.UpdateAliases(m.Version) // re-run reproducing a version that is already tagged and pushed
+			}
+			return Manifest{}, fmt.Errorf("%w: %s", ErrVersionExists, m.Version)
+		}
+		if c.Config.TagNotes {
+			if m.Notes, err = c.ReleaseNotes(m.Version); err != nil {
+				return Manifest{}, err
+			}
+		}
+		if err := c.effect("create tag "+name, func() error { return c.tag(m) }); err != nil {
+			return Manifest{}, err
+		}
+		err = c.effect("push tag "+name+" to origin", func() error {
+			_, err := c.git("push", "origin", "refs/tags/"+name)
+			return err
+		})
+		if err == nil {
+			return m, c.released(m)
+		}
+		c.git("tag", "-d", name)
+		if attempt >= c.Config.PushRetries {
+			return Manifest{}, fmt.Errorf("push %s (attempt %d): %w", m.Version, attempt+1, err)
+		}
+		c.Metrics.pushRetried()
+		time.Sleep(backoff << attempt)
+		if _, err := c.git("fetch", "--tags", "--force", "origin"); err != nil {
+			return Manifest{}, err
+		}
+	}
+}
+
+// ReadTagManifest recovers the manifest stored by Tag. Lightweight or foreign tags yield a manifest carrying only the
+// version; a missing tag is ErrNoMatchingTags.
+func ReadTagManifest(version string) (Manifest, error) {
+	if err := CheckRefName(version); err != nil {
+		return Manifest{}, err
+	}
+	if !tagged(version) {
+		return Manifest{}, fmt.Errorf("%w: %s", ErrNoMatchingTags, version)
+	}
+	out, err := git("tag", "-l", "--format=%(contents:body)", "--end-of-options", version)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	return parseAnnotation(version, out)
+}
+
+// Resolve returns the full SHA of the commit tagged version, peeling annotated tags, to answer "what code is
+// 20250428.100.2?" during incidents. A missing tag is ErrNoMatchingTags.
+func Resolve(version string) (string, error) {
+	if err := CheckRefName(version); err != nil {
+		return "", err
+	}
+	if !tagged(version) {
+		return "", fmt.Errorf("%w: %s", ErrNoMatchingTags, version)
+	}
+	out, err := git("rev-parse", "--verify", "refs/tags/"+version+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// tag is Tag bounded by Config.Timeouts.Git.
+func (c BuildContext) tag(m Manifest) error {
+	name := TagName(m.Namespace, m.Version)
+	if err := CheckRefName(name); err != nil {
+		return err
+	}
+	msg, err := annotation(m)
+	if err != nil {
+		return err
+	}
+	target := m.Commit
+	if target == "" {
+		target = "HEAD"
+	}
+	_, err = c.git("tag", "-a", "-m", msg, "--end-of-options", name, target)
+	return err
+}
+
+func tagged(v string) bool {
+	return BuildContext{}.tagged(v)
+}
+
+func (c BuildContext) tagged(v string) bool {
+	_, err := c.git("rev-parse", "-q", "--verify", "refs/tags/"+v)
+	return err == nil
+}
+
+// tagsCommit reports whether the tag name points at the commit being built.
+func (c BuildContext) tagsCommit(name string) bool {
+	sha, err := c.commit()
+	if err != nil {
+		return false
+	}
+	out, err := c.git("rev-parse", "-q", "--verify", "refs/tags/"+name+"^{commit}")
+	return err == nil && strings.TrimSpace(out) == sha
+}
+
+// record stores m in the ledger, filling in the tagged commit.
+func (c BuildContext) record(m Manifest) error {
+	if c.Ledger == nil {
+		return nil
+	}
+	if m.Commit == "" {
+		sha, err := c.commit()
+		if err != nil {
+			return err
+		}
+		m.Commit = sha
+	}
+	return c.effect("record "+m.Version+" in the ledger", func() error { return c.Ledger.Record(m) })
+}
+
+// annotation renders "Release <v>", the optional notes and finally the manifest JSON.
+func annotation(m Manifest) (string, error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	notes := ""
+	if m.Notes != "" {
+		notes = strings.TrimSpace(m.Notes) + "\n\n"
+	}
+	return fmt.Sprintf("Release %s\n\n%s%s\n", m.Version, notes, b), nil
+}
+
+// parseAnnotation splits an annotation body into notes and the trailing manifest JSON, if any.
+func parseAnnotation(version, body string) (Manifest, error) {
+	body = strings.TrimSpace(body)
+	notes, doc := body, ""
+	switch i := strings.LastIndex(body, "\n{\n"); {
+	case strings.HasPrefix(body, "{"):
+		notes, doc = "", body
+	case i >= 0:
+		notes, doc = strings.TrimSpace(body[:i]), body[i+1:]
+	}
+	if doc == "" {
+		return Manifest{Version: version, Notes: notes}, nil
+	}
+	m := Manifest{Notes: notes}
+	if err := json.Unmarshal([]byte(doc), &m); err != nil {
+		return Manifest{}, fmt.Errorf("%w: tag %s: malformed manifest: %v", ErrInvalidVersion, version, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,160 @@
+package versioner
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnnotationRoundTrip(t *testing.T) {
+	in := Manifest{
+		Version:  "20250428.100.1",
+		Metadata: map[string]string{"flags.payments": "on", "schema": "7"},
+	}
+	msg, err := annotation(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// git strips the subject line from %(contents:body)
+	_, body, _ := strings.Cut(msg, "\n")
+	out, err := parseAnnotation(in.Version, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != in.Version || out.Metadata["flags.payments"] != "on" || out.Metadata["schema"] != "7" {
+		t.Fatalf("got %+v want %+v", out, in)
+	}
+}
+
+func TestParseAnnotationForeignTag(t *testing.T) {
+	m, err := parseAnnotation("20250428.100", "hand-written release notes\n")
+	if err != nil || m.Version != "20250428.100" || m.Metadata != nil || m.Notes != "hand-written release notes" {
+		t.Fatalf("unexpected %+v, %v", m, err)
+	}
+}
+
+func TestAnnotationWithNotes(t *testing.T) {
+	in := Manifest{Version: "20250428.100.1", Notes: "## 20250428.100.1\n\n### Bug Fixes\n\n- x (a1)\n"}
+	msg, _ := annotation(in)
+	_, body, _ := strings.Cut(msg, "\n")
+	out, err := parseAnnotation(in.Version, body)
+	if err != nil || out.Version != in.Version || out.Notes != strings.TrimSpace(in.Notes) {
+		t.Fatalf("got %+v, %v", out, err)
+	}
+}
+
+// gitRepo creates a bare origin with one commit and returns a clone of it; the test's working directory is left in
+// the clone.
+func gitRepo(t *testing.T) (origin string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for k, v := range map[string]string{
+		"GIT_AUTHOR_NAME": "t", "GIT_AUTHOR_EMAIL": "t@example.com",
+		"GIT_COMMITTER_NAME": "t", "GIT_COMMITTER_EMAIL": "t@example.com",
+		"GIT_CONFIG_GLOBAL": "/dev/null", "GIT_CONFIG_NOSYSTEM": "1",
+	} {
+		t.Setenv(k, v)
+	}
+	dir := t.TempDir()
+	origin = filepath.Join(dir, "origin.git")
+	seed := filepath.Join(dir, "seed")
+	mustGit(t, "", "init", "-q", "--bare", "-b", "main", origin)
+	mustGit(t, "", "init", "-q", "-b", "main", seed)
+	mustGit(t, seed, "commit", "-q", "--allow-empty", "-m", "init")
+	mustGit(t, seed, "push", "-q", origin, "main")
+	t.Chdir(cloneRepo(t, origin))
+	return origin
+}
+
+func cloneRepo(t *testing.T, origin string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "clone")
+	mustGit(t, "", "clone", "-q", origin, dir)
+	return dir
+}
+
+func mustGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestTagAndPushRetriesAfterRace(t *testing.T) {
+	origin := gitRepo(t)
+
+	// a concurrent pipeline pushes the patch this one is about to claim
+	rival := cloneRepo(t, origin)
+	mustGit(t, rival, "tag", "20250428.100.1")
+	mustGit(t, rival, "push", "-q", "origin", "20250428.100.1")
+
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", PushRetries: 2, PushBackoff: time.Millisecond}, nil)
+	calls := 0
+	c.LookupTags = func() ([]string, error) {
+		calls++
+		if calls == 1 {
+			return nil, nil // stale view: the rival's tag is not fetched yet
+		}
+		return GitTags()
+	}
+	m, err := c.TagAndPush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "20250428.100.2" {
+		t.Fatalf("got %s want 20250428.100.2", m.Version)
+	}
+	if got := mustGit(t, "", "ls-remote", "--tags", origin, "20250428.100.2"); got == "" {
+		t.Fatal("tag was not pushed")
+	}
+}
+
+func TestTagAndPushExistingTag(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "20250428.100.1")
+	head := mustGit(t, "", "rev-parse", "HEAD")
+
+	// a stale tag view computes a version that is already tagged
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, nil)
+	c.CommitSHA = head
+	if _, err := c.TagAndPush(); !errors.Is(err, ErrVersionExists) {
+		t.Fatalf("without Reruns: got %v want ErrVersionExists", err)
+	}
+
+	c.Config.Reruns = true
+	if m, err := c.TagAndPush(); err != nil || m.Version != "20250428.100.1" {
+		t.Fatalf("re-run of the tagged commit: got %s, %v want 20250428.100.1", m.Version, err)
+	}
+
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "fix")
+	c.CommitSHA = mustGit(t, "", "rev-parse", "HEAD")
+	if _, err := c.TagAndPush(); !errors.Is(err, ErrVersionExists) {
+		t.Fatalf("new commit: got %v want ErrVersionExists", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	gitRepo(t)
+	want := mustGit(t, "", "rev-parse", "HEAD")
+	mustGit(t, "", "tag", "20250428.100")
+	mustGit(t, "", "tag", "-a", "-m", "Release", "20250428.100.1")
+	mustGit(t, "", "commit", "-q", "--allow-empty", "-m", "later")
+
+	for _, v := range []string{"20250428.100", "20250428.100.1"} {
+		if got, err := Resolve(v); err != nil || got != want {
+			t.Fatalf("%s: got %s, %v want %s", v, got, err, want)
+		}
+	}
+	if _, err := Resolve("20250428.100.2"); !errors.Is(err, ErrNoMatchingTags) {
+		t.Fatalf("got %v want ErrNoMatchingTags", err)
+	}
+}
@@ -0,0 +1,135 @@
+package versioner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// TagCache memoizes a tag source so multi-component builds don't run `git tag` once per component. Use its Tags
+// method as BuildContext.LookupTags for sources reading the local repository's tags: it needs a git directory.
+// Entries stay valid until the repository's tag refs change (a fetch, push or deletion), so TagAndPush retries still
+// see freshly fetched tags.
+type TagCache struct {
+	Source func() ([]string, error) // defaults to GitTags
+	File   string                   // optional on-disk cache shared between processes, keyed by HEAD
+
+	mu     sync.Mutex
+	gitDir string
+	key    string
+	tags   []string
+	valid  bool
+}
+
+// Tags returns the cached tags, consulting File and then Source when the tag refs changed.
+func (tc *TagCache) Tags() ([]string, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.gitDir == "" {
+		out, err := git("rev-parse", "--git-common-dir")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+		}
+		if tc.gitDir, err = filepath.Abs(strings.TrimSpace(out)); err != nil {
+			return nil, err
+		}
+	}
+	fp := tc.fingerprint()
+	if tc.valid && tc.key == fp {
+		return tc.tags, nil
+	}
+
+	var diskKey string
+	if tc.File != "" {
+		head, err := git("rev-parse", "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+		}
+		diskKey = strings.TrimSpace(head) + " " + fp
+		if ts, ok := readTagCache(tc.File, diskKey); ok {
+			tc.tags, tc.key, tc.valid = ts, fp, true
+			return ts, nil
+		}
+	}
+
+	src := tc.Source
+	if src == nil {
+		src = GitTags
+	}
+	ts, err := src()
+	if err != nil {
+		return nil, err
+	}
+	tc.tags, tc.key, tc.valid = ts, fp, true
+	if tc.File != "" {
+		writeTagCache(tc.File, diskKey, ts) // best effort: a failed write only costs the next process a lookup
+	}
+	return ts, nil
+}
+
+// Invalidate forgets the in-process entry; the next Tags call re-validates against File and Source.
+func (tc *TagCache) Invalidate() {
+	tc.mu.Lock()
+	tc.valid = false
+	tc.mu.Unlock()
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// fingerprint changes whenever a tag ref is added, updated or removed, packed or loose at any depth (namespaced tags
+// live in refs/tags/<namespace>/), without running git.
+func (tc *TagCache) fingerprint() string {
+	h := sha256.New()
+	if fi, err := os.Stat(filepath.Join(tc.gitDir, "packed-refs")); err == nil {
+		fmt.Fprintf(h, "packed-refs %d/%d\n", fi.ModTime().UnixNano(), fi.Size())
+	}
+	root := filepath.Join(tc.gitDir, "refs", "tags")
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // a ref deleted mid-walk shows in the next fingerprint
+		}
+		if fi, err := d.Info(); err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(root, p)
+			fmt.Fprintf(h, "%s %d/%d\n", rel, fi.ModTime().UnixNano(), fi.Size())
+		}
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+type tagCacheFile struct {
+	Key  string   `json:"key"`
+	Tags []string `json:"tags"`
+}
+
+func readTagCache(path, key string) ([]string, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var f tagCacheFile
+	if json.Unmarshal(b, &f) != nil || f.Key != key {
+		return nil, false
+	}
+	return f.Tags, true
+}
+
+func writeTagCache(path, key string, tags []string) {
+	b, err := json.Marshal(tagCacheFile{Key: key, Tags: tags})
+	if err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, b, 0o644) == nil {
+		os.Rename(tmp, path)
+	}
+}
@@ -0,0 +1,51 @@
+package versioner
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTagCache(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "20250428.100")
+	calls := 0
+	src := func() ([]string, error) { calls++; return GitTags() }
+	file := filepath.Join(t.TempDir(), "tags.json")
+
+	tc := &TagCache{Source: src, File: file}
+	for i := 0; i < 3; i++ {
+		if ts, err := tc.Tags(); err != nil || len(ts) != 1 {
+			t.Fatalf("got %v, %v", ts, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("source called %d times want 1", calls)
+	}
+
+	// a second process reuses the on-disk entry
+	if ts, _ := (&TagCache{Source: src, File: file}).Tags(); len(ts) != 1 || calls != 1 {
+		t.Fatalf("disk cache missed: %v, %d calls", ts, calls)
+	}
+
+	mustGit(t, "", "tag", "20250428.100.1")
+	if ts, _ := tc.Tags(); len(ts) != 2 || calls != 2 {
+		t.Fatalf("new tag not seen: %v, %d calls", ts, calls)
+	}
+}
+
+func TestTagCacheSeesNamespacedTags(t *testing.T) {
+	gitRepo(t)
+	mustGit(t, "", "tag", "cli/20250428.100")
+	tc := &TagCache{}
+	if ts, err := tc.Tags(); err != nil || len(ts) != 1 {
+		t.Fatalf("got %v, %v", ts, err)
+	}
+	mustGit(t, "", "tag", "cli/20250428.100.1")
+	if ts, _ := tc.Tags(); len(ts) != 2 {
+		t.Fatalf("new namespaced tag not seen: %v", ts)
+	}
+	mustGit(t, "", "tag", "-d", "cli/20250428.100")
+	if ts, _ := tc.Tags(); len(ts) != 1 {
+		t.Fatalf("deleted namespaced tag still listed: %v", ts)
+	}
+}
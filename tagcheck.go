@@ -0,0 +1,93 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// SkippedTag is a tag name from a lookup that was ignored because it could not be a real git tag or could confuse
+// the regular expressions that parse versions.
+type SkippedTag struct {
+	Tag    string `json:"tag"` // quoted with %q when printed; may hold control characters
+	Reason string `json:"reason"`
+}
+
+// Tags runs the tag lookup and returns the versions tagged in Config.Namespace that Version works from, with
+// malformed names screened out.
+func (c BuildContext) Tags() ([]string, error) {
+	return c.tags()
+}
+
+// SkippedTags runs the tag lookup and returns the names Version ignored, for auditing the tag source.
+func (c BuildContext) SkippedTags() ([]SkippedTag, error) {
+	if c.LookupTags == nil {
+		return nil, nil
+	}
+	ts, err := c.lookupTags()
+	switch {
+	case errors.Is(err, ErrTagLookupFailed):
+		return nil, err
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	_, skipped := screenTags(ts)
+	return skipped, nil
+}
+
+// OnReleaseLine reports whether the commit tag points at is on the default branch or a release branch, local or on
+// origin, so hand-made tags on feature work or abandoned commits can be flagged.
+func (c BuildContext) OnReleaseLine(tag string) (bool, error) {
+	out, err := c.git("for-each-ref", "--contains", "refs/tags/"+tag, "--format=%(refname)", "refs/heads", "refs/remotes/origin")
+	if err != nil {
+		return false, err
+	}
+	for _, ref := range strings.Fields(out) {
+		br := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/remotes/origin/")
+		if Classify(c.Config, br) != KindFeature {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// maxTagLen is far above any version this package emits and below what would make regex matching costly.
+const maxTagLen = 255
+
+// screenTags treats looked-up names as untrusted: names with control characters, invalid UTF-8, characters git
+// forbids in refs (space ~ ^ : ? * [ \), a leading '-' that would read as an option, or more than maxTagLen bytes are
+// dropped and reported.
+func screenTags(ts []string) (ok []string, skipped []SkippedTag) {
+	ok = ts[:0:0]
+	for _, t := range ts {
+		if reason := badTag(t); reason != "" {
+			skipped = append(skipped, SkippedTag{Tag: t, Reason: reason})
+			continue
+		}
+		ok = append(ok, t)
+	}
+	return ok, skipped
+}
+
+func badTag(t string) string {
+	switch {
+	case t == "":
+		return "empty"
+	case len(t) > maxTagLen:
+		return fmt.Sprintf("longer than %d bytes", maxTagLen)
+	case !utf8.ValidString(t):
+		return "invalid UTF-8"
+	case strings.IndexFunc(t, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0:
+		return "control character"
+	case strings.ContainsAny(t, " ~^:?*[\\"):
+		return "character not allowed in git refs"
+	case strings.HasPrefix(t, "-"):
+		return "starts with '-'"
+	}
+	return ""
+}
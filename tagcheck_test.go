@@ -0,0 +1,55 @@
+package versioner
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestScreenTags(t *testing.T) {
+	tags := []string{
+		"20250101.5",
+		"20250101.5.1\n20250101.5.99",
+		"20250101.5.2 ",
+		"bad\x00",
+		"\xff\xfe",
+		strings.Repeat("9", 300),
+		"20250101.5.[0-9]*",
+	}
+	cfg := Config{DefaultBranch: "main"}
+	if got, err := ctx("release/v20250101.5", cfg, tags).Version(); err != nil || got != "20250101.5.1" {
+		t.Fatalf("got %s, %v", got, err)
+	}
+
+	skipped, err := ctx("main", cfg, tags).SkippedTags()
+	if err != nil || len(skipped) != 6 {
+		t.Fatalf("got %v, %v", skipped, err)
+	}
+	if s := skipped[0]; s.Tag != tags[1] || s.Reason != "control character" {
+		t.Fatalf("got %+v", s)
+	}
+	bi, err := ctx("main", cfg, tags).BuildInfo()
+	if err != nil || len(bi.SkippedTags) != 6 {
+		t.Fatalf("build info: got %v, %v", bi.SkippedTags, err)
+	}
+}
+
+func TestOnReleaseLine(t *testing.T) {
+	gitRepo(t)
+	dir, _ := os.Getwd()
+	mustGit(t, dir, "tag", "20250101.5")
+	mustGit(t, dir, "checkout", "-q", "-b", "feat/x")
+	mustGit(t, dir, "commit", "-q", "--allow-empty", "-m", "wip")
+	mustGit(t, dir, "tag", "20250101.6")
+
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	for tag, want := range map[string]bool{"20250101.5": true, "20250101.6": false} {
+		if got, err := c.OnReleaseLine(tag); err != nil || got != want {
+			t.Fatalf("%s: got %v, %v want %v", tag, got, err, want)
+		}
+	}
+	mustGit(t, dir, "branch", "release/v20250101.6")
+	if got, _ := c.OnReleaseLine("20250101.6"); !got {
+		t.Fatal("tag on a release branch reported off the release line")
+	}
+}
@@ -0,0 +1,47 @@
+package versioner
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// DefaultTicketPattern matches Jira-style issue keys such as "PROJ-1234".
+const DefaultTicketPattern = `[A-Z][A-Z0-9]+-[0-9]+`
+
+// Tickets returns the issue IDs pattern finds in branch, in order of appearance and without repeats:
+// "feature/PROJ-12-PROJ-7-login" gives [PROJ-12 PROJ-7]. A pattern with a capture group yields the group, so
+// `(?:^|/)gh-([0-9]+)` turns "fix/gh-42-crash" into [42]. An invalid pattern is ErrInvalidConfig.
+func Tickets(pattern, branch string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ticket pattern %q: %v", ErrInvalidConfig, pattern, err)
+	}
+	var out []string
+	for _, m := range re.FindAllStringSubmatch(branch, -1) {
+		id := m[0]
+		if len(m) > 1 {
+			id = m[1]
+		}
+		if id != "" && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// tickets are the issue IDs in c.Branch per Config.TicketPattern; none without a pattern.
+func (c BuildContext) tickets() ([]string, error) {
+	if c.Config.TicketPattern == "" {
+		return nil, nil
+	}
+	ts, err := Tickets(c.Config.TicketPattern, c.Branch)
+	if len(ts) > 0 {
+		c.debug("tickets found", "branch", c.Branch, "tickets", ts)
+	}
+	return ts, err
+}
@@ -0,0 +1,36 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTickets(t *testing.T) {
+	for _, tc := range []struct{ pattern, branch, want string }{
+		{DefaultTicketPattern, "feature/PROJ-12-PROJ-7-login-PROJ-12", "[PROJ-12 PROJ-7]"},
+		{DefaultTicketPattern, "feature/proj-12-login", "[]"},
+		{`(?:^|/)gh-([0-9]+)`, "fix/gh-42-crash", "[42]"},
+	} {
+		if got, err := Tickets(tc.pattern, tc.branch); err != nil || fmt.Sprint(got) != tc.want {
+			t.Fatalf("%s: got %v want %s (%v)", tc.branch, got, tc.want, err)
+		}
+	}
+	if _, err := Tickets("PROJ-(", "x"); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("bad pattern: got %v", err)
+	}
+
+	c := ctx("feature/OPS-9-rotate-keys", Config{DefaultBranch: "main", TicketPattern: DefaultTicketPattern}, nil)
+	bi, err := c.BuildInfo()
+	if err != nil || fmt.Sprint(bi.Tickets) != "[OPS-9]" {
+		t.Fatalf("build info: got %v, %v", bi.Tickets, err)
+	}
+	m, err := c.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := annotation(m); !strings.Contains(a, `"tickets": [`) {
+		t.Fatalf("annotation lacks tickets:\n%s", a)
+	}
+}
@@ -0,0 +1,45 @@
+package versioner
+
+import (
+	"context"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Defaults for Timeouts fields left zero: long enough for a fetch of a big repository or a slow API, short enough that
+// a wedged credential helper or endpoint fails the job well before the pipeline timeout.
+const (
+	DefaultGitTimeout     = 2 * time.Minute
+	DefaultAPITimeout     = 30 * time.Second
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// Timeouts bounds each external operation. Zero fields take the defaults above. Package-level functions (Tag,
+// GitTags, RemoteTags …) always use DefaultGitTimeout; their BuildContext methods honour Git.
+type Timeouts struct {
+	Git     time.Duration // each git command run for a BuildContext
+	API     time.Duration // each GitLab or GitHub API call of PublishRelease
+	Webhook time.Duration // each webhook delivery attempt of Notify
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// git runs a git command bounded by Config.Timeouts.Git.
+func (c BuildContext) git(args ...string) (string, error) {
+	return c.gitEnv(nil, args...)
+}
+
+// gitEnv is gitEnv bounded by Config.Timeouts.Git.
+func (c BuildContext) gitEnv(env []string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), orDefault(c.Config.Timeouts.Git, DefaultGitTimeout))
+	defer cancel()
+	return gitContext(ctx, env, args...)
+}
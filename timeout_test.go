@@ -0,0 +1,57 @@
+package versioner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// hangingRunner simulates a wedged credential helper: commands only end when their context does.
+type hangingRunner struct{}
+
+func (hangingRunner) Run(ctx context.Context, name string, args, env []string) (string, string, error) {
+	<-ctx.Done()
+	return "", "", ctx.Err()
+}
+
+func TestGitTimeout(t *testing.T) {
+	prev := SetRunner(hangingRunner{})
+	t.Cleanup(func() { SetRunner(prev) })
+
+	c := ctx("main", Config{DefaultBranch: "main", Timeouts: Timeouts{Git: 20 * time.Millisecond}}, nil)
+	for name, run := range map[string]func() error{
+		"git":          func() error { _, err := c.git("fetch", "origin"); return err },
+		"GitTags":      func() error { _, err := c.GitTags(); return err },
+		"RemoteTags":   func() error { _, err := c.RemoteTags("https://example.com/app.git", "tok")(); return err },
+		"RefTags":      func() error { _, err := c.RefTags("*")(); return err },
+		"DescribeTags": func() error { _, err := c.DescribeTags("")(); return err },
+		"FetchTags":    func() error { _, err := c.FetchTags(GitTags, "")(); return err },
+		"History": func() error {
+			_, err := History(HistoryOptions{GitTimeout: 20 * time.Millisecond})
+			return err
+		},
+		"TagAndPush": func() error { _, err := c.TagAndPush(); return err },
+	} {
+		start := time.Now()
+		if err := run(); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+			t.Fatalf("%s: got %v after %s", name, err, time.Since(start))
+		}
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+	defer srv.Close()
+	defer close(release)
+
+	c := ctx("main", Config{DefaultBranch: "main", Timeouts: Timeouts{Webhook: 20 * time.Millisecond}}, nil)
+	c.Webhooks = []Webhook{{URL: srv.URL}}
+	start := time.Now()
+	if err := c.Notify(context.Background(), EventTagged, Manifest{Version: "20250428.321"}); err == nil || time.Since(start) > time.Second {
+		t.Fatalf("got %v after %s", err, time.Since(start))
+	}
+}
@@ -0,0 +1,90 @@
+package versioner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Tracer starts spans around tag lookup, classification and tagging. It is shaped so an OpenTelemetry tracer can be
+// adapted in a few lines; the parent comes from BuildContext.Context, which may carry a CI trace via WithTraceParent.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...slog.Attr) Span
+}
+
+// Span is one traced step; End records its outcome.
+type Span interface {
+	End(err error)
+}
+
+// WithTraceParent returns ctx carrying a W3C traceparent header value (e.g. $TRACEPARENT exported by the CI job), so
+// spans become children of the pipeline's trace. Malformed values are ignored.
+func WithTraceParent(ctx context.Context, traceparent string) context.Context {
+	if !traceParentRE.MatchString(traceparent) {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey{}, traceparent)
+}
+
+// TraceParent returns the traceparent stored by WithTraceParent, for Tracer adapters.
+func TraceParent(ctx context.Context) (string, bool) {
+	tp, ok := ctx.Value(traceParentKey{}).(string)
+	return tp, ok
+}
+
+// LogTracer is a Tracer that logs each span's duration and outcome at debug level, tagged with the CI trace ID.
+type LogTracer struct {
+	Logger *slog.Logger
+}
+
+func (t LogTracer) Start(ctx context.Context, name string, attrs ...slog.Attr) Span {
+	if tp, ok := TraceParent(ctx); ok {
+		attrs = append(attrs, slog.String("trace_id", tp[3:35]))
+	}
+	return &logSpan{t: t, ctx: ctx, name: name, attrs: attrs, start: time.Now()}
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+type traceParentKey struct{}
+
+var traceParentRE = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+type logSpan struct {
+	t     LogTracer
+	ctx   context.Context
+	name  string
+	attrs []slog.Attr
+	start time.Time
+}
+
+func (s *logSpan) End(err error) {
+	if s.t.Logger == nil {
+		return
+	}
+	attrs := append(s.attrs, slog.Duration("duration", time.Since(s.start)))
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	s.t.Logger.LogAttrs(s.ctx, slog.LevelDebug, fmt.Sprintf("span %s", s.name), attrs...)
+}
+
+type noSpan struct{}
+
+func (noSpan) End(error) {}
+
+// span starts a span under c.Context when a Tracer is configured.
+func (c BuildContext) span(name string, attrs ...slog.Attr) Span {
+	if c.Tracer == nil {
+		return noSpan{}
+	}
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.Tracer.Start(ctx, name, attrs...)
+}
@@ -0,0 +1,41 @@
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type recordingTracer struct{ names []string }
+
+func (r *recordingTracer) Start(_ context.Context, name string, _ ...slog.Attr) Span {
+	r.names = append(r.names, name)
+	return noSpan{}
+}
+
+func TestTracingSpans(t *testing.T) {
+	rt := &recordingTracer{}
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, nil)
+	c.Tracer = rt
+	c.Version()
+	if got := strings.Join(rt.names, ","); got != "versioner.classify,versioner.tag_lookup" {
+		t.Fatalf("got spans %s", got)
+	}
+}
+
+func TestLogTracerCarriesCITrace(t *testing.T) {
+	var buf bytes.Buffer
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.Tracer = LogTracer{Logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+	c.Context = WithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	c.Version()
+	if !strings.Contains(buf.String(), "span versioner.classify") ||
+		!strings.Contains(buf.String(), "trace_id=4bf92f3577b34da6a3ce929d0e0e4736") {
+		t.Fatalf("unexpected log %s", buf.String())
+	}
+	if _, ok := TraceParent(WithTraceParent(context.Background(), "garbage")); ok {
+		t.Fatal("malformed traceparent accepted")
+	}
+}
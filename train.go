@@ -0,0 +1,206 @@
+package versioner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Schedule is a parsed five-field cron expression ("minute hour day-of-month month day-of-week") supporting '*',
+// lists, ranges and steps; day-of-week 0 and 7 are Sunday. Like cron, a restricted day-of-month and day-of-week
+// match if either does.
+type Schedule struct {
+	expr                string
+	min, hour, dom, mon [64]bool
+	dow                 [8]bool
+	domStar, dowStar    bool
+}
+
+// ParseSchedule parses a cron expression such as "0 6 * * 1" (Mondays 06:00).
+func ParseSchedule(expr string) (Schedule, error) {
+	f := strings.Fields(expr)
+	if len(f) != 5 {
+		return Schedule{}, fmt.Errorf("%w: schedule %q: want 5 fields", ErrInvalidConfig, expr)
+	}
+	s := Schedule{expr: expr, domStar: f[2] == "*", dowStar: f[4] == "*"}
+	var dow [64]bool
+	for i, fld := range []struct {
+		set    *[64]bool
+		lo, hi int
+	}{{&s.min, 0, 59}, {&s.hour, 0, 23}, {&s.dom, 1, 31}, {&s.mon, 1, 12}, {&dow, 0, 7}} {
+		if err := parseCronField(f[i], fld.lo, fld.hi, fld.set); err != nil {
+			return Schedule{}, fmt.Errorf("%w: schedule %q: %v", ErrInvalidConfig, expr, err)
+		}
+	}
+	copy(s.dow[:], dow[:8])
+	s.dow[0] = s.dow[0] || s.dow[7]
+	return s, nil
+}
+
+func (s Schedule) String() string { return s.expr }
+
+// Prev is the latest firing at or before t (minute precision, in t's location); ok is false when there was none
+// within the past five years.
+func (s Schedule) Prev(t time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for d := 0; d < 5*366; d++ {
+		if s.matchDay(day) {
+			for h := 23; h >= 0; h-- {
+				for m := 59; m >= 0; m-- {
+					at := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location())
+					if s.hour[h] && s.min[m] && !at.After(t) {
+						return at, true
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}, false
+}
+
+// TrainDecision says whether this pipeline departs a new release train or rides the current one.
+type TrainDecision struct {
+	Cut       bool      // a departure passed since the current release branch was cut
+	Branch    string    // the branch to cut, or the current one when Cut is false
+	Departure time.Time // the latest scheduled departure
+}
+
+// Train decides, from the Config.Train schedule, whether a release branch is due: it is when the latest departure
+// falls on a later day than the newest release/v<YYYYMMDD.B> branch and the latest default-branch tag isn't already
+// on that branch. CutRelease then creates Branch. Release branches come from LookupReleaseBranches.
+func (c BuildContext) Train() (TrainDecision, error) {
+	c = c.pinTime()
+	s, err := ParseSchedule(c.Config.Train)
+	if err != nil {
+		return TrainDecision{}, err
+	}
+	now, err := c.localTime()
+	if err != nil {
+		return TrainDecision{}, err
+	}
+	dep, ok := s.Prev(now)
+	if !ok {
+		return TrainDecision{}, fmt.Errorf("%w: schedule %q never fires", ErrInvalidConfig, s)
+	}
+
+	brs, err := c.releaseBranches()
+	if err != nil {
+		return TrainDecision{}, err
+	}
+	var current Version
+	for _, br := range brs {
+		m := relBranchRE.FindStringSubmatch(br)
+		if m == nil {
+			continue
+		}
+		if v, err := Parse(m[1]); err == nil && (current == Version{} || Compare(v, current) > 0) {
+			current = v
+		}
+	}
+	d := TrainDecision{Departure: dep}
+	if current != (Version{}) {
+		d.Branch = "release/v" + current.Base()
+	}
+	if current.Date >= dep.Format("20060102") {
+		c.debug("train not due", "departure", dep, "current", d.Branch)
+		return d, nil
+	}
+
+	ts, err := c.tags()
+	if err != nil {
+		return TrainDecision{}, err
+	}
+	latest, ok := latestDefault(ts, c.Config.Prefix)
+	if !ok || latest.Base() == current.Base() {
+		c.debug("train due but nothing new to ship", "departure", dep, "current", d.Branch)
+		return d, nil
+	}
+	d.Cut, d.Branch = true, "release/v"+latest.Base()
+	c.debug("train departing", "departure", dep, "branch", d.Branch)
+	return d, nil
+}
+
+// GitReleaseBranches lists the release/* branches known locally and on origin, without remote prefixes.
+func GitReleaseBranches() ([]string, error) {
+	return BuildContext{}.gitReleaseBranches()
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (c BuildContext) gitReleaseBranches() ([]string, error) {
+	out, err := c.git("for-each-ref", "--format=%(refname)", "refs/heads/release/", "refs/remotes/origin/release/")
+	if err != nil {
+		return nil, err
+	}
+	var brs []string
+	for _, ref := range strings.Fields(out) {
+		brs = append(brs, strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/remotes/origin/"))
+	}
+	return brs, nil
+}
+
+func (c BuildContext) releaseBranches() ([]string, error) {
+	if c.LookupReleaseBranches != nil {
+		return c.LookupReleaseBranches()
+	}
+	return c.gitReleaseBranches()
+}
+
+func (s Schedule) matchDay(d time.Time) bool {
+	if !s.mon[int(d.Month())] {
+		return false
+	}
+	dom, dow := s.dom[d.Day()], s.dow[int(d.Weekday())]
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseCronField marks the values of one comma-separated cron field ("*/15", "1-5", "0,30") in set.
+func parseCronField(field string, lo, hi int, set *[64]bool) error {
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if r, st, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(st)
+			if err != nil || n < 1 {
+				return fmt.Errorf("bad step in %q", part)
+			}
+			rng, step = r, n
+		}
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(a); err != nil {
+				return fmt.Errorf("bad value in %q", part)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(b); err != nil {
+					return fmt.Errorf("bad range in %q", part)
+				}
+			} else if step > 1 {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSchedulePrev(t *testing.T) {
+	now := time.Date(2025, 4, 28, 15, 0, 0, 0, time.UTC) // a Monday
+	for _, tc := range []struct{ expr, want string }{
+		{"0 6 * * 1", "2025-04-28 06:00"},
+		{"0 16 * * 1", "2025-04-21 16:00"},
+		{"*/20 * * * *", "2025-04-28 15:00"},
+		{"30 9 1,15 * *", "2025-04-15 09:30"},
+		{"0 6 * * 5-7", "2025-04-27 06:00"},
+		{"0 6 1 * 2", "2025-04-22 06:00"}, // day-of-month OR day-of-week
+	} {
+		s, err := ParseSchedule(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		if got, _ := s.Prev(now); got.Format("2006-01-02 15:04") != tc.want {
+			t.Fatalf("%s: got %s want %s", tc.expr, got.Format("2006-01-02 15:04"), tc.want)
+		}
+	}
+	for _, bad := range []string{"0 6 * *", "61 * * * *", "0 6 * * mon", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseSchedule(bad); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%q: got %v", bad, err)
+		}
+	}
+}
+
+func TestTrain(t *testing.T) {
+	tags := []string{"20250425.300", "20250428.320"}
+	for _, tc := range []struct {
+		branches []string
+		cut      bool
+		want     string
+	}{
+		{[]string{"release/v20250421.250", "feature/x"}, true, "release/v20250428.320"},
+		{[]string{"release/v20250428.320"}, false, "release/v20250428.320"},
+		{[]string{"release/v20250421.250", "release/v20250428.310"}, false, "release/v20250428.310"},
+		{nil, true, "release/v20250428.320"},
+	} {
+		c := ctx("main", Config{DefaultBranch: "main", Train: "0 6 * * 1"}, tags)
+		c.LookupReleaseBranches = func() ([]string, error) { return tc.branches, nil }
+		d, err := c.Train()
+		if err != nil || d.Cut != tc.cut || d.Branch != tc.want {
+			t.Fatalf("%v: got %+v want cut=%v %s (%v)", tc.branches, d, tc.cut, tc.want, err)
+		}
+	}
+
+	c := ctx("main", Config{DefaultBranch: "main", Train: "0 6 * * 1"}, []string{"20250421.250"})
+	c.LookupReleaseBranches = func() ([]string, error) { return []string{"release/v20250421.250"}, nil }
+	if d, _ := c.Train(); d.Cut {
+		t.Fatalf("nothing new since the last train: got %+v", d)
+	}
+}
@@ -0,0 +1,42 @@
+package versioner
+
+import (
+	"fmt"
+	"time"
+)
+
+// Validate reports whether s conforms to this package's scheme: an optional prefix, a real calendar date, a build
+// number, an optional release patch, an optional suffix and optional commit metadata. Nightly versions
+// (YYYYMMDD-nightly.<n>, see ParseNightly) are valid too. Failures wrap ErrInvalidVersion.
+func Validate(s string) error {
+	date := ""
+	if n, err := ParseNightly(s); err == nil {
+		date = n.Date
+	} else {
+		v, err := Parse(s)
+		if err != nil {
+			return err
+		}
+		date = v.Date
+	}
+	if _, err := time.Parse("20060102", date); err != nil {
+		return fmt.Errorf("%w: %s: no such date %s", ErrInvalidVersion, s, date)
+	}
+	return nil
+}
+
+// IsFinal reports whether s is a valid default-branch or release version, i.e. one without a suffix. Feature builds
+// configured without any suffix look exactly like default-branch builds and are therefore reported as final.
+func IsFinal(s string) bool {
+	v, err := Parse(s)
+	return err == nil && Validate(s) == nil && v.Suffix == ""
+}
+
+// IsSnapshot reports whether s is a valid feature (snapshot) version carrying a suffix, or a nightly.
+func IsSnapshot(s string) bool {
+	if _, err := ParseNightly(s); err == nil {
+		return Validate(s) == nil
+	}
+	v, err := Parse(s)
+	return err == nil && Validate(s) == nil && v.Suffix != ""
+}
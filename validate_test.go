@@ -0,0 +1,44 @@
+package versioner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, s := range []string{"20250428.321", "cli-20250428.100.2", "20250428.321-SNAPSHOT+0a1b2c3d",
+		"20250428-nightly.1", "cli-20250428-nightly.2+0a1b2c3d"} {
+		if err := Validate(s); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "v1.2.3", "20251341.1", "2025042.1", "20250428", "20250231-nightly.1"} {
+		if err := Validate(s); !errors.Is(err, ErrInvalidVersion) {
+			t.Fatalf("%s: got %v want ErrInvalidVersion", s, err)
+		}
+	}
+
+	c := ctx("main", Config{DefaultBranch: "main", ForceVersion: "20250428-nightly.1"}, nil)
+	if v, err := c.Version(); err != nil || v != "20250428-nightly.1" {
+		t.Fatalf("forced nightly: got %s, %v", v, err)
+	}
+}
+
+func TestFinalAndSnapshot(t *testing.T) {
+	cases := []struct {
+		s               string
+		final, snapshot bool
+	}{
+		{"20250428.321", true, false},
+		{"cli-20250428.100.2", true, false},
+		{"20250428.321-SNAPSHOT", false, true},
+		{"20250428-nightly.1", false, true},
+		{"dev-jane-laptop-3", false, false},
+		{"20250231.1", false, false},
+	}
+	for _, c := range cases {
+		if IsFinal(c.s) != c.final || IsSnapshot(c.s) != c.snapshot {
+			t.Fatalf("%s: final=%v snapshot=%v want %v/%v", c.s, IsFinal(c.s), IsSnapshot(c.s), c.final, c.snapshot)
+		}
+	}
+}
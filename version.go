@@ -1,8 +1,15 @@
 // Package versioner produces deterministic CalVer strings for GitLab pipelines.
 //
-// ─  Default-branch  → YYYYMMDD.<PipelineID>
+// ─  Default-branch  → YYYYMMDD.<PipelineID>   (or YYYYMMDD.<n>, the n-th build of the day, with DailySequence)
 // ─  Feature branch  → [<Prefix>-]YYYYMMDD.<PipelineID>[-<Suffix>]
-// ─  Release branch  → [<Prefix>-]<BaseTag>.<NextPatch>
+// ─  Release branch  → [<Prefix>-]<BaseTag>.<NextPatch>   (or <BaseTag>-rc.<n> before the first final, with Candidates)
+// ─  Scheduled, with Nightly → [<Prefix>-]YYYYMMDD-nightly.<n>, the n-th nightly of the day
+//
+// Config.Channels adds '-beta.<build>', '-nightly' … to default and feature builds by branch or pipeline source.
+//
+// Config.SemVer switches to MAJOR.MINOR.PATCH, bumped from the latest tag by conventional commits.
+//
+// A non-zero Config.Epoch is written as '<n>!' in front of the date ('1!20250428.321') and outranks any date.
 //
 //   - BaseTag syntax: YYYYMMDD.<PipelineID>
 //   - Release branch name:  release/v<baseTag>
@@ -10,77 +17,450 @@
 package versioner
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
+	"log/slog"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // ---------------- Public ---------------------------------------------------------------------------------------------
 
 type Config struct {
-	DefaultBranch string // "main", "master", "trunk" …
-	Prefix        string // optional; prepended with '<prefix>-'
-	FeatureSuffix string // optional; appended as '-<suffix>' on *feature* builds only
+	DefaultBranch   string             // "main", "master", "trunk" …
+	Prefix          string             // optional; prepended with '<prefix>-'
+	Namespace       string             // optional; tags are '<namespace>/<version>' and only those count (see InNamespace)
+	FeatureSuffix   string             // optional; appended as '-<suffix>' on *feature* builds only
+	Channels        map[string]Channel // branch name, glob or ScheduleKey → channel suffix on default/feature builds
+	SuffixLabels    []string           // variant labels ("arm64", "debug") appended to *feature* builds in canonical (sorted) order
+	SanitizeAffixes bool               // rewrite Prefix/FeatureSuffix into the allowed charset instead of failing with *AffixError
+	Submodules      bool               // record submodule pins in the Manifest
+	Changelog       string             // optional; CHANGELOG.md kept in sync by UpdateChangelog on default/release builds
+	ChangelogMR     bool               // open a merge request for the changelog commit instead of pushing to the branch
+	TagNotes        bool               // TagAndPush: put the grouped changelog since the previous tag into the annotation
+	Milestone       string             // GitLab releases: link the milestone titled by this template ("Sprint {year}-{month}") or MilestoneByDate
+	CloseMilestone  bool               // with Milestone: close the linked milestone once the release is published
+	Timezone        string             // optional IANA name deciding the calendar day; defaults to UTC
+	Monotonic       bool               // fail with *MonotonicityError unless the version sorts after the latest existing tag
+	CommitMeta      bool               // append '+<shortsha>' build metadata from BuildContext.CommitSHA
+	BranchSlug      bool               // add the sanitized branch name ('-feat-payments') to *feature* builds
+	MergeRequest    bool               // add '-mr<IID>' to *feature* builds running in a merge-request pipeline
+	FeatureBranches []string           // globs ("feature/*") of the branches allowed feature builds when UnknownBranches is set
+	UnknownBranches UnknownBranches    // what branches outside the default, release and FeatureBranches get: a snapshot, an error or none
+	TicketPattern   string             // regex of the issue IDs in the branch name (DefaultTicketPattern); recorded in BuildInfo and manifests
+	Policies        []string           // built-in naming policies the version must pass, possibly rewritten: "docker", "k8s-label", "npm"
+	Reruns          bool               // release re-runs of an old commit reproduce its version or fail with ErrStaleRerun
+	NoCollisions    bool               // fail with ErrVersionExists if the tag exists; release branches skip to the next free patch
+	ForceVersion    string             // emergency override ($VERSIONER_FORCE_VERSION): used verbatim once it passes Validate
+	Epoch           int                // scheme generation written as '<n>!' before the date; bump it to reset or correct dating
+	DailySequence   bool               // default-branch builds number 1, 2, … per day from existing tags instead of the pipeline ID
+	MaxLength       int                // cap on the whole string (63 for k8s labels, 128 for Docker tags); long suffixes get hashed
+	BuildWidth      int                // zero-pad the build number to this width (6: '20250428.000321') for string-sorting stores
+	PatchWidth      int                // zero-pad the release patch likewise (2: '20250428.000321.01')
+	RequireBaseTag  bool               // release builds fail with ErrMissingBaseTag unless the branch's <BaseTag> exists
+	Train           string             // cron schedule ("0 6 * * 1") of release trains; see BuildContext.Train
+	Candidates      bool               // release branches emit '<BaseTag>-rc.<n>' until a Final build cuts '<BaseTag>.1'
+	Final           bool               // with Candidates: approve the first final patch
+	SemVer          bool               // MAJOR.MINOR.PATCH bumped by the conventional commits since the latest tag instead of CalVer
+	Bump            Bump               // SemVer: override the bump derived from commit messages
+	LegacyTags      bool               // SemVer: count 'vX.Y.Z' tags from before versioner as releases, so numbering continues from them
+	Nightly         bool               // scheduled pipelines emit '[<prefix>-]YYYYMMDD-nightly.<n>', numbered per day, on any branch
+	NightlyKeepDays int                // with Nightly: tagging a nightly prunes nightly tags older than this many days; 0 keeps all
+
+	Aliases []Alias // moving tags ("latest", "stable") force-updated to each newer final version once it is tagged
+
+	DryRun         bool // describe tags, pushes and file writes instead of performing them
+	BestEffortTags bool // treat a failed tag lookup as "no tags" instead of failing (previous behaviour)
+
+	PushRetries int           // TagAndPush: extra attempts after a rejected tag push
+	PushBackoff time.Duration // TagAndPush: first retry delay, doubled per attempt; defaults to 1s
+
+	Timeouts Timeouts // bounds on git commands, hosting API calls and webhook deliveries
 }
 
 type BuildContext struct {
-	Branch     string    // CI_COMMIT_BRANCH
-	PipelineID string    // CI_PIPELINE_IID
-	Time       time.Time // generally time.Now()
-	Config     Config
-	LookupTags func() ([]string, error) // overridable for tests
+	Branch         string    // CI_COMMIT_BRANCH
+	PipelineID     string    // CI_PIPELINE_IID
+	CommitSHA      string    // CI_COMMIT_SHA; recorded in manifests, optionally appended as build metadata
+	MergeReqID     string    // CI_MERGE_REQUEST_IID; empty outside merge-request pipelines
+	PipelineSource string    // CI_PIPELINE_SOURCE ("push", "schedule" …); selects Config.Channels[ScheduleKey]
+	PipelineURL    string    // CI_PIPELINE_URL; included in webhook events
+	Time           time.Time // build time; when zero, each operation reads Clock once
+	Clock          Clock     // defaults to SystemClock
+	Config         Config
+	LookupTags     func() ([]string, error) // overridable for tests
+
+	LookupSubmodules func() (map[string]string, error)   // overridable for tests; defaults to HEAD's gitlinks
+	LookupBuild      func() (string, error)              // build number when PipelineID is empty; defaults to CommitCount
+	LookupChanges    func(since string) (Changes, error) // SemVer: commits from tag since to HEAD; defaults to git log
+
+	LookupReleaseBranches func() ([]string, error) // Train: existing release branches; defaults to GitReleaseBranches
+	PruneNightly          NightlyPolicy            // run after tagging a nightly; defaults to KeepNightlyDays(Config.NightlyKeepDays)
+
+	Metadata map[string]string // optional key/value facts recorded with the version (flags, schema version …)
+	Locker   Locker            // optional; serializes TagAndPush on release branches across pipelines
+	Ledger   Ledger            // optional; TagAndPush records every pushed manifest here
+	Webhooks []Webhook         // endpoints Notify posts events to
+	Audit    AuditSink         // optional; every Version outcome is appended with its inputs
+	Policies []Policy          // optional; custom naming policies run after Config.Policies
+
+	DryRunOut io.Writer    // where Config.DryRun describes skipped side effects; defaults to os.Stderr
+	Logger    *slog.Logger // optional; receives debug events about classification, tags and patch selection
+	Metrics   *Metrics     // optional; counts computed versions, tag-lookup latency and push retries
+
+	Tracer  Tracer          // optional; spans around tag lookup, classification and tagging
+	Context context.Context // parent of those spans, e.g. WithTraceParent(ctx, $TRACEPARENT); defaults to Background
+
+	memo *tagMemo // the one tag lookup of the current evaluation; see memoizeTags
 }
 
-// Version returns the canonical version string or an error.
-func (c BuildContext) Version() (string, error) {
-	switch classify(c.Config.DefaultBranch, c.Branch) {
+// Version returns the canonical version string or an error. With Audit set, the outcome is recorded there. The tags
+// are looked up at most once per call, however many checks consult them.
+func (c BuildContext) Version() (v string, err error) {
+	c = c.pinTime().memoizeTags()
+	if c.Audit != nil {
+		defer func() {
+			if aerr := c.audit(v, err); aerr != nil && err == nil {
+				v, err = "", aerr
+			}
+		}()
+	}
+	if f := c.Config.ForceVersion; f != "" {
+		c.debug("version forced", "version", f)
+		if err := Validate(f); err != nil {
+			return "", fmt.Errorf("forced version: %w", err)
+		}
+		if limit := c.Config.MaxLength; limit > 0 && len(f) > limit {
+			return "", fmt.Errorf("%w: forced version %s is longer than %d", ErrInvalidVersion, f, limit)
+		}
+		return f, nil
+	}
+	if v, err = c.version(); err == nil {
+		c.Metrics.versionComputed(Classify(c.Config, c.Branch))
+	}
+	return v, err
+}
 
-	case typeDefault:
-		v := fmt.Sprintf("%s.%s", c.Time.Format("20060102"), c.PipelineID)
-		return addPrefix(v, c.Config.Prefix), nil
+func (c BuildContext) version() (string, error) {
+	if err := c.Config.Validate(); err != nil {
+		return "", err
+	}
+	v, err := c.compute()
+	if err != nil {
+		return "", err
+	}
+	if !c.Config.Monotonic && !c.Config.NoCollisions {
+		return c.applyPolicies(v)
+	}
 
-	case typeRelease:
-		base, next, err := nextPatch(c.Branch, c.LookupTags)
+	ts, err := c.tags()
+	if err != nil {
+		return "", err
+	}
+	if c.Config.NoCollisions {
+		before := v
+		release := Classify(c.Config, c.Branch) == KindRelease
+		if v, err = avoidCollision(v, release, ts); err != nil {
+			return "", err
+		}
+		if v != before {
+			c.debug("tag collision avoided", "taken", before, "version", v)
+		}
+	}
+	if c.Config.Monotonic && !c.isNightly() { // nightlies are numbered upwards per day and sort by date
+		var latest string
+		if c.Config.SemVer {
+			latest, err = checkSemVerMonotonic(v, Classify(c.Config, c.Branch) == KindRelease, ts)
+		} else {
+			latest, err = checkMonotonic(v, ts)
+		}
+		c.debug("monotonicity checked", "version", v, "latest", latest)
 		if err != nil {
 			return "", err
 		}
-		v := fmt.Sprintf("%s.%d", base, next)
+	}
+	return c.applyPolicies(v)
+}
+
+func (c BuildContext) compute() (string, error) {
+	if c.Branch != "" {
+		if err := CheckRefName(c.Branch); err != nil {
+			return "", fmt.Errorf("branch: %w", err)
+		}
+	}
+	if err := c.checkKnownBranch(); err != nil {
+		return "", err
+	}
+	cfg, err := checkAffixes(c.Config)
+	if err != nil {
+		return "", err
+	}
+	c.Config = cfg
+	v, err := c.computeBase()
+	if err != nil {
+		return "", err
+	}
+	if c.Config.CommitMeta && c.CommitSHA != "" {
+		v += "+" + shortSHA(c.CommitSHA)
+	}
+	if c.Config.MaxLength > 0 && len(v) > c.Config.MaxLength {
+		long := v
+		if v, err = fitLength(v, c.Config.MaxLength); err != nil {
+			return "", err
+		}
+		c.debug("version shortened", "from", long, "to", v)
+	}
+	return v, nil
+}
+
+func (c BuildContext) computeBase() (string, error) {
+	if c.Config.SemVer {
+		return c.semver()
+	}
+	day, err := c.day()
+	if err != nil {
+		return "", err
+	}
+	if c.isNightly() {
+		return c.nightly(day)
+	}
+
+	sp := c.span("versioner.classify", slog.String("branch", c.Branch))
+	kind := Classify(c.Config, c.Branch)
+	sp.End(nil)
+	c.debug("branch classified", "branch", c.Branch, "kind", kind, "default_branch", c.Config.DefaultBranch)
+
+	switch kind {
+
+	case KindDefault:
+		var build string
+		if c.Config.DailySequence {
+			build, err = c.dailySequence(day)
+		} else {
+			build, err = c.build()
+		}
+		if err != nil {
+			return "", err
+		}
+		v := fmt.Sprintf("%s%s.%s", epochMark(c.Config.Epoch), day, pad(build, c.Config.BuildWidth))
+		ch, err := c.channelSuffix(build)
+		if err != nil {
+			return "", err
+		}
+		return addPrefix(v+ch, c.Config.Prefix), nil
+
+	case KindRelease:
+		ts, err := c.tags()
+		if err != nil {
+			return "", err
+		}
+		c.debug("tags considered", "count", len(ts))
+		ts = sameEpoch(ts, c.Config.Epoch)
+		base, next, err := nextPatch(c.Branch, c.Config.Prefix, ts)
+		if err != nil {
+			return "", err
+		}
+		if c.Config.RequireBaseTag && !hasBase(base, c.Config.Prefix, ts) {
+			return "", fmt.Errorf("%w: %s has no tag %s", ErrMissingBaseTag, c.Branch, addPrefix(base, c.Config.Prefix))
+		}
+		if day > base[:8] && next > 1 {
+			c.debug("backport onto an older release", "base", base, "today", day)
+		}
+		if next > 1 {
+			c.debug("latest tag chosen", "tag", base+"."+pad(strconv.Itoa(next-1), c.Config.PatchWidth))
+		}
+		c.debug("patch computed", "base", base, "patch", next)
+		if c.Config.Reruns {
+			if v, ok, err := c.previousAssignment(base); err != nil || ok {
+				return v, err
+			}
+		}
+		if c.Config.Candidates && !c.Config.Final && next == 1 {
+			n := nextCandidate(base, c.Config.Prefix, ts)
+			c.debug("release candidate computed", "base", base, "rc", n)
+			v := fmt.Sprintf("%s%s-rc.%d", epochMark(c.Config.Epoch), base, n)
+			return addPrefix(v, c.Config.Prefix), nil
+		}
+		v := fmt.Sprintf("%s%s.%s", epochMark(c.Config.Epoch), base, pad(strconv.Itoa(next), c.Config.PatchWidth))
 		return addPrefix(v, c.Config.Prefix), nil
 
 	default: // feature / hot-fix
-		base := c.Time.Format("20060102")
-		v := fmt.Sprintf("%s.%s", base, c.PipelineID)
-		if suf := strings.TrimPrefix(c.Config.FeatureSuffix, "-"); suf != "" {
-			v += "-" + suf
+		build, err := c.build()
+		if err != nil {
+			return "", err
 		}
-		return addPrefix(v, c.Config.Prefix), nil
+		v := fmt.Sprintf("%s%s.%s", epochMark(c.Config.Epoch), day, pad(build, c.Config.BuildWidth))
+		extra, err := c.featureExtras(build)
+		if err != nil {
+			return "", err
+		}
+		return addPrefix(v+extra, c.Config.Prefix), nil
 	}
 }
 
-// ---------------- Internals ------------------------------------------------------------------------------------------
-
-type branchKind int
+// Kind is the branch category that selects a versioning rule.
+type Kind int
 
 const (
-	typeFeature branchKind = iota
-	typeDefault
-	typeRelease
+	KindFeature Kind = iota // anything else: feature, hot-fix … → snapshot versions
+	KindDefault             // the default branch → final YYYYMMDD.<PipelineID>
+	KindRelease             // release/v<BaseTag> → final <BaseTag>.<NextPatch>
 )
 
-func classify(def, br string) branchKind {
+// Final reports whether builds of this kind produce publishable (non-snapshot) versions.
+func (k Kind) Final() bool { return k != KindFeature }
+
+func (k Kind) String() string {
+	switch k {
+	case KindDefault:
+		return "default"
+	case KindRelease:
+		return "release"
+	default:
+		return "feature"
+	}
+}
+
+// Classify decides which versioning rule applies to branch under cfg, so CI templates can route jobs (e.g. only
+// publish final builds) with the same logic the versioner uses.
+func Classify(cfg Config, branch string) Kind {
 	switch {
-	case br == def:
-		return typeDefault
-	case strings.HasPrefix(br, "release/"):
-		return typeRelease
+	case branch == cfg.DefaultBranch:
+		return KindDefault
+	case strings.HasPrefix(branch, "release/"):
+		return KindRelease
 	default:
-		return typeFeature
+		return KindFeature
 	}
 }
 
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (c BuildContext) debug(msg string, args ...any) {
+	if c.Logger != nil {
+		c.Logger.Debug(msg, args...)
+	}
+}
+
+// day is the YYYYMMDD build date in the configured timezone, so runner locale never decides the date.
+func (c BuildContext) day() (string, error) {
+	t, err := c.localTime()
+	return t.Format("20060102"), err
+}
+
+func (c BuildContext) localTime() (time.Time, error) {
+	if c.Config.Timezone == "" {
+		return c.now().UTC(), nil
+	}
+	loc, err := time.LoadLocation(c.Config.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: timezone %q: %v", ErrInvalidConfig, c.Config.Timezone, err)
+	}
+	return c.now().In(loc), nil
+}
+
+// avoidCollision rejects an existing tag, or on release branches bumps the patch (the rc number of a candidate) until
+// it is free.
+func avoidCollision(v string, release bool, tags []string) (string, error) {
+	taken := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		taken[t] = true
+	}
+	if !taken[v] {
+		return v, nil
+	}
+	if !release {
+		return "", fmt.Errorf("%w: %s", ErrVersionExists, v)
+	}
+	pv, err := Parse(v)
+	if err != nil {
+		return "", err
+	}
+	if m := rcRE.FindStringSubmatch(pv.Suffix); m != nil && pv.Patch == 0 {
+		n, _ := strconv.Atoi(m[1])
+		for taken[pv.String()] {
+			n++
+			pv.Suffix = "rc." + strconv.Itoa(n)
+		}
+		return pv.String(), nil
+	}
+	for taken[pv.String()] {
+		pv.Patch++
+	}
+	return pv.String(), nil
+}
+
+// featureExtras is the '-<slug>-mr<IID>-<suffix>-<channel>-<labels…>' tail of a feature build.
+func (c BuildContext) featureExtras(build string) (string, error) {
+	var v string
+	if c.Config.BranchSlug {
+		v += "-" + branchSlug(c.Branch)
+	}
+	if c.Config.MergeRequest && c.MergeReqID != "" {
+		v += "-mr" + c.MergeReqID
+	}
+	if suf := strings.TrimPrefix(c.Config.FeatureSuffix, "-"); suf != "" {
+		v += "-" + suf
+	}
+	ch, err := c.channelSuffix(build)
+	if err != nil {
+		return "", err
+	}
+	v += ch
+	for _, l := range canonicalLabels(c.Config.SuffixLabels) {
+		v += "-" + l
+	}
+	return v, nil
+}
+
+const maxSlug = 40
+
+// branchSlug reduces a branch name to lower-case alphanumerics and dashes, short enough for registry tags.
+func branchSlug(br string) string {
+	s := slug(br)
+	if len(s) > maxSlug {
+		s = strings.TrimRight(s[:maxSlug], "-")
+	}
+	return s
+}
+
+// fitLength shortens v's suffix to fit limit characters: the suffix is cut and ends in "-<8 hex of sha256(suffix)>",
+// so the result is deterministic and distinct suffixes stay distinct. Versions whose core alone is too long fail.
+func fitLength(v string, limit int) (string, error) {
+	pv, err := Parse(v)
+	if err != nil {
+		return "", err
+	}
+	full := pv.Suffix
+	pv.Suffix = ""
+	room := limit - len(pv.String()) - 1 // '-' before the suffix
+	if full == "" || room < 8 {
+		return "", fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidVersion, v, limit)
+	}
+	sum := sha256.Sum256([]byte(full))
+	h := hex.EncodeToString(sum[:])[:8]
+	if keep := strings.TrimRight(full[:max(room-9, 0)], "-."); keep != "" {
+		h = keep + "-" + h
+	}
+	pv.Suffix = h
+	return pv.String(), nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
 func addPrefix(v, p string) string {
 	if p == "" {
 		return v
@@ -90,24 +470,23 @@ func addPrefix(v, p string) string {
 
 /* ---------- helpers for release branches ------------------------------------ */
 
-var relBranchRE = regexp.MustCompile(`^release/v(\d{8}\.\d+)$`)
+var (
+	relBranchRE = regexp.MustCompile(`^release/v(\d{8}\.\d+)$`)
+	rcRE        = regexp.MustCompile(`^rc\.(\d+)$`)
+)
 
-func nextPatch(br string, lookup func() ([]string, error)) (base string, patch int, err error) {
+// nextPatch is one more than the highest '<base>.<n>' tag of the release branch br, with or without prefix.
+func nextPatch(br, prefix string, ts []string) (base string, patch int, err error) {
 	m := relBranchRE.FindStringSubmatch(br)
 	if len(m) != 2 {
-		err = fmt.Errorf("invalid release branch: %s", br)
+		err = fmt.Errorf("%w: %s", ErrInvalidReleaseBranch, br)
 		return
 	}
 	base = m[1]
 
-	// graceful degradation if lookup is nil
-	var ts []string
-	if lookup != nil {
-		ts, _ = lookup()
-	}
-
 	max := 0
-	re := regexp.MustCompile(fmt.Sprintf(`^%s\.(\d+)$`, regexp.QuoteMeta(base)))
+	re := regexp.MustCompile(fmt.Sprintf(`^(?:%s|%s)\.(\d+)$`, regexp.QuoteMeta(base),
+		regexp.QuoteMeta(addPrefix(base, prefix))))
 	for _, t := range ts {
 		if mm := re.FindStringSubmatch(t); len(mm) == 2 {
 			n, _ := strconv.Atoi(mm[1])
@@ -120,12 +499,194 @@ func nextPatch(br string, lookup func() ([]string, error)) (base string, patch i
 	return
 }
 
+// hasBase reports whether the default-branch build base was tagged, with or without prefix.
+func hasBase(base, prefix string, ts []string) bool {
+	for _, t := range ts {
+		if t == base || t == addPrefix(base, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextCandidate is one more than the highest '<base>-rc.<n>' tag, with or without prefix.
+func nextCandidate(base, prefix string, ts []string) int {
+	max := 0
+	re := regexp.MustCompile(fmt.Sprintf(`^(?:%s|%s)-rc\.(\d+)$`, regexp.QuoteMeta(base),
+		regexp.QuoteMeta(addPrefix(base, prefix))))
+	for _, t := range ts {
+		if m := re.FindStringSubmatch(t); len(m) == 2 {
+			if n, _ := strconv.Atoi(m[1]); n > max {
+				max = n
+			}
+		}
+	}
+	return max + 1
+}
+
+// dailySequence is one more than the highest build among today's default-branch tags of this prefix and epoch, so
+// concurrent pipelines race for it and TagAndPush's retry hands the loser the next number.
+func (c BuildContext) dailySequence(day string) (string, error) {
+	ts, err := c.tags()
+	if err != nil {
+		return "", err
+	}
+	max := 0
+	for _, t := range ts {
+		v, err := Parse(t)
+		if err == nil && v.Date == day && v.Patch == 0 && v.Suffix == "" && v.Epoch == c.Config.Epoch &&
+			v.Prefix == strings.TrimSuffix(c.Config.Prefix, "-") && v.Build > max {
+			max = v.Build
+		}
+	}
+	c.debug("daily sequence computed", "day", day, "latest", max)
+	return strconv.Itoa(max + 1), nil
+}
+
+// sameEpoch keeps the tags written in epoch n, with their "<n>!" marker removed, for patch counting.
+func sameEpoch(ts []string, n int) []string {
+	mark := epochMark(n)
+	var out []string
+	for _, t := range ts {
+		if n == 0 && !strings.Contains(t, "!") {
+			out = append(out, t)
+		} else if n > 0 && strings.HasPrefix(t, mark) {
+			out = append(out, strings.TrimPrefix(t, mark))
+		}
+	}
+	return out
+}
+
+// tags runs LookupTags. A nil lookup means no tags; a failing one is fatal unless Config.BestEffortTags is set,
+// because guessing "no tags" silently hands out patch numbers that are already taken. Malformed names are screened
+// out (see SkippedTags).
+func (c BuildContext) tags() ([]string, error) {
+	if c.LookupTags == nil {
+		return nil, nil
+	}
+	ts, err := c.lookupTags()
+	switch {
+	case err == nil:
+		ts, skipped := screenTags(ts)
+		for _, s := range skipped {
+			c.debug("tag skipped", "tag", fmt.Sprintf("%q", s.Tag), "reason", s.Reason)
+		}
+		return ts, nil
+	case c.Config.BestEffortTags:
+		return nil, nil
+	case errors.Is(err, ErrTagLookupFailed):
+		return nil, err
+	default:
+		return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+}
+
+// tagMemo holds the result of the first tag lookup of an evaluation.
+type tagMemo struct {
+	once sync.Once
+	done atomic.Bool // the lookup ran
+	tags []string
+	err  error
+}
+
+// memoizeTags starts an evaluation: until it returns, every lookupTags on c and its copies answers from one lookup.
+// Inside an evaluation that already started it is a no-op, so nested entry points share the outer lookup.
+func (c BuildContext) memoizeTags() BuildContext {
+	if c.memo == nil {
+		c.memo = new(tagMemo)
+	}
+	return c
+}
+
+// lookupTags runs LookupTags under a span, once per evaluation when memoized, and returns the versions tagged in
+// Config.Namespace as a list of the caller's own.
+func (c BuildContext) lookupTags() ([]string, error) {
+	fetch := func() ([]string, error) {
+		sp, start := c.span("versioner.tag_lookup"), time.Now()
+		ts, err := c.LookupTags()
+		c.Metrics.observeLookup(time.Since(start))
+		sp.End(err)
+		return ts, err
+	}
+	m := c.memo
+	if m == nil {
+		ts, err := fetch()
+		return InNamespace(ts, c.Config.Namespace), err
+	}
+	m.once.Do(func() { m.tags, m.err = fetch(); m.done.Store(true) })
+	return InNamespace(m.tags, c.Config.Namespace), m.err
+}
+
+// tagName is the tag of version in Config.Namespace.
+func (c BuildContext) tagName(version string) string {
+	return TagName(c.Config.Namespace, version)
+}
+
+// build is the pipeline ID or, outside CI, the number from LookupBuild, so local runs never produce "20250428.".
+func (c BuildContext) build() (string, error) {
+	if c.PipelineID != "" {
+		return c.PipelineID, nil
+	}
+	lookup := c.LookupBuild
+	if lookup == nil {
+		lookup = c.commitCount
+	}
+	n, err := lookup()
+	if err != nil {
+		return "", fmt.Errorf("%w: no pipeline ID and no fallback build number: %w", ErrInvalidConfig, err)
+	}
+	if _, err := strconv.ParseUint(n, 10, 64); err != nil {
+		return "", fmt.Errorf("%w: fallback build number %q is not numeric", ErrInvalidConfig, n)
+	}
+	c.debug("pipeline ID missing, using fallback build number", "build", n)
+	return n, nil
+}
+
 /* ---------- default Git helpers (may be stubbed in tests) -------------------- */
 
+// CommitCount is the number of commits reachable from HEAD, the default fallback build number.
+func CommitCount() (string, error) {
+	return BuildContext{}.commitCount()
+}
+
+// GitTags is the default tag source: every tag of the repository.
 func GitTags() ([]string, error) {
-	out, err := exec.Command("git", "tag").CombinedOutput()
+	return BuildContext{}.GitTags()
+}
+
+// GitTags is GitTags bounded by Config.Timeouts.Git.
+func (c BuildContext) GitTags() ([]string, error) {
+	out, err := c.git("tag")
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrTagLookupFailed, err)
+	}
+	return strings.Fields(out), nil
+}
+
+func (c BuildContext) commitCount() (string, error) {
+	out, err := c.git("rev-list", "--count", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// git runs a git subcommand in the working directory through the Runner, bounded by DefaultGitTimeout, and returns
+// its stdout with CRLF line endings (Windows) turned into LF; failures come back as *GitError carrying git's stderr.
+func git(args ...string) (string, error) {
+	return gitEnv(nil, args...)
+}
+
+// gitEnv is git with extra environment variables, for secrets that must not appear in the arguments.
+func gitEnv(env []string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultGitTimeout)
+	defer cancel()
+	return gitContext(ctx, env, args...)
+}
+
+// gitContext is gitEnv bounded by ctx.
+func gitContext(ctx context.Context, env []string, args ...string) (string, error) {
+	out, stderr, err := run(ctx, "git", args, env)
+	out = strings.ReplaceAll(out, "\r\n", "\n")
+	if err != nil {
+		return out, &GitError{Args: args, Output: stderr, Err: err}
 	}
-	return strings.Fields(string(out)), nil
+	return out, nil
 }
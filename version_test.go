@@ -1,6 +1,11 @@
 package versioner
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 )
@@ -64,3 +69,320 @@ func TestReleaseBranchSubsequentPatch(t *testing.T) {
 		t.Fatalf("got %s want %s", got, want)
 	}
 }
+
+func TestTimezoneDecidesDay(t *testing.T) {
+	c := ctx("main", Config{DefaultBranch: "main", Timezone: "Asia/Tokyo"}, nil)
+	c.Time = time.Date(2025, 4, 28, 23, 30, 0, 0, time.UTC)
+	got, _ := c.Version()
+	want := "20250429.321"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+func TestDefaultTimezoneIsUTC(t *testing.T) {
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.Time = time.Date(2025, 4, 28, 20, 30, 0, 0, time.FixedZone("EDT", -4*3600))
+	got, _ := c.Version()
+	want := "20250429.321"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+func TestInvalidTimezone(t *testing.T) {
+	_, err := ctx("main", Config{DefaultBranch: "main", Timezone: "Mars/Olympus"}, nil).Version()
+	if err == nil {
+		t.Fatal("expected error for unknown timezone")
+	}
+}
+
+func TestCollisionOnDefaultBranch(t *testing.T) {
+	tags := []string{"20250428.321"}
+	_, err := ctx("main", Config{DefaultBranch: "main", NoCollisions: true}, tags).Version()
+	if !errors.Is(err, ErrVersionExists) {
+		t.Fatalf("got %v want ErrVersionExists", err)
+	}
+}
+
+func TestCollisionOnReleaseBranchTakesNextPatch(t *testing.T) {
+	tags := []string{"cli-20250428.100", "cli-20250428.100.1", "cli-20250428.100.2"}
+	want := "cli-20250428.100.3"
+	for _, cfg := range []Config{
+		{DefaultBranch: "main", Prefix: "cli"},
+		{DefaultBranch: "main", Prefix: "cli", NoCollisions: true},
+	} {
+		got, err := ctx("release/v20250428.100", cfg, tags).Version()
+		if err != nil || got != want {
+			t.Fatalf("NoCollisions=%v: got %s, %v want %s", cfg.NoCollisions, got, err, want)
+		}
+	}
+}
+
+func TestCommitMetadata(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", FeatureSuffix: "SNAPSHOT", CommitMeta: true}
+	c := ctx("feat/x", cfg, nil)
+	c.CommitSHA = "0123456789abcdef0123456789abcdef01234567"
+	got, _ := c.Version()
+	want := "20250428.321-SNAPSHOT+01234567"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	if m, _ := c.Manifest(); m.Commit != c.CommitSHA {
+		t.Fatalf("manifest commit %q want %q", m.Commit, c.CommitSHA)
+	}
+}
+
+func TestFeatureBranchSlug(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", FeatureSuffix: "SNAPSHOT", BranchSlug: true}
+	got, _ := ctx("feat/Payments_v2", cfg, nil).Version()
+	want := "20250428.321-feat-payments-v2-SNAPSHOT"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	long, _ := ctx("feat/"+strings.Repeat("x", 80), cfg, nil).Version()
+	if len(long) > len("20250428.321--SNAPSHOT")+maxSlug {
+		t.Fatalf("slug not truncated: %s", long)
+	}
+}
+
+func TestMergeRequestSuffix(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", FeatureSuffix: "SNAPSHOT", MergeRequest: true}
+	c := ctx("feat/payments", cfg, nil)
+	c.MergeReqID = "42"
+	got, _ := c.Version()
+	want := "20250428.321-mr42-SNAPSHOT"
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	c.MergeReqID = "" // branch pipeline
+	if got, _ := c.Version(); got != "20250428.321-SNAPSHOT" {
+		t.Fatalf("got %s want 20250428.321-SNAPSHOT", got)
+	}
+}
+
+func TestDebugLogging(t *testing.T) {
+	var buf bytes.Buffer
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, []string{"20250428.100.1"})
+	c.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := c.Version(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"kind=release", "count=1", "tag=20250428.100.1", "patch=2"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("log lacks %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cfg := Config{DefaultBranch: "trunk"}
+	cases := map[string]Kind{"trunk": KindDefault, "release/v20250428.100": KindRelease, "feat/x": KindFeature, "main": KindFeature}
+	for br, want := range cases {
+		if got := Classify(cfg, br); got != want {
+			t.Fatalf("Classify(%s) = %s want %s", br, got, want)
+		}
+	}
+	if KindFeature.Final() || !KindRelease.Final() {
+		t.Fatal("only default and release builds are final")
+	}
+}
+
+func TestForceVersion(t *testing.T) {
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main", ForceVersion: "20250428.100.7"}, nil)
+	c.LookupTags = func() ([]string, error) { return nil, errors.New("not consulted") }
+	if got, err := c.Version(); err != nil || got != "20250428.100.7" {
+		t.Fatalf("got %s, %v", got, err)
+	}
+	c.Config.ForceVersion = "20251399.1"
+	if _, err := c.Version(); !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("got %v want ErrInvalidVersion", err)
+	}
+}
+
+func TestFallbackBuildNumber(t *testing.T) {
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.PipelineID = ""
+	c.LookupBuild = func() (string, error) { return "57", nil }
+	if got, err := c.Version(); err != nil || got != "20250428.57" {
+		t.Fatalf("got %s, %v", got, err)
+	}
+	c.LookupBuild = func() (string, error) { return "", errors.New("no counter") }
+	if _, err := c.Version(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v want ErrInvalidConfig", err)
+	}
+
+	gitRepo(t)
+	c.LookupBuild = nil
+	if got, err := c.Version(); err != nil || got != "20250428.1" {
+		t.Fatalf("commit count: got %s, %v", got, err)
+	}
+}
+
+func TestEpochBuilds(t *testing.T) {
+	c := ctx("main", Config{DefaultBranch: "main", Epoch: 1}, nil)
+	if got, _ := c.Version(); got != "1!20250428.321" {
+		t.Fatalf("got %s", got)
+	}
+	c = ctx("release/v20250428.100", Config{DefaultBranch: "main", Epoch: 1},
+		[]string{"20250428.100.4", "1!20250428.100.1"})
+	if got, _ := c.Version(); got != "1!20250428.100.2" {
+		t.Fatalf("got %s want patches counted within the epoch", got)
+	}
+}
+
+func TestDailySequence(t *testing.T) {
+	tags := []string{"20250427.7", "20250428.1", "20250428.2", "20250428.2.1", "20250428.9-feat", "app-20250428.5"}
+	c := ctx("main", Config{DefaultBranch: "main", DailySequence: true}, tags)
+	if got, _ := c.Version(); got != "20250428.3" {
+		t.Fatalf("got %s want 20250428.3", got)
+	}
+	c.Time = now.Add(24 * time.Hour)
+	if got, _ := c.Version(); got != "20250429.1" {
+		t.Fatalf("got %s want the sequence to restart at 1", got)
+	}
+	c = ctx("feat/x", Config{DefaultBranch: "main", DailySequence: true, FeatureSuffix: "SNAPSHOT"}, tags)
+	if got, _ := c.Version(); got != "20250428.321-SNAPSHOT" {
+		t.Fatalf("feature builds keep the pipeline ID, got %s", got)
+	}
+}
+
+func TestZeroPadding(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", BuildWidth: 6, PatchWidth: 2}
+	if got, _ := ctx("main", cfg, nil).Version(); got != "20250428.000321" {
+		t.Fatalf("got %s", got)
+	}
+	c := ctx("release/v20250428.000321", cfg, []string{"20250428.000321.09"})
+	if got, _ := c.Version(); got != "20250428.000321.10" {
+		t.Fatalf("got %s", got)
+	}
+	if v, _ := Parse("20250428.000321.01"); v.String() != "20250428.000321.01" || v.Build != 321 || v.Base() != "20250428.000321" {
+		t.Fatalf("padded round trip got %+v", v)
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", BranchSlug: true, MaxLength: 40}
+	c := ctx("feature/an-extremely-long-name-payments", cfg, nil)
+	c.CommitSHA = "0123456789abcdef"
+	c.Config.CommitMeta = true
+	got, err := c.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) > 40 || !strings.HasPrefix(got, "20250428.321-feature-a") || !strings.HasSuffix(got, "+01234567") {
+		t.Fatalf("got %s (%d chars)", got, len(got))
+	}
+	if again, _ := c.Version(); again != got {
+		t.Fatalf("truncation is not deterministic: %s vs %s", got, again)
+	}
+	c.Branch = "feature/an-extremely-long-name-invoices"
+	if other, _ := c.Version(); other == got {
+		t.Fatalf("distinct suffixes collapsed to %s", got)
+	}
+
+	c = ctx("main", Config{DefaultBranch: "main", MaxLength: 10}, nil)
+	if _, err := c.Version(); !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("got %v want ErrInvalidVersion", err)
+	}
+}
+
+func TestAffixValidation(t *testing.T) {
+	for _, cfg := range []Config{{Prefix: "1app"}, {Prefix: "my.app"}, {FeatureSuffix: "feat+x"}, {FeatureSuffix: "a b"}} {
+		cfg.DefaultBranch = "main"
+		_, err := ctx("feat/x", cfg, nil).Version()
+		var ae *AffixError
+		if !errors.As(err, &ae) || !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%+v: got %v want *AffixError", cfg, err)
+		}
+	}
+
+	cfg := Config{DefaultBranch: "main", Prefix: "2024 My.App", FeatureSuffix: "feat+x/ARM64", SanitizeAffixes: true}
+	if got, err := ctx("feat/x", cfg, nil).Version(); err != nil || got != "My-App-20250428.321-feat-x-ARM64" {
+		t.Fatalf("got %s, %v", got, err)
+	}
+}
+
+func TestSuffixLabels(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", FeatureSuffix: "SNAPSHOT", SuffixLabels: []string{"debug", "arm64", "debug"}}
+	got, err := ctx("feat/x", cfg, nil).Version()
+	if err != nil || got != "20250428.321-SNAPSHOT-arm64-debug" {
+		t.Fatalf("got %s, %v", got, err)
+	}
+	if v, _ := ctx("main", cfg, nil).Version(); v != "20250428.321" {
+		t.Fatalf("final builds carry no labels, got %s", v)
+	}
+
+	pv, _ := Parse(got)
+	base, labels := StripLabels(pv, []string{"arm64", "amd64", "DEBUG"})
+	if base.String() != "20250428.321-SNAPSHOT" || fmt.Sprint(labels) != "[arm64 debug]" {
+		t.Fatalf("got %s %v", base, labels)
+	}
+}
+
+func TestReleaseCandidates(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", Candidates: true}
+	for _, tc := range []struct {
+		tags  []string
+		final bool
+		want  string
+	}{
+		{nil, false, "20250101.5-rc.1"},
+		{[]string{"20250101.5-rc.1", "20250101.5-rc.2", "20250101.6-rc.9"}, false, "20250101.5-rc.3"},
+		{[]string{"20250101.5-rc.1", "20250101.5-rc.2"}, true, "20250101.5.1"},
+		{[]string{"20250101.5-rc.2", "20250101.5.1"}, false, "20250101.5.2"},
+	} {
+		cfg.Final = tc.final
+		if got, err := ctx("release/v20250101.5", cfg, tc.tags).Version(); err != nil || got != tc.want {
+			t.Fatalf("%v final=%v: got %s want %s (%v)", tc.tags, tc.final, got, tc.want, err)
+		}
+	}
+}
+
+func TestBackportPatch(t *testing.T) {
+	cfg := Config{DefaultBranch: "main", RequireBaseTag: true}
+	tags := []string{"20240110.52", "20240110.52.1", "20240110.52.2", "20250428.300"}
+	if got, err := ctx("release/v20240110.52", cfg, tags).Version(); err != nil || got != "20240110.52.3" {
+		t.Fatalf("got %s, %v", got, err)
+	}
+	if _, err := ctx("release/v20240110.25", cfg, tags).Version(); !errors.Is(err, ErrMissingBaseTag) {
+		t.Fatalf("missing base: got %v", err)
+	}
+	cfg.Prefix = "svc"
+	if got, err := ctx("release/v20240110.7", cfg, []string{"svc-20240110.7"}).Version(); err != nil || got != "svc-20240110.7.1" {
+		t.Fatalf("prefixed base: got %s, %v", got, err)
+	}
+}
+
+func TestVersionLooksUpTagsOnce(t *testing.T) {
+	calls := 0
+	c := ctx("release/v20250401.100", Config{DefaultBranch: "main", Monotonic: true, NoCollisions: true}, nil)
+	c.LookupTags = func() ([]string, error) {
+		calls++
+		return []string{"20250401.100", "20250401.100.1", "20250401.100.2"}, nil
+	}
+	bi, err := c.BuildInfo()
+	if err != nil || bi.Version != "20250401.100.3" {
+		t.Fatalf("got %s, %v want 20250401.100.3", bi.Version, err)
+	}
+	if calls != 1 {
+		t.Fatalf("tags looked up %d times want 1", calls)
+	}
+
+	// every evaluation starts afresh
+	if _, err := c.Version(); err != nil || calls != 2 {
+		t.Fatalf("got %d lookups, %v want 2", calls, err)
+	}
+}
+
+func TestAvoidCollisionBumpsCandidates(t *testing.T) {
+	tags := []string{"cli-20250428.100-rc.1", "cli-20250428.100-rc.2", "cli-20250428.100.1"}
+	for v, want := range map[string]string{
+		"cli-20250428.100-rc.1": "cli-20250428.100-rc.3",
+		"cli-20250428.100.1":    "cli-20250428.100.2",
+	} {
+		if got, err := avoidCollision(v, true, tags); err != nil || got != want {
+			t.Fatalf("%s: got %s, %v want %s", v, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Package versionertest helps teams test their release pipelines against the versioner library: a tag source they
+// control, a clock that only moves when told to, a command runner that replays git output, and throwaway git
+// repositories with the tags and branches a scenario needs.
+//
+//	tags := versionertest.NewTags("20250428.100", "20250428.100.1")
+//	clock := versionertest.NewClock(time.Date(2025, 4, 28, 12, 0, 0, 0, time.UTC))
+//	c := versioner.New("release/v20250428.100", versioner.WithPipelineID("321"),
+//		versioner.WithClock(clock), versioner.WithTagSource(tags.Lookup))
+package versionertest
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Tags is a fake tag source, safe for concurrent use. Pass its Lookup method as BuildContext.LookupTags.
+type Tags struct {
+	mu    sync.Mutex
+	tags  []string
+	err   error
+	calls int
+}
+
+// NewTags returns a source listing tags.
+func NewTags(tags ...string) *Tags {
+	return &Tags{tags: slices.Clone(tags)}
+}
+
+// Lookup returns a copy of the tags, or the error set by Fail.
+func (s *Tags) Lookup() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return slices.Clone(s.tags), nil
+}
+
+// Add lists more tags from the next lookup on, like a concurrent pipeline pushing them.
+func (s *Tags) Add(tags ...string) {
+	s.mu.Lock()
+	s.tags = append(s.tags, tags...)
+	s.mu.Unlock()
+}
+
+// Fail makes every later lookup return err; nil heals the source.
+func (s *Tags) Fail(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Calls is the number of lookups so far.
+func (s *Tags) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+// Clock is a deterministic versioner.Clock, safe for concurrent use: Now keeps returning the same instant until Set
+// or Advance moves it.
+type Clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewClock returns a clock standing at t.
+func NewClock(t time.Time) *Clock {
+	return &Clock{t: t}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set moves the clock to t, backwards if need be (to test clock skew).
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	c.t = t
+	c.mu.Unlock()
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+// Runner is a fake versioner.Runner for testing tagging and tag lookups without a git binary: it answers each
+// command from Outputs, keyed by the program and its arguments joined with spaces ("git tag"), and records every
+// command in Calls. Commands missing from Outputs fail like git does, with exit status 128. Install it with
+// versioner.SetRunner and restore the previous runner with t.Cleanup.
+type Runner struct {
+	Outputs map[string]string
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *Runner) Run(ctx context.Context, name string, args, env []string) (string, string, error) {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	r.mu.Lock()
+	r.calls = append(r.calls, cmd)
+	r.mu.Unlock()
+	out, ok := r.Outputs[cmd]
+	if !ok {
+		return "", "fatal: versionertest: no output for " + cmd, errors.New("exit status 128")
+	}
+	return out, "", nil
+}
+
+// Calls returns the commands run so far, in order.
+func (r *Runner) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
+
+// Repo is a throwaway git repository: a clone in Dir of a bare Origin, both removed when the test ends. The
+// package-level versioner functions run git in the working directory, so call Chdir before using them.
+type Repo struct {
+	Dir    string // the clone, on branch main with one empty commit
+	Origin string // the bare repository Dir pushes to as origin
+
+	t testing.TB
+}
+
+// NewRepo builds a Repo with a fixed author and no global or system git config, or skips the test without git.
+// Tags and branches are created on the clone and pushed to Origin.
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for k, v := range map[string]string{
+		"GIT_AUTHOR_NAME": "versionertest", "GIT_AUTHOR_EMAIL": "versionertest@example.com",
+		"GIT_COMMITTER_NAME": "versionertest", "GIT_COMMITTER_EMAIL": "versionertest@example.com",
+		"GIT_CONFIG_GLOBAL": "/dev/null", "GIT_CONFIG_NOSYSTEM": "1",
+	} {
+		t.Setenv(k, v)
+	}
+	tmp := t.TempDir()
+	r := &Repo{Dir: filepath.Join(tmp, "clone"), Origin: filepath.Join(tmp, "origin.git"), t: t}
+	r.run("", "init", "-q", "--bare", "-b", "main", r.Origin)
+	r.run("", "clone", "-q", r.Origin, r.Dir)
+	r.Commit("init")
+	r.Git("push", "-q", "origin", "main")
+	return r
+}
+
+// Chdir makes Dir the working directory until the test ends.
+func (r *Repo) Chdir() {
+	r.t.Chdir(r.Dir)
+}
+
+// Git runs git in Dir and returns its trimmed output, failing the test on error.
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	return r.run(r.Dir, args...)
+}
+
+// Commit adds an empty commit with message msg and returns its SHA.
+func (r *Repo) Commit(msg string) string {
+	r.t.Helper()
+	r.Git("commit", "-q", "--allow-empty", "-m", msg)
+	return r.Git("rev-parse", "HEAD")
+}
+
+// Tag creates lightweight tags at HEAD and pushes them.
+func (r *Repo) Tag(tags ...string) {
+	r.t.Helper()
+	for _, tag := range tags {
+		r.Git("tag", tag)
+	}
+	r.Git(append([]string{"push", "-q", "origin"}, tags...)...)
+}
+
+// CommitTags adds one commit per tag and tags it, so each version points at its own build, as in a real history.
+func (r *Repo) CommitTags(tags ...string) {
+	r.t.Helper()
+	for _, tag := range tags {
+		r.Commit("build " + tag)
+		r.Tag(tag)
+	}
+}
+
+// Branch creates branch at HEAD, checks it out and pushes it.
+func (r *Repo) Branch(branch string) {
+	r.t.Helper()
+	r.Git("checkout", "-q", "-b", branch)
+	r.Git("push", "-q", "origin", branch)
+}
+
+// Checkout switches to an existing branch, tag or commit.
+func (r *Repo) Checkout(ref string) {
+	r.t.Helper()
+	r.Git("checkout", "-q", ref)
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (r *Repo) run(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
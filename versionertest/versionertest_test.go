@@ -0,0 +1,77 @@
+package versionertest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	versioner "github.com/drew-mcl/test"
+)
+
+func TestFakes(t *testing.T) {
+	tags := NewTags("20250428.100", "20250428.100.1")
+	clock := NewClock(time.Date(2025, 4, 28, 23, 0, 0, 0, time.UTC))
+	c := versioner.New("release/v20250428.100", versioner.WithPipelineID("321"),
+		versioner.WithClock(clock), versioner.WithTagSource(tags.Lookup))
+	if got, err := c.Version(); err != nil || got != "20250428.100.2" {
+		t.Fatalf("got %s, %v want 20250428.100.2", got, err)
+	}
+
+	tags.Add("20250428.100.2")
+	if got, _ := c.Version(); got != "20250428.100.3" {
+		t.Fatalf("got %s want 20250428.100.3", got)
+	}
+	if n := tags.Calls(); n != 2 {
+		t.Fatalf("got %d lookups want 2", n)
+	}
+	tags.Fail(errors.New("remote hung up"))
+	if _, err := c.Version(); !errors.Is(err, versioner.ErrTagLookupFailed) {
+		t.Fatalf("got %v want %v", err, versioner.ErrTagLookupFailed)
+	}
+
+	clock.Advance(2 * time.Hour)
+	c = versioner.New("main", versioner.WithPipelineID("322"), versioner.WithClock(clock))
+	if got, _ := c.Version(); got != "20250429.322" {
+		t.Fatalf("got %s want 20250429.322", got)
+	}
+}
+
+func TestRepo(t *testing.T) {
+	r := NewRepo(t)
+	r.CommitTags("20250428.100", "20250428.100.1")
+	r.Checkout("20250428.100")
+	r.Branch("release/v20250428.100")
+	r.Chdir()
+
+	c := versioner.New("release/v20250428.100", versioner.WithPipelineID("321"))
+	if got, err := c.Version(); err != nil || got != "20250428.100.2" {
+		t.Fatalf("got %s, %v want 20250428.100.2", got, err)
+	}
+	if out := r.Git("ls-remote", "--tags", "origin"); out == "" {
+		t.Fatal("tags were not pushed to origin")
+	}
+}
+
+func TestRunner(t *testing.T) {
+	r := &Runner{Outputs: map[string]string{"git tag": "20250428.100\n20250428.100.1\n"}}
+	prev := versioner.SetRunner(r)
+	t.Cleanup(func() { versioner.SetRunner(prev) })
+
+	ts, err := versioner.GitTags()
+	if err != nil || len(ts) != 2 {
+		t.Fatalf("got %v, %v", ts, err)
+	}
+
+	// commands without an output fail like git, and are recorded all the same
+	err = versioner.Tag(versioner.Manifest{Version: "20250428.100.2", Commit: "abc123"})
+	var ge *versioner.GitError
+	if !errors.As(err, &ge) {
+		t.Fatalf("got %v want a *GitError", err)
+	}
+	calls := r.Calls()
+	if len(calls) != 2 || !strings.HasPrefix(calls[1], "git tag -a") ||
+		!strings.HasSuffix(calls[1], "--end-of-options 20250428.100.2 abc123") {
+		t.Fatalf("got calls %q", calls)
+	}
+}
@@ -0,0 +1,63 @@
+package versioner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Warning is an issue found while computing a version that did not stop it, for pipelines to surface without
+// failing the build.
+type Warning struct {
+	Code    string `json:"code"` // one of the Warn constants, stable for scripts to match on
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string { return w.Message }
+
+// Warning codes.
+const (
+	WarnMalformedTags   = "malformed-tags"    // the tag lookup returned names that were ignored; see SkippedTags
+	WarnTagLookupFailed = "tag-lookup-failed" // Config.BestEffortTags computed the version as if there were no tags
+	WarnShallowClone    = "shallow-clone"     // commit counts and ancestry see only part of the history
+	WarnNoBranch        = "no-branch"         // no branch name, so the build was versioned as a feature branch
+)
+
+// Warnings returns the non-fatal issues of computing c's version. The tag warnings cover the lookup of the evaluation
+// c belongs to (see BuildInfo), so a build that never consulted tags, such as a dev or merge-request build, runs no
+// lookup for them; it fails only where Version would have.
+func (c BuildContext) Warnings() ([]Warning, error) {
+	var ws []Warning
+	if c.Branch == "" && c.Config.ForceVersion == "" {
+		ws = append(ws, Warning{WarnNoBranch, "no branch name (CI_COMMIT_BRANCH unset): versioned as a feature branch"})
+	}
+	if m := c.memo; c.lookedUp() {
+		if _, err := c.tags(); err != nil {
+			return nil, err
+		}
+		if m.err != nil { // and Config.BestEffortTags, or tags would have failed
+			ws = append(ws, Warning{WarnTagLookupFailed,
+				fmt.Sprintf("tag lookup failed, computed as if there were no tags: %v", m.err)})
+		} else if _, skipped := screenTags(InNamespace(m.tags, c.Config.Namespace)); len(skipped) > 0 {
+			ws = append(ws, Warning{WarnMalformedTags,
+				fmt.Sprintf("tag lookup returned %d malformed tags, ignored (first: %q)", len(skipped), skipped[0].Tag)})
+		}
+	}
+	// Outside a git checkout (a ledger, a remote tag source) there is no clone to be shallow.
+	if out, err := c.git("rev-parse", "--is-shallow-repository"); err == nil && strings.TrimSpace(out) == "true" {
+		ws = append(ws, Warning{WarnShallowClone,
+			"shallow clone detected: commit counts and ancestry checks see only part of the history (set GIT_DEPTH: 0)"})
+	}
+	for _, w := range ws {
+		c.debug("warning", "code", w.Code, "message", w.Message)
+	}
+	return ws, nil
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+// lookedUp reports whether the evaluation c belongs to already ran its tag lookup.
+func (c BuildContext) lookedUp() bool {
+	return c.LookupTags != nil && c.memo != nil && c.memo.done.Load()
+}
@@ -0,0 +1,54 @@
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	bi, err := ctx("", Config{DefaultBranch: "main"}, nil).BuildInfo()
+	if err != nil || len(bi.Warnings) != 1 || bi.Warnings[0].Code != WarnNoBranch {
+		t.Fatalf("no branch: got %v, %v", bi.Warnings, err)
+	}
+	bi, err = ctx("release/v20250427.3", Config{DefaultBranch: "main"}, []string{"20250427.3", "bad\x00tag"}).BuildInfo()
+	if err != nil || len(bi.Warnings) != 1 || bi.Warnings[0].Code != WarnMalformedTags {
+		t.Fatalf("malformed tags: got %v, %v", bi.Warnings, err)
+	}
+
+	c := ctx("main", Config{DefaultBranch: "main", BestEffortTags: true}, nil)
+	c.LookupTags = func() ([]string, error) { return nil, errors.New("remote hung up") }
+	if bi, err = c.BuildInfo(); err != nil || len(bi.Warnings) == 0 || bi.Warnings[0].Code != WarnTagLookupFailed {
+		t.Fatalf("best effort: got %v, %v", bi.Warnings, err)
+	}
+
+	// A feature build needs no tags, so the warnings run no lookup for it.
+	lookups := 0
+	c = ctx("feat/x", Config{DefaultBranch: "main"}, nil).memoizeTags()
+	c.LookupTags = func() ([]string, error) { lookups++; return nil, fmt.Errorf("%w: remote hung up", ErrTagLookupFailed) }
+	if _, err := c.Version(); err != nil {
+		t.Fatal(err)
+	}
+	if ws, err := c.Warnings(); err != nil || len(ws) != 0 || lookups != 0 {
+		t.Fatalf("feature build: got %v, %v after %d lookups", ws, err, lookups)
+	}
+	c.Branch = "release/v20250427.3"
+	if _, err = c.BuildInfo(); !errors.Is(err, ErrTagLookupFailed) || strings.Count(err.Error(), "tag lookup failed") != 1 {
+		t.Fatalf("release build: got %v", err)
+	}
+	if _, err = c.SkippedTags(); strings.Count(fmt.Sprint(err), "tag lookup failed") != 1 {
+		t.Fatalf("skipped tags: got %v", err)
+	}
+}
+
+func TestWarningsShallowClone(t *testing.T) {
+	origin := gitRepo(t)
+	mustGit(t, "", "commit", "--allow-empty", "-m", "second")
+	mustGit(t, "", "push", "origin", "HEAD")
+	mustGit(t, "", "fetch", "--depth=1", "file://"+origin)
+	ws, err := ctx("main", Config{DefaultBranch: "main"}, nil).Warnings()
+	if err != nil || len(ws) != 1 || ws[0].Code != WarnShallowClone {
+		t.Fatalf("got %v, %v", ws, err)
+	}
+}
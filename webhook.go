@@ -0,0 +1,257 @@
+package versioner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ---------------- Public ---------------------------------------------------------------------------------------------
+
+// Event is the JSON payload delivered to webhooks.
+type Event struct {
+	Type        string    `json:"type"` // EventComputed, EventTagged …
+	Version     string    `json:"version"`
+	Kind        string    `json:"kind,omitempty"` // default, release or feature
+	Branch      string    `json:"branch,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	PipelineURL string    `json:"pipeline_url,omitempty"`
+	Time        time.Time `json:"time"`
+	Manifest    *Manifest `json:"manifest,omitempty"`
+}
+
+// Event types sent by BuildContext.Notify.
+const (
+	EventComputed = "version.computed"
+	EventTagged   = "version.tagged"
+)
+
+// Webhook delivers events to an HTTP endpoint with optional HMAC signing, retries and a dead-letter log.
+type Webhook struct {
+	URL        string
+	Secret     string        // optional; signs the body as "X-Versioner-Signature: sha256=<hex>"
+	Retries    int           // extra attempts after a failed delivery
+	Backoff    time.Duration // first retry delay, doubled per attempt; defaults to 1s
+	DeadLetter string        // optional JSON-lines file recording deliveries that exhausted their retries
+	Client     *http.Client  // defaults to http.DefaultClient
+	Timeout    time.Duration // bound on each delivery attempt; defaults to DefaultWebhookTimeout
+	Clock      Clock         // stamps dead letters; defaults to SystemClock, Notify on a BuildContext passes its Clock
+}
+
+// DeadLetter is one undeliverable event recorded in Webhook.DeadLetter.
+type DeadLetter struct {
+	Time     time.Time       `json:"time"`
+	URL      string          `json:"url"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// SignatureHeader carries the HMAC-SHA256 of the request body when Webhook.Secret is set.
+const SignatureHeader = "X-Versioner-Signature"
+
+// Notify POSTs e, retrying network errors, 429s and 5xx responses with doubling backoff. Once retries are exhausted
+// (or the endpoint answers with another 4xx) the event is appended to the dead-letter log before the error is
+// returned.
+func (w Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return w.deliver(ctx, body)
+}
+
+// Redeliver resends every dead-lettered event for this webhook's URL and rewrites the log with those that still
+// fail. It returns the number of events delivered.
+func (w Webhook) Redeliver(ctx context.Context) (int, error) {
+	dls, err := ReadDeadLetters(w.DeadLetter)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Remove(w.DeadLetter); err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, dl := range dls {
+		if dl.URL != w.URL {
+			if err := appendDeadLetter(w.DeadLetter, dl); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := w.deliver(ctx, dl.Body); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// ReadDeadLetters lists the events recorded in a dead-letter log; a missing log is empty.
+func ReadDeadLetters(path string) ([]DeadLetter, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dls []DeadLetter
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var dl DeadLetter
+		if err := json.Unmarshal(sc.Bytes(), &dl); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		dls = append(dls, dl)
+	}
+	return dls, sc.Err()
+}
+
+// Notify sends a typ event for m to every BuildContext.Webhooks endpoint, so downstream systems (CMDB, deploy
+// orchestrators) learn about new versions immediately. Each endpoint retries and dead-letters on its own; failures
+// come back joined and are usually worth only a warning, since the version itself already exists.
+func (c BuildContext) Notify(ctx context.Context, typ string, m Manifest) error {
+	e := Event{
+		Type:        typ,
+		Version:     m.Version,
+		Kind:        Classify(c.Config, c.Branch).String(),
+		Branch:      c.Branch,
+		Commit:      firstNonEmpty(m.Commit, c.CommitSHA),
+		PipelineURL: c.PipelineURL,
+		Time:        c.now().UTC(),
+		Manifest:    &m,
+	}
+	var errs []error
+	for _, w := range c.Webhooks {
+		if w.Timeout <= 0 {
+			w.Timeout = c.Config.Timeouts.Webhook
+		}
+		if w.Clock == nil {
+			w.Clock = c.Clock
+		}
+		err := c.effect(fmt.Sprintf("POST %s event to %s", typ, w.URL), func() error { return w.Notify(ctx, e) })
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// VerifySignature lets receivers check SignatureHeader against their copy of the secret.
+func VerifySignature(secret string, body []byte, header string) bool {
+	return hmac.Equal([]byte(sign(secret, body)), []byte(header))
+}
+
+// ---------------- Internals ------------------------------------------------------------------------------------------
+
+func (w Webhook) deliver(ctx context.Context, body []byte) error {
+	backoff := w.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var err error
+	attempts := 0
+retry:
+	for attempts <= w.Retries {
+		if attempts > 0 {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				break retry
+			case <-time.After(backoff << (attempts - 1)):
+			}
+		}
+		attempts++
+		again, perr := w.post(ctx, body)
+		if perr == nil {
+			return nil
+		}
+		if err = perr; !again {
+			break
+		}
+	}
+
+	err = fmt.Errorf("webhook %s: %w", w.URL, err)
+	if w.DeadLetter != "" {
+		clock := w.Clock
+		if clock == nil {
+			clock = SystemClock
+		}
+		dl := DeadLetter{Time: clock.Now().UTC(), URL: w.URL, Attempts: attempts, Error: err.Error(), Body: body}
+		if derr := appendDeadLetter(w.DeadLetter, dl); derr != nil {
+			return errors.Join(err, derr)
+		}
+	}
+	return err
+}
+
+// post sends body once and reports whether a failure is worth retrying.
+func (w Webhook) post(ctx context.Context, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, orDefault(w.Timeout, DefaultWebhookTimeout))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, sign(w.Secret, body))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	default:
+		return false, fmt.Errorf("status %s", resp.Status)
+	}
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func appendDeadLetter(path string, dl DeadLetter) error {
+	b, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
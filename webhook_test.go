@@ -0,0 +1,122 @@
+package versioner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignsAndRetries(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if !VerifySignature("s3cret", body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("bad signature %q", r.Header.Get(SignatureHeader))
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	wh := Webhook{URL: srv.URL, Secret: "s3cret", Retries: 2, Backoff: time.Millisecond}
+	if err := wh.Notify(context.Background(), Event{Type: "version.tagged", Version: "20250428.100.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("got %d calls want 3", calls)
+	}
+}
+
+func TestWebhookDeadLetterAndRedeliver(t *testing.T) {
+	healthy := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	dlq := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	wh := Webhook{URL: srv.URL, Retries: 1, Backoff: time.Millisecond, DeadLetter: dlq}
+	if err := wh.Notify(context.Background(), Event{Type: "version.tagged", Version: "20250428.100.1"}); err == nil {
+		t.Fatal("expected delivery failure")
+	}
+	dls, err := ReadDeadLetters(dlq)
+	if err != nil || len(dls) != 1 || dls[0].Attempts != 2 {
+		t.Fatalf("unexpected dead letters %+v, %v", dls, err)
+	}
+
+	healthy = true
+	n, err := wh.Redeliver(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("redelivered %d, %v", n, err)
+	}
+	if dls, _ := ReadDeadLetters(dlq); len(dls) != 0 {
+		t.Fatalf("dead-letter log not drained: %+v", dls)
+	}
+}
+
+func TestWebhookClientErrorIsNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	wh := Webhook{URL: srv.URL, Retries: 3, Backoff: time.Millisecond}
+	if err := wh.Notify(context.Background(), Event{Version: "20250428.1"}); err == nil || calls != 1 {
+		t.Fatalf("got %v after %d calls, want one failed call", err, calls)
+	}
+}
+
+func TestBuildContextNotify(t *testing.T) {
+	var got []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		json.NewDecoder(r.Body).Decode(&e)
+		got = append(got, e)
+	}))
+	defer srv.Close()
+
+	c := ctx("release/v20250428.100", Config{DefaultBranch: "main"}, nil)
+	c.CommitSHA, c.PipelineURL = "abc123", "https://ci.example.com/p/9"
+	c.Webhooks = []Webhook{{URL: srv.URL}, {URL: srv.URL + "/cmdb"}}
+	if err := c.Notify(context.Background(), EventTagged, Manifest{Version: "20250428.100.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d deliveries want 2", len(got))
+	}
+	e := got[0]
+	if e.Type != EventTagged || e.Kind != "release" || e.Commit != "abc123" || e.PipelineURL != c.PipelineURL ||
+		!e.Time.Equal(now) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+
+func TestNotifyDeadLetterUsesClock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	dlq := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	failed := now.Add(time.Minute)
+	c := ctx("main", Config{DefaultBranch: "main"}, nil)
+	c.Clock = ClockFunc(func() time.Time { return failed })
+	c.Webhooks = []Webhook{{URL: srv.URL, DeadLetter: dlq}}
+	if err := c.Notify(context.Background(), EventTagged, Manifest{Version: "20250428.321"}); err == nil {
+		t.Fatal("expected delivery failure")
+	}
+	if dls, err := ReadDeadLetters(dlq); err != nil || len(dls) != 1 || !dls[0].Time.Equal(failed) {
+		t.Fatalf("unexpected dead letters %+v, %v want time %s", dls, err, failed)
+	}
+}